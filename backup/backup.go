@@ -0,0 +1,417 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatName identifies the archive created by this application.
+	FormatName = "go-webapp-demo-backup"
+	// FormatVersion is the version of the archive format which this application writes.
+	FormatVersion = 5
+	// ContentType is the media type of the archive.
+	ContentType = "application/gzip"
+	// manifestFile is the name of the last entry of the archive.
+	manifestFile = "manifest.json"
+	// entrySuffix is the extension of the entries which have the rows of the tables.
+	entrySuffix = ".ndjson"
+	// restoreBatchSize is the number of rows inserted at once when restoring.
+	restoreBatchSize = 100
+)
+
+var (
+	// ErrNotEmpty is returned when restoring into the database which already has data.
+	ErrNotEmpty = errors.New("the database isn't empty")
+	// ErrInvalidArchive is returned when the archive isn't created by this application.
+	ErrInvalidArchive = errors.New("the archive is invalid")
+)
+
+// Manifest represents the last entry of the archive which describes the other entries.
+type Manifest struct {
+	Format    string       `json:"format"`
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Dialect   string       `json:"dialect"`
+	Tables    []TableEntry `json:"tables"`
+}
+
+// TableEntry represents an entry of the archive which has the rows of a table as NDJSON.
+type TableEntry struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Rows int    `json:"rows"`
+}
+
+type tabler interface {
+	TableName() string
+}
+
+// table represents the operations of a table for dumping and restoring.
+type table struct {
+	name    string
+	serial  bool
+	count   func(rep repository.Repository) (int64, error)
+	dump    func(rep repository.Repository, w io.Writer) (int, error)
+	restore func(rep repository.Repository, r io.Reader) (int, error)
+}
+
+// tables lists every table in the order which satisfies the foreign keys when restoring.
+var tables = []table{
+	newTable[authorityRecord](true),
+	newTable[accountRecord](true),
+	newTable[authorityPermissionRecord](true),
+	newTable[accountAuthorityRecord](true),
+	newTable[accountIdentityRecord](true),
+	newTable[twoFactorRecord](true),
+	newTable[recoveryCodeRecord](true),
+	newTable[categoryRecord](true),
+	newTable[formatRecord](true),
+	newTable[bookRecord](true),
+	newTable[deletedBookRecord](false),
+	newTable[webhookRecord](true),
+	newTable[webhookDeliveryRecord](true),
+}
+
+// accountTables lists the tables which aren't in the archive but refer to the accounts, so they are cleared
+// when replacing the data, not to leave the sessions and the credentials of the replaced accounts.
+var accountTables = []string{"account_session", "password_reset_token", "refresh_token", "api_key"}
+
+func newTable[T tabler](serial bool) table {
+	var record T
+	return table{
+		name:   record.TableName(),
+		serial: serial,
+		count: func(rep repository.Repository) (int64, error) {
+			var count int64
+			err := rep.Model(new(T)).Count(&count).Error
+			return count, err
+		},
+		dump: func(rep repository.Repository, w io.Writer) (int, error) {
+			rows, err := rep.Model(new(T)).Order("id").Rows()
+			if err != nil {
+				return 0, err
+			}
+			defer rows.Close()
+
+			enc := json.NewEncoder(w)
+			count := 0
+			for rows.Next() {
+				var r T
+				if err := rep.ScanRows(rows, &r); err != nil {
+					return 0, err
+				}
+				if err := enc.Encode(&r); err != nil {
+					return 0, err
+				}
+				count++
+			}
+			return count, rows.Err()
+		},
+		restore: func(rep repository.Repository, r io.Reader) (int, error) {
+			dec := json.NewDecoder(r)
+			batch := make([]T, 0, restoreBatchSize)
+			count := 0
+			for {
+				var record T
+				err := dec.Decode(&record)
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return 0, err
+				}
+				if batch = append(batch, record); len(batch) == restoreBatchSize {
+					if err := rep.Create(&batch).Error; err != nil {
+						return 0, err
+					}
+					count += len(batch)
+					batch = batch[:0]
+				}
+			}
+			if len(batch) > 0 {
+				if err := rep.Create(&batch).Error; err != nil {
+					return 0, err
+				}
+				count += len(batch)
+			}
+			return count, nil
+		},
+	}
+}
+
+// Dump writes every table to the tar.gz archive. The rows of each table are written as NDJSON in the order
+// of restoring, and the manifest which counts them is the last entry. Each table is spooled to a temporary file
+// until its size is known, so that the whole dataset isn't held in the memory.
+func Dump(rep repository.Repository, dialect string, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{Format: FormatName, Version: FormatVersion, CreatedAt: time.Now().UTC(), Dialect: dialect}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, t := range tables {
+		entry := TableEntry{Name: t.name, File: t.name + entrySuffix}
+		rows, err := dumpEntry(rep, tw, t, entry.File, manifest.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dump %s: %w", t.name, err)
+		}
+		entry.Rows = rows
+		manifest.Tables = append(manifest.Tables, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestFile, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// dumpEntry dumps the rows of the table to a temporary file, and copies it to the archive as an entry.
+func dumpEntry(rep repository.Repository, tw *tar.Writer, t table, name string, modTime time.Time) (int, error) {
+	f, err := os.CreateTemp("", "backup-*"+entrySuffix)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	buf := bufio.NewWriter(f)
+	rows, err := t.dump(rep, buf)
+	if err != nil {
+		return 0, err
+	}
+	if err := buf.Flush(); err != nil {
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: size, ModTime: modTime}); err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+// Restore inserts the rows of the archive into the empty database with their IDs in a transaction.
+// If replace is true, the database doesn't have to be empty, because the current rows are deleted in the same transaction.
+// The manifest is the last entry of the archive, or the first entry of the archives before the version 5.
+// The pending deliveries of the webhooks are restored as dead, not to send the events of the archive again.
+// The sequences of the IDs are moved after the restored rows on PostgreSQL.
+func Restore(rep repository.Repository, dialect string, r io.Reader, replace bool) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	var manifest *Manifest
+	if header.Name == manifestFile {
+		if manifest, err = readManifest(tr); err != nil {
+			return nil, err
+		}
+	}
+
+	if !replace {
+		if err := checkEmpty(rep); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rep.Transaction(func(txRep repository.Repository) error {
+		if replace {
+			if err := clearTables(txRep); err != nil {
+				return err
+			}
+		}
+		if manifest != nil {
+			if err := restoreByManifest(txRep, tr, manifest); err != nil {
+				return err
+			}
+		} else if manifest, err = restoreEntries(txRep, tr, header); err != nil {
+			return err
+		}
+		if err := txRep.Exec("update webhook_delivery set status = ?, last_error = ? where status = ?",
+			model.DeliveryDead, "restored from the backup", model.DeliveryPending).Error; err != nil {
+			return err
+		}
+		if dialect == repository.POSTGRES {
+			return resetSequences(txRep)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// restoreByManifest restores the entries listed by the manifest which has been read at first.
+func restoreByManifest(txRep repository.Repository, tr *tar.Reader, manifest *Manifest) error {
+	for _, entry := range manifest.Tables {
+		header, err := tr.Next()
+		if err != nil || header.Name != entry.File {
+			return fmt.Errorf("%w: %s is missing", ErrInvalidArchive, entry.File)
+		}
+		rows, err := restoreEntry(txRep, tr, entry.Name)
+		if err != nil {
+			return err
+		}
+		if rows != entry.Rows {
+			return fmt.Errorf("%w: %s has %d rows, but the manifest says %d", ErrInvalidArchive, entry.File, rows, entry.Rows)
+		}
+	}
+	return nil
+}
+
+// restoreEntries restores the entries from given header until the manifest, and checks that the manifest
+// lists the restored entries.
+func restoreEntries(txRep repository.Repository, tr *tar.Reader, header *tar.Header) (*Manifest, error) {
+	var restored []TableEntry
+	for header.Name != manifestFile {
+		name := strings.TrimSuffix(header.Name, entrySuffix)
+		rows, err := restoreEntry(txRep, tr, name)
+		if err != nil {
+			return nil, err
+		}
+		restored = append(restored, TableEntry{Name: name, File: header.Name, Rows: rows})
+		if header, err = tr.Next(); err != nil {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, manifestFile)
+		}
+	}
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Tables) != len(restored) {
+		return nil, fmt.Errorf("%w: the archive has %d tables, but the manifest says %d", ErrInvalidArchive,
+			len(restored), len(manifest.Tables))
+	}
+	for i, entry := range manifest.Tables {
+		if entry != restored[i] {
+			return nil, fmt.Errorf("%w: %s has %d rows, but the manifest says %s has %d rows", ErrInvalidArchive,
+				restored[i].File, restored[i].Rows, entry.File, entry.Rows)
+		}
+	}
+	return manifest, nil
+}
+
+// restoreEntry inserts the rows of the current entry into the table of given name.
+func restoreEntry(txRep repository.Repository, tr *tar.Reader, name string) (int, error) {
+	t, ok := findTable(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown table %s", ErrInvalidArchive, name)
+	}
+	rows, err := t.restore(txRep, tr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore %s: %w", name, err)
+	}
+	return rows, nil
+}
+
+// readManifest decodes the manifest of the current entry, and checks the format and the version.
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if manifest.Format != FormatName {
+		return nil, ErrInvalidArchive
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, manifest.Version)
+	}
+	return manifest, nil
+}
+
+// checkEmpty returns ErrNotEmpty if any table has rows.
+func checkEmpty(rep repository.Repository) error {
+	for _, t := range tables {
+		count, err := t.count(rep)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrNotEmpty
+		}
+	}
+	return nil
+}
+
+// clearTables deletes the rows of every table in the reverse order of restoring, after the tables which refer to the accounts.
+func clearTables(txRep repository.Repository) error {
+	names := append([]string{}, accountTables...)
+	for i := len(tables) - 1; i >= 0; i-- {
+		names = append(names, tables[i].name)
+	}
+	for _, name := range names {
+		if err := txRep.Exec(fmt.Sprintf("delete from %s", name)).Error; err != nil {
+			return fmt.Errorf("failed to clear %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resetSequences sets the sequences to the next of the max ID, because inserting the IDs doesn't move them.
+func resetSequences(txRep repository.Repository) error {
+	for _, t := range tables {
+		if !t.serial {
+			continue
+		}
+		sql := fmt.Sprintf("select setval(pg_get_serial_sequence('%s', 'id'), "+
+			"coalesce((select max(id) from %s), 0) + 1, false)", t.name, t.name)
+		if err := txRep.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findTable(name string) (table, bool) {
+	for _, t := range tables {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return table{}, false
+}
+
+func writeEntry(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), ModTime: modTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
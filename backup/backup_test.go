@@ -0,0 +1,126 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/lyh-demo/go-webapp-demo/backup"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type entry struct {
+	name    string
+	content []byte
+}
+
+// prepareForBackupTest creates a webhook with a pending delivery, and returns the archive of the database.
+func prepareForBackupTest(t *testing.T) (container.Container, []byte) {
+	container := test.PrepareForServiceTest()
+	rep := container.GetRepository()
+	webhook, _ := model.NewWebhook("http://localhost/hook", []string{"book.created"}, "secret", true).Create(rep)
+	_, _ = model.NewWebhookDelivery(webhook.ID, "book.created", "{}").Create(rep)
+
+	var buf bytes.Buffer
+	_, err := backup.Dump(rep, container.GetConfig().Database.Dialect, &buf)
+	assert.NoError(t, err)
+	return container, buf.Bytes()
+}
+
+func readEntries(t *testing.T, archive []byte) []entry {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var entries []entry
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(tr)
+		entries = append(entries, entry{name: header.Name, content: content})
+	}
+}
+
+func writeEntries(entries []entry) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		_ = tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0600, Size: int64(len(e.content))})
+		_, _ = tw.Write(e.content)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func findDeliveryStatus(container container.Container) string {
+	var status string
+	container.GetRepository().Raw("select status from webhook_delivery").Scan(&status)
+	return status
+}
+
+func TestBackup_ManifestIsLast(t *testing.T) {
+	_, archive := prepareForBackupTest(t)
+
+	entries := readEntries(t, archive)
+
+	last := entries[len(entries)-1]
+	assert.Equal(t, "manifest.json", last.name)
+	manifest := &backup.Manifest{}
+	assert.NoError(t, json.Unmarshal(last.content, manifest))
+	assert.Equal(t, backup.FormatVersion, manifest.Version)
+	assert.Len(t, manifest.Tables, len(entries)-1)
+}
+
+func TestRestore_PendingDeliveryIsDead(t *testing.T) {
+	container, archive := prepareForBackupTest(t)
+	assert.Equal(t, model.DeliveryPending, findDeliveryStatus(container))
+
+	manifest, err := backup.Restore(container.GetRepository(), container.GetConfig().Database.Dialect,
+		bytes.NewReader(archive), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, backup.FormatVersion, manifest.Version)
+	assert.Equal(t, model.DeliveryDead, findDeliveryStatus(container))
+}
+
+func TestRestore_LeadingManifest(t *testing.T) {
+	container, archive := prepareForBackupTest(t)
+	entries := readEntries(t, archive)
+	manifest := &backup.Manifest{}
+	_ = json.Unmarshal(entries[len(entries)-1].content, manifest)
+	manifest.Version = 4
+	data, _ := json.Marshal(manifest)
+	// the archives before the version 5 have the manifest as the first entry.
+	old := append([]entry{{name: "manifest.json", content: data}}, entries[:len(entries)-1]...)
+
+	result, err := backup.Restore(container.GetRepository(), container.GetConfig().Database.Dialect,
+		bytes.NewReader(writeEntries(old)), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Version)
+}
+
+func TestRestore_ManifestMismatch(t *testing.T) {
+	container, archive := prepareForBackupTest(t)
+	entries := readEntries(t, archive)
+	manifest := &backup.Manifest{}
+	_ = json.Unmarshal(entries[len(entries)-1].content, manifest)
+	manifest.Tables[0].Rows++
+	entries[len(entries)-1].content, _ = json.Marshal(manifest)
+
+	_, err := backup.Restore(container.GetRepository(), container.GetConfig().Database.Dialect,
+		bytes.NewReader(writeEntries(entries)), true)
+
+	assert.ErrorIs(t, err, backup.ErrInvalidArchive)
+	assert.Equal(t, model.DeliveryPending, findDeliveryStatus(container))
+}
@@ -0,0 +1,140 @@
+package backup
+
+import "time"
+
+// The records below define the columns written to the archive, independently of the JSON of the models,
+// so the archive keeps the password hashes and the secrets which the API never returns.
+// Changing them requires a new version of the archive format.
+
+type authorityRecord struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func (authorityRecord) TableName() string { return "authority_master" }
+
+type accountRecord struct {
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	Password       string `json:"password"`
+	Email          string `json:"email"`
+	AuthorityID    uint   `json:"authorityId"`
+	Locale         string `json:"locale"`
+	Disabled       bool   `json:"disabled"`
+	SessionVersion uint   `json:"sessionVersion"`
+}
+
+func (accountRecord) TableName() string { return "account_master" }
+
+type authorityPermissionRecord struct {
+	ID          uint   `json:"id"`
+	AuthorityID uint   `json:"authorityId"`
+	Permission  string `json:"permission"`
+}
+
+func (authorityPermissionRecord) TableName() string { return "authority_permission" }
+
+type accountAuthorityRecord struct {
+	ID          uint `json:"id"`
+	AccountID   uint `json:"accountId"`
+	AuthorityID uint `json:"authorityId"`
+}
+
+func (accountAuthorityRecord) TableName() string { return "account_authority" }
+
+type accountIdentityRecord struct {
+	ID          uint      `json:"id"`
+	AccountID   uint      `json:"accountId"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	Provisioned bool      `json:"provisioned"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (accountIdentityRecord) TableName() string { return "account_identity" }
+
+type twoFactorRecord struct {
+	ID          uint      `json:"id"`
+	AccountID   uint      `json:"accountId"`
+	Secret      string    `json:"secret"`
+	Enabled     bool      `json:"enabled"`
+	LastCounter int64     `json:"lastCounter"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (twoFactorRecord) TableName() string { return "two_factor" }
+
+type recoveryCodeRecord struct {
+	ID        uint       `json:"id"`
+	AccountID uint       `json:"accountId"`
+	CodeHash  string     `json:"codeHash"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (recoveryCodeRecord) TableName() string { return "recovery_code" }
+
+type categoryRecord struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func (categoryRecord) TableName() string { return "category_master" }
+
+type formatRecord struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	MediaType string `json:"mediaType"`
+}
+
+func (formatRecord) TableName() string { return "format_master" }
+
+type bookRecord struct {
+	ID         uint      `json:"id"`
+	Title      string    `json:"title"`
+	Isbn       string    `json:"isbn"`
+	CategoryID uint      `json:"categoryId"`
+	FormatID   uint      `json:"formatId"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func (bookRecord) TableName() string { return "book" }
+
+type deletedBookRecord struct {
+	ID         uint      `json:"id"`
+	CategoryID uint      `json:"categoryId"`
+	DeletedAt  time.Time `json:"deletedAt"`
+}
+
+func (deletedBookRecord) TableName() string { return "deleted_book" }
+
+type webhookRecord struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    string    `json:"events"`
+	Secret    string    `json:"secret"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (webhookRecord) TableName() string { return "webhook" }
+
+type webhookDeliveryRecord struct {
+	ID             uint       `json:"id"`
+	WebhookID      uint       `json:"webhookId"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	ResponseStatus int        `json:"responseStatus"`
+	LastError      string     `json:"lastError"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func (webhookDeliveryRecord) TableName() string { return "webhook_delivery" }
@@ -0,0 +1,182 @@
+package broker
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/logger"
+	"sync"
+	"time"
+)
+
+// Entity types of events.
+const (
+	// EntityBook represents the events of books.
+	EntityBook = "book"
+)
+
+// Actions of events.
+const (
+	// ActionCreated represents the event which an entity is created.
+	ActionCreated = "created"
+	// ActionUpdated represents the event which an entity is updated.
+	ActionUpdated = "updated"
+	// ActionDeleted represents the event which an entity is deleted.
+	ActionDeleted = "deleted"
+)
+
+// Entities is the list of entity types which clients can subscribe.
+var Entities = []string{EntityBook}
+
+const (
+	defaultHistorySize = 256
+	defaultBufferSize  = 64
+)
+
+// Event represents a change of data which is pushed to the subscribers.
+type Event struct {
+	ID         uint64      `json:"id"`
+	Type       string      `json:"type"`
+	Entity     string      `json:"entity"`
+	Action     string      `json:"action"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// Subscription represents a subscriber of the broker.
+// The channel of events is closed when the subscriber is unsubscribed or falls too far behind.
+type Subscription struct {
+	// Replay has the events published after the event ID given at subscribing.
+	Replay []*Event
+	// Missed is true if some events after the given event ID are no longer kept,
+	// so the subscriber should reload the data instead of resuming.
+	Missed   bool
+	events   chan *Event
+	entities map[string]bool
+}
+
+// Broker represents an in-process broker which fans out events to subscribers.
+type Broker interface {
+	Publish(entity string, action string, data interface{}) *Event
+	Subscribe(entities []string, lastEventID uint64) *Subscription
+	Unsubscribe(s *Subscription)
+}
+
+type broker struct {
+	mu          sync.Mutex
+	logger      logger.Logger
+	lastID      uint64
+	history     []*Event
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroker is constructor.
+func NewBroker(logger logger.Logger, conf *config.Config) Broker {
+	b := &broker{
+		logger:      logger,
+		historySize: conf.Event.HistorySize,
+		bufferSize:  conf.Event.BufferSize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+	if b.historySize <= 0 {
+		b.historySize = defaultHistorySize
+	}
+	if b.bufferSize <= 0 {
+		b.bufferSize = defaultBufferSize
+	}
+	return b
+}
+
+// Publish sends a new event to the subscribers of the entity type, and keeps it in the history for resuming.
+// A subscriber whose buffer is full is dropped instead of blocking the publisher.
+func (b *broker) Publish(entity string, action string, data interface{}) *Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e := &Event{
+		ID:         b.lastID,
+		Type:       entity + "." + action,
+		Entity:     entity,
+		Action:     action,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for s := range b.subscribers {
+		if !s.accepts(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			b.logger.GetZapLogger().Infof("Dropped the slow subscriber of events")
+			b.remove(s)
+		}
+	}
+	return e
+}
+
+// Subscribe registers a subscriber of the given entity types. All entity types are subscribed if it is empty.
+// The events after lastEventID are replayed if it isn't zero.
+func (b *broker) Subscribe(entities []string, lastEventID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &Subscription{events: make(chan *Event, b.bufferSize), entities: make(map[string]bool)}
+	for _, entity := range entities {
+		s.entities[entity] = true
+	}
+
+	if lastEventID > 0 {
+		// the ID greater than the last one means the events were lost by restarting.
+		s.Missed = lastEventID > b.lastID ||
+			(lastEventID < b.lastID && (len(b.history) == 0 || b.history[0].ID > lastEventID+1))
+		for _, e := range b.history {
+			if e.ID > lastEventID && s.accepts(e) {
+				s.Replay = append(s.Replay, e)
+			}
+		}
+	}
+
+	b.subscribers[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe removes the subscriber and closes its channel.
+func (b *broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(s)
+}
+
+func (b *broker) remove(s *Subscription) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.events)
+	}
+}
+
+// Events returns the channel which receives the events published after subscribing.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+func (s *Subscription) accepts(e *Event) bool {
+	return len(s.entities) == 0 || s.entities[e.Entity]
+}
+
+// IsEntity returns true if the given name is one of the entity types.
+func IsEntity(name string) bool {
+	for _, entity := range Entities {
+		if entity == name {
+			return true
+		}
+	}
+	return false
+}
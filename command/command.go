@@ -0,0 +1,116 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/migration"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// Backup is the subcommand which writes every table to an archive.
+	Backup = "backup"
+	// Restore is the subcommand which restores an archive into the empty database.
+	Restore = "restore"
+	// stdio is the file name which represents the standard input or output.
+	stdio = "-"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// Run executes the subcommand given as the arguments, and returns the exit status.
+func Run(container container.Container, args []string) int {
+	switch args[0] {
+	case Backup:
+		return runBackup(container, args[1:])
+	case Restore:
+		return runRestore(container, args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command: %s\nUsage: go-webapp-demo [-env ENV] [backup|restore] -file FILE [-replace]\n", args[0])
+	return exitUsage
+}
+
+func runBackup(container container.Container, args []string) int {
+	flags := flag.NewFlagSet(Backup, flag.ContinueOnError)
+	file := flags.String("file", fmt.Sprintf("backup-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z")),
+		"The archive to write, or - for the standard output.")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *file == stdio {
+		return backupTo(container, os.Stdout)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	code := backupTo(container, f)
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = exitError
+	}
+	// the partial archive is removed, not to be mistaken for a complete backup.
+	if code != exitOK {
+		_ = os.Remove(*file)
+	}
+	return code
+}
+
+// backupTo writes the archive to the writer, and prints the rows of each table.
+func backupTo(container container.Container, w io.Writer) int {
+	manifest, err := service.NewBackupService(container).Backup(w)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	for _, t := range manifest.Tables {
+		fmt.Fprintf(os.Stderr, "%s: %d rows\n", t.Name, t.Rows)
+	}
+	return exitOK
+}
+
+func runRestore(container container.Container, args []string) int {
+	flags := flag.NewFlagSet(Restore, flag.ContinueOnError)
+	file := flags.String("file", "", "The archive to restore, or - for the standard input.")
+	replace := flags.Bool("replace", false, "Replace the current data instead of requiring the empty database.")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *file == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != stdio {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// the tables are created if they don't exist, but the data is never dropped unless -replace is given.
+	migration.MigrateDatabase(container)
+	manifest, err := service.NewBackupService(container).Restore(r, *replace)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	for _, t := range manifest.Tables {
+		fmt.Fprintf(os.Stderr, "%s: %d rows\n", t.Name, t.Rows)
+	}
+	return exitOK
+}
@@ -4,9 +4,12 @@ import (
 	"embed"
 	"flag"
 	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
 	"github.com/lyh-demo/go-webapp-demo/util"
 	"gopkg.in/yaml.v3"
+	"io/fs"
 	"os"
+	"strings"
 )
 
 type DatabaseConfig struct {
@@ -24,10 +27,23 @@ type RedisConfig struct {
 	Host               string
 	Port               string
 }
+type SessionConfig struct {
+	Name     string `default:"SESSION"`
+	Keys     []SessionKeyConfig
+	Secure   bool   `default:"false"`
+	SameSite string `yaml:"same_site" default:"lax"`
+	Domain   string
+	MaxAge   int `yaml:"max_age" default:"0"`
+}
+type SessionKeyConfig struct {
+	AuthenticationKey string `yaml:"authentication_key"`
+	EncryptionKey     string `yaml:"encryption_key"`
+}
 type ExtensionConfig struct {
-	MasterGenerator bool `yaml:"master_generator" default:"false"`
-	CorsEnabled     bool `yaml:"cors_enabled" default:"false"`
-	SecurityEnabled bool `yaml:"security_enabled" default:"false"`
+	MasterGenerator  bool     `yaml:"master_generator" default:"false"`
+	CorsEnabled      bool     `yaml:"cors_enabled" default:"false"`
+	CorsAllowOrigins []string `yaml:"cors_allow_origins"`
+	SecurityEnabled  bool     `yaml:"security_enabled" default:"false"`
 }
 type LogConfig struct {
 	RequestLogFormat string `yaml:"request_log_format" default:"${remote_ip} ${account_name} ${uri} ${method} ${status}"`
@@ -40,21 +56,136 @@ type SwaggerConfig struct {
 	Path    string
 }
 type SecurityConfig struct {
-	AuthPath    []string `yaml:"auth_path"`
-	ExcludePath []string `yaml:"exclude_path"`
-	UserPath    []string `yaml:"user_path"`
-	AdminPath   []string `yaml:"admin_path"`
+	AuthPath        []string `yaml:"auth_path"`
+	ExcludePath     []string `yaml:"exclude_path"`
+	CSRFExcludePath []string `yaml:"csrf_exclude_path"`
+}
+type OaiConfig struct {
+	RepositoryName       string   `yaml:"repository_name" default:"go-webapp-demo"`
+	RepositoryIdentifier string   `yaml:"repository_identifier" default:"localhost"`
+	AdminEmail           []string `yaml:"admin_email"`
+	PageSize             int      `yaml:"page_size" default:"100"`
+}
+type OpdsConfig struct {
+	MaxPageSize     int    `yaml:"max_page_size" default:"100"`
+	AcquisitionPath string `yaml:"acquisition_path" default:"/files/books/%d"`
+}
+type GraphQLConfig struct {
+	MaxPageSize int `yaml:"max_page_size" default:"100"`
+	MaxDepth    int `yaml:"max_depth" default:"8"`
+}
+type FeedConfig struct {
+	Size        int    `default:"20"`
+	SpaBookPath string `yaml:"spa_book_path" default:"/?book=%d"`
+}
+type WebhookConfig struct {
+	Enabled     bool `default:"false"`
+	Interval    int  `default:"5"`
+	Timeout     int  `default:"10"`
+	MaxAttempts int  `yaml:"max_attempts" default:"8"`
+	BackoffBase int  `yaml:"backoff_base" default:"30"`
+	BackoffMax  int  `yaml:"backoff_max" default:"3600"`
+	BatchSize   int  `yaml:"batch_size" default:"50"`
+}
+type EventConfig struct {
+	Heartbeat   int `default:"15"`
+	HistorySize int `yaml:"history_size" default:"256"`
+	BufferSize  int `yaml:"buffer_size" default:"64"`
+}
+type GrpcConfig struct {
+	Enabled bool   `default:"false"`
+	Port    string `default:"9090"`
+}
+type NotifierConfig struct {
+	Type     string `default:"log"`
+	FilePath string `yaml:"file_path" default:"notifications.log"`
+}
+type PasswordResetConfig struct {
+	TokenExpiry   int    `yaml:"token_expiry" default:"30"`
+	ResetURL      string `yaml:"reset_url" default:"/?reset_token=%s"`
+	MaxRequests   int    `yaml:"max_requests" default:"3"`
+	MaxIPRequests int    `yaml:"max_ip_requests" default:"20"`
+	Window        int    `default:"60"`
+}
+type JWTConfig struct {
+	Enabled            bool   `default:"false"`
+	Issuer             string `default:"go-webapp-demo"`
+	Audience           string `default:"go-webapp-demo"`
+	Algorithm          string `default:"RS256"`
+	AccessTokenExpiry  int    `yaml:"access_token_expiry" default:"15"`
+	RefreshTokenExpiry int    `yaml:"refresh_token_expiry" default:"10080"`
+	Keys               []JWTKeyConfig
+}
+type JWTKeyConfig struct {
+	ID             string
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+type OIDCConfig struct {
+	Enabled           bool `default:"false"`
+	Issuer            string
+	ClientID          string                 `yaml:"client_id"`
+	ClientSecret      string                 `yaml:"client_secret"`
+	RedirectURL       string                 `yaml:"redirect_url"`
+	PostLoginURL      string                 `yaml:"post_login_url" default:"/"`
+	TwoFactorURL      string                 `yaml:"two_factor_url" default:"/"`
+	Scopes            []string               `default:"openid,profile,email"`
+	GroupsClaim       string                 `yaml:"groups_claim" default:"groups"`
+	DefaultAuthority  string                 `yaml:"default_authority" default:"User"`
+	AuthorityMappings []OIDCAuthorityMapping `yaml:"authority_mappings"`
+	Timeout           int                    `default:"10"`
+}
+type OIDCAuthorityMapping struct {
+	Group     string
+	Authority string
+}
+type TwoFactorConfig struct {
+	Issuer           string `default:"go-webapp-demo"`
+	RequiredForAdmin bool   `yaml:"required_for_admin" default:"false"`
+	Skew             int    `default:"1"`
+	RecoveryCodes    int    `yaml:"recovery_codes" default:"10"`
+	PendingExpiry    int    `yaml:"pending_expiry" default:"5"`
+}
+type LockoutConfig struct {
+	Enabled       bool `default:"false"`
+	MaxFailures   int  `yaml:"max_failures" default:"5"`
+	MaxIPFailures int  `yaml:"max_ip_failures" default:"20"`
+	Window        int  `default:"15"`
+	LockDuration  int  `yaml:"lock_duration" default:"15"`
+	DelayBase     int  `yaml:"delay_base" default:"1"`
+	DelayMax      int  `yaml:"delay_max" default:"30"`
+}
+type AuditConfig struct {
+	Enabled        bool `default:"false"`
+	RetentionDays  int  `yaml:"retention_days" default:"365"`
+	PurgeInterval  int  `yaml:"purge_interval" default:"60"`
+	ExportLimit    int  `yaml:"export_limit" default:"10000"`
+	DenialInterval int  `yaml:"denial_interval" default:"60"`
 }
 
 // Config represents the composition of yml settings.
 type Config struct {
 	Database       DatabaseConfig       `yaml:"database"`
 	Redis          RedisConfig          `yaml:"redis"`
+	Session        SessionConfig        `yaml:"session"`
 	Extension      ExtensionConfig      `yaml:"extension"`
 	Log            LogConfig            `yaml:"log"`
 	StaticContents StaticContentsConfig `yaml:"static_contents"`
 	Swagger        SwaggerConfig        `yaml:"swagger"`
 	Security       SecurityConfig       `yaml:"security"`
+	Oai            OaiConfig            `yaml:"oai"`
+	Feed           FeedConfig           `yaml:"feed"`
+	Opds           OpdsConfig           `yaml:"opds"`
+	GraphQL        GraphQLConfig        `yaml:"graphql"`
+	Grpc           GrpcConfig           `yaml:"grpc"`
+	Webhook        WebhookConfig        `yaml:"webhook"`
+	Event          EventConfig          `yaml:"event"`
+	Notifier       NotifierConfig       `yaml:"notifier"`
+	PasswordReset  PasswordResetConfig  `yaml:"password_reset"`
+	JWT            JWTConfig            `yaml:"jwt"`
+	OIDC           OIDCConfig           `yaml:"oidc"`
+	TwoFactor      TwoFactorConfig      `yaml:"two_factor"`
+	Lockout        LockoutConfig        `yaml:"lockout"`
+	Audit          AuditConfig          `yaml:"audit"`
 }
 
 const (
@@ -91,12 +222,23 @@ func LoadAppConfig(yamlFile embed.FS) (*Config, string) {
 	return config, *env
 }
 
-// LoadMessagesConfig loads the messages.properties.
-func LoadMessagesConfig(propsFile embed.FS) map[string]string {
+// LoadMessagesConfig loads the messages.properties as the default bundle,
+// and the messages_<locale>.properties as the bundles of the locales.
+func LoadMessagesConfig(propsFile embed.FS) i18n.Bundles {
 	messages := util.ReadPropertiesFile(propsFile, MessagesConfigPath)
 	if messages == nil {
 		fmt.Printf("Failed to load the messages.properties.")
 		os.Exit(ErrExitStatus)
 	}
-	return messages
+
+	localized := make(map[string]map[string]string)
+	prefix, suffix, _ := strings.Cut(MessagesBundlePath, "%s")
+	files, _ := fs.Glob(propsFile, prefix+"*"+suffix)
+	for _, file := range files {
+		locale := strings.TrimSuffix(strings.TrimPrefix(file, prefix), suffix)
+		if bundle := util.ReadPropertiesFile(propsFile, file); bundle != nil {
+			localized[locale] = bundle
+		}
+	}
+	return i18n.NewBundles(messages, localized)
 }
@@ -8,6 +8,8 @@ const (
 	AppConfigPath = "resources/config/application.%s.yml"
 	// MessagesConfigPath is the path of messages.properties.
 	MessagesConfigPath = "resources/config/messages.properties"
+	// MessagesBundlePath is the path of messages_<locale>.properties.
+	MessagesBundlePath = "resources/config/messages_%s.properties"
 	// LoggerConfigPath is the path of zaplogger.yml.
 	LoggerConfigPath = "resources/config/zaplogger.%s.yml"
 )
@@ -22,6 +24,10 @@ const (
 	APIBooks = API + "/books"
 	// APIBooksID represents the API to get book data using id.
 	APIBooksID = APIBooks + "/:id"
+	// APIBooksIDLabel represents the API to print the label of a book.
+	APIBooksIDLabel = APIBooksID + "/label"
+	// APIBooksLabels represents the API to print the labels of books on A4 sheets.
+	APIBooksLabels = APIBooks + "/labels"
 	// APICategories represents the group of category management API.
 	APICategories = API + "/categories"
 	// APIFormats represents the group of format management API.
@@ -35,13 +41,167 @@ const (
 	APIAccountLoginStatus = APIAccount + "/loginStatus"
 	// APIAccountLoginAccount represents the API to get the logged in account.
 	APIAccountLoginAccount = APIAccount + "/loginAccount"
+	// APIAccountCSRFToken represents the API to get the CSRF token of the session.
+	APIAccountCSRFToken = APIAccount + "/csrf"
 	// APIAccountLogin represents the API to login by session authentication.
 	APIAccountLogin = APIAccount + "/login"
+	// APIAccountLoginTwoFactor represents the API to complete the login by the code of the two-factor authentication.
+	APIAccountLoginTwoFactor = APIAccountLogin + "/2fa"
+	// APIAccountLoginTwoFactorSetup represents the API to set up the two-factor authentication required to login.
+	APIAccountLoginTwoFactorSetup = APIAccountLoginTwoFactor + "/setup"
+	// APIAccountLoginTwoFactorConfirm represents the API to enable the two-factor authentication required to login.
+	APIAccountLoginTwoFactorConfirm = APIAccountLoginTwoFactor + "/confirm"
+	// APIAccountOIDCLogin represents the API to start the login through the OpenID Connect provider.
+	APIAccountOIDCLogin = APIAccount + "/oidc/login"
+	// APIAccountOIDCLink represents the API to link the identity of the OpenID Connect provider to the logged-in account.
+	APIAccountOIDCLink = APIAccount + "/oidc/link"
+	// APIAccountOIDCCallback represents the API which the OpenID Connect provider redirects to after the login.
+	APIAccountOIDCCallback = APIAccount + "/oidc/callback"
 	// APIAccountLogout represents the API to logout.
 	APIAccountLogout = APIAccount + "/logout"
+	// APIAccountLocale represents the API to change the preferred locale of the logged in account.
+	APIAccountLocale = APIAccount + "/locale"
+	// APIAccountPassword represents the API to change the password of the logged in account.
+	APIAccountPassword = APIAccount + "/password"
+	// APIAccountPasswordReset represents the API to request the token to reset the password.
+	APIAccountPasswordReset = APIAccountPassword + "/reset"
+	// APIAccountPasswordResetConfirm represents the API to set the new password by using the reset token.
+	APIAccountPasswordResetConfirm = APIAccountPasswordReset + "/confirm"
+	// APIAccountToken represents the API to issue the access token by the bearer authentication.
+	APIAccountToken = APIAccount + "/token"
+	// APIAccountTokenRefresh represents the API to issue a new access token by using the refresh token.
+	APIAccountTokenRefresh = APIAccountToken + "/refresh"
+	// APIAccountTokenRevoke represents the API to revoke the refresh token.
+	APIAccountTokenRevoke = APIAccountToken + "/revoke"
+	// APIAccountAPIKeys represents the group of API key management API of the logged in account.
+	APIAccountAPIKeys = APIAccount + "/apikeys"
+	// APIAccountAPIKeysID represents the API to revoke the API key of the logged in account using id.
+	APIAccountAPIKeysID = APIAccountAPIKeys + "/:id"
+	// APIAccountTwoFactor represents the API to get the status of the two-factor authentication of the logged in account.
+	APIAccountTwoFactor = APIAccount + "/2fa"
+	// APIAccountTwoFactorSetup represents the API to set up the two-factor authentication of the logged in account.
+	APIAccountTwoFactorSetup = APIAccountTwoFactor + "/setup"
+	// APIAccountTwoFactorConfirm represents the API to enable the two-factor authentication by confirming a code.
+	APIAccountTwoFactorConfirm = APIAccountTwoFactor + "/confirm"
+	// APIAccountTwoFactorRecoveryCodes represents the API to regenerate the recovery codes of the logged in account.
+	APIAccountTwoFactorRecoveryCodes = APIAccountTwoFactor + "/recoveryCodes"
+	// APIAccountTwoFactorDisable represents the API to disable the two-factor authentication of the logged in account.
+	APIAccountTwoFactorDisable = APIAccountTwoFactor + "/disable"
+	// APIAccountSessions represents the group of session management API of the logged in account.
+	APIAccountSessions = APIAccount + "/sessions"
+	// APIAccountSessionsID represents the API to revoke the session of the logged in account using id.
+	APIAccountSessionsID = APIAccountSessions + "/:id"
 )
 
 const (
 	// APIHealth represents the API to get the status of this application.
 	APIHealth = API + "/health"
 )
+
+const (
+	// APIGraphQL represents the API to execute GraphQL queries and mutations.
+	APIGraphQL = API + "/graphql"
+)
+
+const (
+	// APIAdmin represents the group of administration API.
+	APIAdmin = API + "/admin"
+	// APIAdminBackup represents the API to download the backup archive.
+	APIAdminBackup = APIAdmin + "/backup"
+	// APIAdminRestore represents the API to restore the backup archive.
+	APIAdminRestore = APIAdmin + "/restore"
+	// APIAdminAccounts represents the group of account management API.
+	APIAdminAccounts = APIAdmin + "/accounts"
+	// APIAdminAccountsID represents the API to get account data using id.
+	APIAdminAccountsID = APIAdminAccounts + "/:id"
+	// APIAdminAccountsIDSessions represents the API to get the sessions of the account or to force it to logout.
+	APIAdminAccountsIDSessions = APIAdminAccountsID + "/sessions"
+	// APIAdminAuthorities represents the group of authority management API.
+	APIAdminAuthorities = APIAdmin + "/authorities"
+	// APIAdminAuthoritiesIDPermissions represents the API to update the permissions of the authority using id.
+	APIAdminAuthoritiesIDPermissions = APIAdminAuthorities + "/:id/permissions"
+	// APIAdminPermissions represents the API to get the permissions which can be assigned to authorities.
+	APIAdminPermissions = APIAdmin + "/permissions"
+	// APIAdminAPIKeys represents the group of API key management API of all accounts.
+	APIAdminAPIKeys = APIAdmin + "/apikeys"
+	// APIAdminAPIKeysID represents the API to revoke the API key of any account using id.
+	APIAdminAPIKeysID = APIAdminAPIKeys + "/:id"
+	// APIAdminLockouts represents the API to get the usernames and the remote IP addresses which are locked out.
+	APIAdminLockouts = APIAdmin + "/lockouts"
+	// APIAdminLockoutsUnlock represents the API to release the lockout of the username or the remote IP address.
+	APIAdminLockoutsUnlock = APIAdminLockouts + "/unlock"
+	// APIAdminAuditLogs represents the API to get the records of the security audit log.
+	APIAdminAuditLogs = APIAdmin + "/auditlogs"
+	// APIAdminAuditLogsCSV represents the API to download the records of the security audit log as CSV.
+	APIAdminAuditLogsCSV = APIAdminAuditLogs + ".csv"
+	// APIAdminAuditLogsVerify represents the API to verify the hash chain of the security audit log.
+	APIAdminAuditLogsVerify = APIAdminAuditLogs + "/verify"
+)
+
+const (
+	// APIReports represents the group of reporting API.
+	APIReports = API + "/reports"
+	// APIReportsSummary represents the API to get the summary report of the catalog.
+	APIReportsSummary = APIReports + "/summary"
+	// APIReportsSummaryCSV represents the API to download the summary report as CSV.
+	APIReportsSummaryCSV = APIReportsSummary + ".csv"
+)
+
+const (
+	// APIEvents represents the API to stream the events of data changes as Server-Sent Events.
+	APIEvents = API + "/events"
+	// APIEventsWebSocket represents the API to stream the events of data changes over WebSocket.
+	APIEventsWebSocket = APIEvents + "/ws"
+)
+
+const (
+	// APIWebhooks represents the group of webhook management API.
+	APIWebhooks = API + "/webhooks"
+	// APIWebhooksID represents the API to manage a webhook using id.
+	APIWebhooksID = APIWebhooks + "/:id"
+	// APIWebhooksIDDeliveries represents the API to get the delivery history of a webhook.
+	APIWebhooksIDDeliveries = APIWebhooksID + "/deliveries"
+	// APIWebhooksDeliveriesRedeliver represents the API to redeliver a delivery using id.
+	APIWebhooksDeliveriesRedeliver = APIWebhooks + "/deliveries/:id/redeliver"
+)
+
+const (
+	// OPDS represents the root of the OPDS catalog.
+	OPDS = "/opds"
+	// OPDSBooks represents the acquisition feed of all books.
+	OPDSBooks = OPDS + "/books"
+	// OPDSCategories represents the navigation feed of categories.
+	OPDSCategories = OPDS + "/categories"
+	// OPDSCategoriesID represents the acquisition feed of books belonging to a category.
+	OPDSCategoriesID = OPDSCategories + "/:id"
+	// OPDSFormats represents the navigation feed of formats.
+	OPDSFormats = OPDS + "/formats"
+	// OPDSFormatsID represents the acquisition feed of books published in a format.
+	OPDSFormatsID = OPDSFormats + "/:id"
+	// OPDSSearch represents the acquisition feed of books matched the search keyword.
+	OPDSSearch = OPDS + "/search"
+	// OPDSOpenSearch represents the OpenSearch description document of the catalog.
+	OPDSOpenSearch = OPDS + "/opensearch.xml"
+)
+
+// OPDSPageSize is the default number of entries per page of an acquisition feed.
+const OPDSPageSize int = 20
+
+const (
+	// OAI represents the endpoint of the OAI-PMH provider.
+	OAI = "/oai"
+)
+
+const (
+	// Feeds represents the group of syndication feeds.
+	Feeds = "/feeds"
+	// FeedsNewAtom represents the Atom feed of newly added books.
+	FeedsNewAtom = Feeds + "/new.atom"
+	// FeedsNewRSS represents the RSS feed of newly added books.
+	FeedsNewRSS = Feeds + "/new.rss"
+)
+
+const (
+	// WellKnownJWKS represents the endpoint of the public keys to verify the access tokens.
+	WellKnownJWKS = "/.well-known/jwks.json"
+)
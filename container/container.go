@@ -1,18 +1,28 @@
 package container
 
 import (
+	"github.com/lyh-demo/go-webapp-demo/broker"
 	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/lockout"
 	"github.com/lyh-demo/go-webapp-demo/logger"
+	"github.com/lyh-demo/go-webapp-demo/notifier"
 	"github.com/lyh-demo/go-webapp-demo/repository"
 	"github.com/lyh-demo/go-webapp-demo/session"
+	"github.com/lyh-demo/go-webapp-demo/token"
 )
 
 // Container represents an interface for accessing the data which sharing in overall application.
 type Container interface {
 	GetRepository() repository.Repository
 	GetSession() session.Session
+	GetBroker() broker.Broker
+	GetNotifier() notifier.Notifier
+	GetKeySet() token.KeySet
+	GetLockoutStore() lockout.Store
 	GetConfig() *config.Config
-	GetMessages() map[string]string
+	GetMessages(locale string) map[string]string
+	GetBundles() i18n.Bundles
 	GetLogger() logger.Logger
 	GetEnv() string
 }
@@ -22,17 +32,22 @@ type Container interface {
 type container struct {
 	rep      repository.Repository
 	session  session.Session
+	broker   broker.Broker
+	notifier notifier.Notifier
+	keySet   token.KeySet
+	lockout  lockout.Store
 	config   *config.Config
-	messages map[string]string
+	bundles  i18n.Bundles
 	logger   logger.Logger
 	env      string
 }
 
 // NewContainer is constructor.
-func NewContainer(rep repository.Repository, s session.Session, config *config.Config,
-	messages map[string]string, logger logger.Logger, env string) Container {
-	return &container{rep: rep, session: s, config: config,
-		messages: messages, logger: logger, env: env}
+func NewContainer(rep repository.Repository, s session.Session, b broker.Broker, n notifier.Notifier,
+	k token.KeySet, l lockout.Store, config *config.Config, bundles i18n.Bundles, logger logger.Logger,
+	env string) Container {
+	return &container{rep: rep, session: s, broker: b, notifier: n, keySet: k, lockout: l, config: config,
+		bundles: bundles, logger: logger, env: env}
 }
 
 // GetRepository returns the object of repository.
@@ -45,14 +60,39 @@ func (c *container) GetSession() session.Session {
 	return c.session
 }
 
+// GetBroker returns the object of event broker.
+func (c *container) GetBroker() broker.Broker {
+	return c.broker
+}
+
+// GetNotifier returns the object of notifier.
+func (c *container) GetNotifier() notifier.Notifier {
+	return c.notifier
+}
+
+// GetKeySet returns the object of the keys of the access tokens.
+func (c *container) GetKeySet() token.KeySet {
+	return c.keySet
+}
+
+// GetLockoutStore returns the object of the store of the failed login attempts.
+func (c *container) GetLockoutStore() lockout.Store {
+	return c.lockout
+}
+
 // GetConfig returns the object of configuration.
 func (c *container) GetConfig() *config.Config {
 	return c.config
 }
 
-// GetMessages returns the map has key and message.
-func (c *container) GetMessages() map[string]string {
-	return c.messages
+// GetMessages returns the map has key and message of the given locale.
+func (c *container) GetMessages(locale string) map[string]string {
+	return c.bundles.GetMessages(locale)
+}
+
+// GetBundles returns the bundles of messages of all locales.
+func (c *container) GetBundles() i18n.Bundles {
+	return c.bundles
 }
 
 // GetLogger returns the object of logger.
@@ -1,34 +1,67 @@
 package controller
 
 import (
+	"encoding/json"
 	"github.com/labstack/echo/v4"
 	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	appMiddleware "github.com/lyh-demo/go-webapp-demo/middleware"
 	"github.com/lyh-demo/go-webapp-demo/model"
 	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/oidc"
 	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/session"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // AccountController is a controller for managing user account.
 type AccountController interface {
 	GetLoginStatus(c echo.Context) error
 	GetLoginAccount(c echo.Context) error
+	GetCSRFToken(c echo.Context) error
 	Login(c echo.Context) error
+	LoginTwoFactor(c echo.Context) error
+	LoginTwoFactorSetup(c echo.Context) error
+	LoginTwoFactorConfirm(c echo.Context) error
 	Logout(c echo.Context) error
+	OIDCLogin(c echo.Context) error
+	OIDCCallback(c echo.Context) error
+	OIDCLink(c echo.Context) error
+	UpdateLocale(c echo.Context) error
+	ChangePassword(c echo.Context) error
+	RequestPasswordReset(c echo.Context) error
+	ResetPassword(c echo.Context) error
+	GetAccount(c echo.Context) error
+	GetAccountList(c echo.Context) error
+	CreateAccount(c echo.Context) error
+	UpdateAccount(c echo.Context) error
+	DeleteAccount(c echo.Context) error
 }
 
 type accountController struct {
-	context      container.Container
-	service      service.AccountService
-	dummyAccount *model.Account
+	context          container.Container
+	service          service.AccountService
+	passwordService  service.PasswordService
+	oidcService      service.OIDCService
+	twoFactorService service.TwoFactorService
+	sessionService   service.SessionService
+	auditService     service.AuditService
+	dummyAccount     *model.Account
 }
 
 // NewAccountController is constructor.
 func NewAccountController(container container.Container) AccountController {
 	return &accountController{
-		context:      container,
-		service:      service.NewAccountService(container),
-		dummyAccount: model.NewAccountWithPlainPassword("test", "test", 1),
+		context:          container,
+		service:          service.NewAccountService(container),
+		passwordService:  service.NewPasswordService(container),
+		oidcService:      service.NewOIDCService(container),
+		twoFactorService: service.NewTwoFactorService(container),
+		sessionService:   service.NewSessionService(container),
+		auditService:     service.NewAuditService(container),
+		dummyAccount:     model.NewAccountWithPlainPassword("test", "test", 1),
 	}
 }
 
@@ -39,7 +72,7 @@ func NewAccountController(container container.Container) AccountController {
 // @Accept  json
 // @Produce  json
 // @Success 200 {boolean} bool "The current user have already logged-in. Returns true."
-// @Failure 401 {boolean} bool "The current user haven't logged-in yet. Returns false."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
 // @Router /auth/loginStatus [get]
 func (controller *accountController) GetLoginStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, true)
@@ -52,7 +85,7 @@ func (controller *accountController) GetLoginStatus(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} model.Account "Success to fetch the account data. If the security function is disable, it returns the dummy data."
-// @Failure 401 {boolean} bool "The current user haven't logged-in yet. Returns false."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
 // @Router /auth/loginAccount [get]
 func (controller *accountController) GetLoginAccount(c echo.Context) error {
 	if !controller.context.GetConfig().Extension.SecurityEnabled {
@@ -61,39 +94,368 @@ func (controller *accountController) GetLoginAccount(c echo.Context) error {
 	return c.JSON(http.StatusOK, controller.context.GetSession().GetAccount(c))
 }
 
+// GetCSRFToken returns the CSRF token of the session.
+// @Summary Get the CSRF token of the session.
+// @Description Get the CSRF token of the session, which is also set to the XSRF-TOKEN cookie. The requests authenticated by the session have to send it back by the X-XSRF-TOKEN header, except for GET, HEAD and OPTIONS.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.CSRFToken "Success to fetch the CSRF token."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 500 {object} controller.Problem "Failed to issue the CSRF token."
+// @Router /auth/csrf [get]
+func (controller *accountController) GetCSRFToken(c echo.Context) error {
+	token, err := controller.context.GetSession().GetCSRFToken(c)
+	if err != nil {
+		controller.context.GetLogger().GetZapLogger().Errorf(err.Error())
+		return service.NewInternalError("Failed to issue the CSRF token", err)
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, &model.CSRFToken{Token: token, HeaderName: appMiddleware.HeaderCSRFToken})
+}
+
 // Login is the method to login using username and password by http post.
 // @Summary Login using username and password.
-// @Description Login using username and password.
+// @Description Login using username and password. If the account has enabled the two-factor authentication, or it is required to enable it, the login waits for the second step and returns 202.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
 // @Param data body dto.LoginDto true "Username and Password for logged-in."
 // @Success 200 {object} model.Account "Success to the authentication."
-// @Failure 401 {boolean} bool "Failed to the authentication."
+// @Success 202 {object} model.LoginChallenge "The code of the two-factor authentication, or the enrollment of it is required."
+// @Failure 400 {object} controller.Problem "Failed to parse the request."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 429 {object} controller.Problem "Too many failed logins of the username or from the remote IP address."
 // @Router /auth/login [post]
 func (controller *accountController) Login(c echo.Context) error {
 	loginDto := dto.NewLoginDto()
 	if err := c.Bind(loginDto); err != nil {
-		return c.JSON(http.StatusBadRequest, loginDto)
+		return err
 	}
 
 	sess := controller.context.GetSession()
-	if account := sess.GetAccount(c); account != nil {
+	if account := sess.GetAccount(c); account != nil && controller.service.ValidateSession(account) == nil &&
+		controller.sessionService.Validate(account, sess.GetSessionToken(c), c.RealIP()) == nil {
 		return c.JSON(http.StatusOK, account)
 	}
 
-	authenticate, a := controller.service.AuthenticateByUsernameAndPassword(loginDto.UserName, loginDto.Password)
-	if authenticate {
-		_ = sess.SetAccount(c, a)
+	a, err := controller.service.AuthenticateByUsernameAndPassword(loginDto.UserName, loginDto.Password, c.RealIP())
+	if err != nil {
+		recordAudit(controller.auditService, newAuditLog(c, model.AuditLogin).WithActorName(loginDto.UserName).
+			WithDetails("password"), err)
+		return err
+	}
+	pending, err := controller.twoFactorService.BeginLogin(a)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		_ = sess.SetValue(c, session.PendingLogin, pending)
 		_ = sess.Save(c)
-		return c.JSON(http.StatusOK, a)
+		return c.JSON(http.StatusAccepted, &model.LoginChallenge{TwoFactorRequired: !pending.EnrollmentRequired,
+			EnrollmentRequired: pending.EnrollmentRequired})
+	}
+	if err = controller.startSession(c, a); err != nil {
+		return err
+	}
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditLogin).WithActor(a).WithDetails("password"), nil)
+	return c.JSON(http.StatusOK, a)
+}
+
+// LoginTwoFactor completes the login waiting for the second step by http post.
+// @Summary Complete the login by the code of the two-factor authentication.
+// @Description Complete the login which has passed the password by the TOTP code or a recovery code. The login has to start again after 5 incorrect codes, and the incorrect codes are counted as the failed logins of the account.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.TwoFactorCodeDto true "The TOTP code or a recovery code."
+// @Success 200 {object} model.Account "Success to the authentication."
+// @Failure 400 {object} controller.Problem "The code is incorrect."
+// @Failure 401 {object} controller.Problem "The login hasn't passed the password, has expired, or requires the enrollment."
+// @Failure 429 {object} controller.Problem "Too many failed logins of the account or from the remote IP address."
+// @Router /auth/login/2fa [post]
+func (controller *accountController) LoginTwoFactor(c echo.Context) error {
+	codeDto := dto.NewTwoFactorCodeDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(codeDto); err != nil {
+		return err
+	}
+
+	pending := controller.getPendingLogin(c)
+	account, err := controller.twoFactorService.CompleteLogin(pending, codeDto, c.RealIP())
+	if err != nil {
+		controller.failPendingLogin(c, pending, err)
+		return err
+	}
+	return controller.completePendingLogin(c, account, account)
+}
+
+// LoginTwoFactorSetup sets up the two-factor authentication which is required to complete the login by http post.
+// @Summary Set up the two-factor authentication required to login.
+// @Description Create a new TOTP secret of the account whose login requires the enrollment of the two-factor authentication. Register the provisioning URI or the QR code to the authenticator app.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.TwoFactorEnrollment "Success to create a new secret."
+// @Failure 401 {object} controller.Problem "The login hasn't passed the password, or has expired."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /auth/login/2fa/setup [post]
+func (controller *accountController) LoginTwoFactorSetup(c echo.Context) error {
+	account, err := controller.findEnrollingAccount(c)
+	if err != nil {
+		return err
+	}
+	enrollment, err := controller.twoFactorService.Setup(account)
+	if err != nil {
+		return err
 	}
-	return c.NoContent(http.StatusUnauthorized)
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+// LoginTwoFactorConfirm enables the two-factor authentication, and completes the login by http post.
+// @Summary Enable the two-factor authentication required to login.
+// @Description Enable the TOTP secret by confirming a code of it, and complete the login. The recovery codes are returned only this time, so keep them safely.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.TwoFactorCodeDto true "The TOTP code of the new secret."
+// @Success 200 {object} model.RecoveryCodes "Success to the authentication."
+// @Failure 400 {object} controller.Problem "The code is incorrect."
+// @Failure 401 {object} controller.Problem "The login hasn't passed the password, or has expired."
+// @Failure 409 {object} controller.Problem "The secret hasn't been set up."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /auth/login/2fa/confirm [post]
+func (controller *accountController) LoginTwoFactorConfirm(c echo.Context) error {
+	codeDto := dto.NewTwoFactorCodeDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(codeDto); err != nil {
+		return err
+	}
+
+	pending := controller.getPendingLogin(c)
+	account, err := controller.findEnrollingAccount(c)
+	if err != nil {
+		return err
+	}
+	codes, err := controller.twoFactorService.Confirm(account, codeDto)
+	if err != nil {
+		controller.failPendingLogin(c, pending, err)
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return controller.completePendingLogin(c, account, codes)
+}
+
+// getPendingLogin returns the login of the session which waits for the second step, or nil if there isn't.
+func (controller *accountController) getPendingLogin(c echo.Context) *model.PendingLogin {
+	v := controller.context.GetSession().GetValue(c, session.PendingLogin)
+	if v == "" {
+		return nil
+	}
+	pending := &model.PendingLogin{}
+	if err := json.Unmarshal([]byte(v), pending); err != nil {
+		return nil
+	}
+	return pending
+}
+
+// findEnrollingAccount returns the account of the pending login which requires the enrollment.
+func (controller *accountController) findEnrollingAccount(c echo.Context) (*model.Account, error) {
+	pending := controller.getPendingLogin(c)
+	account, err := controller.twoFactorService.FindPendingAccount(pending)
+	if err != nil {
+		return nil, err
+	}
+	if !pending.EnrollmentRequired {
+		return nil, service.NewUnauthorizedError("The login requires the code of the two-factor authentication")
+	}
+	return account, nil
+}
+
+// failPendingLogin counts the failure of the pending login, and discards it if it can't be attempted anymore.
+func (controller *accountController) failPendingLogin(c echo.Context, pending *model.PendingLogin, err error) {
+	account, _ := controller.twoFactorService.FindPendingAccount(pending)
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditLogin).WithActor(account).
+		WithDetails("two-factor"), err)
+
+	sess := controller.context.GetSession()
+	if controller.twoFactorService.CountFailure(pending, err) {
+		_ = sess.SetValue(c, session.PendingLogin, pending)
+	} else {
+		_ = sess.SetValue(c, session.PendingLogin, nil)
+	}
+	_ = sess.Save(c)
+}
+
+// completePendingLogin discards the pending login, and starts the session of the account.
+func (controller *accountController) completePendingLogin(c echo.Context, account *model.Account,
+	response interface{}) error {
+	_ = controller.context.GetSession().SetValue(c, session.PendingLogin, nil)
+	if err := controller.startSession(c, account); err != nil {
+		return err
+	}
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditLogin).WithActor(account).
+		WithDetails("two-factor"), nil)
+	return c.JSON(http.StatusOK, response)
+}
+
+// startSession starts a new session tracked for the account, and sets the account and the token to the session.
+func (controller *accountController) startSession(c echo.Context, account *model.Account) error {
+	token, err := controller.sessionService.Start(account, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		return err
+	}
+	sess := controller.context.GetSession()
+	_ = sess.SetAccount(c, account)
+	_ = sess.SetSessionToken(c, token)
+	if _, err = sess.ResetCSRFToken(c); err != nil {
+		controller.context.GetLogger().GetZapLogger().Errorf(err.Error())
+		return service.NewInternalError("Failed to issue the CSRF token", err)
+	}
+	_ = sess.Save(c)
+	return nil
+}
+
+// OIDCLogin starts the login through the OpenID Connect provider by redirecting to it.
+// @Summary Login through the OpenID Connect provider.
+// @Description Redirect to the authorization endpoint of the OpenID Connect provider by the authorization code flow with PKCE. The provider redirects to the callback after the login.
+// @Tags Auth
+// @Success 302
+// @Failure 500 {object} controller.Problem "Failed to connect to the identity provider."
+// @Router /auth/oidc/login [get]
+func (controller *accountController) OIDCLogin(c echo.Context) error {
+	request, err := controller.oidcService.BeginLogin(nil)
+	if err != nil {
+		return err
+	}
+	sess := controller.context.GetSession()
+	_ = sess.SetValue(c, session.OIDCRequest, request)
+	_ = sess.Save(c)
+	return c.Redirect(http.StatusFound, request.URL)
+}
+
+// OIDCLink starts to link the identity of the OpenID Connect provider to the logged-in account.
+// @Summary Link the identity of the OpenID Connect provider.
+// @Description Return the URL of the authorization endpoint of the OpenID Connect provider to link the identity to the account logged in by the session. The identity is linked by the callback after the login to the provider, and the account can login through the provider since then. The email of the identity never links it to an account.
+// @Tags Auth
+// @Produce  json
+// @Success 200 {object} model.IdentityLink "The URL of the provider to redirect to."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session, or the CSRF token is incorrect."
+// @Failure 500 {object} controller.Problem "Failed to connect to the identity provider."
+// @Router /auth/oidc/link [post]
+func (controller *accountController) OIDCLink(c echo.Context) error {
+	sess := controller.context.GetSession()
+	if !sess.IsSessionAuthenticated(c) {
+		return service.NewForbiddenError("The identity can be linked only to the account logged in by the session")
+	}
+	request, err := controller.oidcService.BeginLogin(sess.GetAccount(c))
+	if err != nil {
+		return err
+	}
+	_ = sess.SetValue(c, session.OIDCRequest, request)
+	_ = sess.Save(c)
+	return c.JSON(http.StatusOK, &model.IdentityLink{URL: request.URL})
+}
+
+// OIDCCallback completes the login through the OpenID Connect provider, and redirects to the application.
+// @Summary Complete the login through the OpenID Connect provider.
+// @Description Exchange the authorization code for the ID token, and login with the account linked to the identity. The account is created if it doesn't exist, and the authorities of the created account are mapped from the groups of the identity. If the request has been started by the link, the identity is linked to the logged-in account instead. If the account has enabled or is required to enable the two-factor authentication, it redirects to the two-factor URL with twoFactor=required or enrollment=required, and the login is completed by the second step as well as the login by the password.
+// @Tags Auth
+// @Param code query string false "The authorization code."
+// @Param state query string true "The state of the authorization request."
+// @Param error query string false "The error of the authorization request."
+// @Success 302
+// @Failure 401 {object} controller.Problem "The state or the ID token is invalid, the provider denied the login, or the account is disabled."
+// @Failure 409 {object} controller.Problem "The account is the last administrator, or the identity is linked to another account."
+// @Failure 500 {object} controller.Problem "Failed to connect to the identity provider."
+// @Router /auth/oidc/callback [get]
+func (controller *accountController) OIDCCallback(c echo.Context) error {
+	callbackDto := dto.NewOIDCCallbackDto()
+	if err := c.Bind(callbackDto); err != nil {
+		return err
+	}
+
+	sess := controller.context.GetSession()
+	var request *oidc.AuthRequest
+	if v := sess.GetValue(c, session.OIDCRequest); v != "" {
+		request = &oidc.AuthRequest{}
+		_ = json.Unmarshal([]byte(v), request)
+	}
+	_ = sess.SetValue(c, session.OIDCRequest, nil)
+
+	if request != nil && request.LinkAccountID != 0 {
+		return controller.completeLink(c, callbackDto, request)
+	}
+	account, err := controller.oidcService.CompleteLogin(callbackDto, request)
+	if err != nil {
+		recordAudit(controller.auditService, newAuditLog(c, model.AuditLogin).WithDetails("oidc"), err)
+		_ = sess.Save(c)
+		return err
+	}
+	pending, err := controller.twoFactorService.BeginLogin(account)
+	if err != nil {
+		_ = sess.Save(c)
+		return err
+	}
+	if pending != nil {
+		_ = sess.SetValue(c, session.PendingLogin, pending)
+		_ = sess.Save(c)
+		return c.Redirect(http.StatusFound, twoFactorRedirect(controller.context.GetConfig().OIDC.TwoFactorURL, pending))
+	}
+	if err = controller.startSession(c, account); err != nil {
+		return err
+	}
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditLogin).WithActor(account).WithDetails("oidc"), nil)
+
+	redirect := controller.context.GetConfig().OIDC.PostLoginURL
+	if redirect == "" {
+		redirect = "/"
+	}
+	return c.Redirect(http.StatusFound, redirect)
+}
+
+// completeLink links the identity of the callback to the account which has started the link, and redirects
+// to the application. The session is kept as it is, because the account has already logged in.
+func (controller *accountController) completeLink(c echo.Context, callbackDto *dto.OIDCCallbackDto,
+	request *oidc.AuthRequest) error {
+	sess := controller.context.GetSession()
+	_ = sess.Save(c)
+	current := sess.GetAccount(c)
+	if current == nil || current.ID != request.LinkAccountID {
+		return service.NewUnauthorizedError("The account which has started the link isn't logged in")
+	}
+	_, err := controller.oidcService.CompleteLogin(callbackDto, request)
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditIdentityLink).WithActor(current), err)
+	if err != nil {
+		return err
+	}
+
+	redirect := controller.context.GetConfig().OIDC.PostLoginURL
+	if redirect == "" {
+		redirect = "/"
+	}
+	return c.Redirect(http.StatusFound, redirect)
+}
+
+// twoFactorRedirect returns the URL to enter the code of the two-factor authentication or to enable it,
+// which the login through the OpenID Connect provider redirects to.
+func twoFactorRedirect(base string, pending *model.PendingLogin) string {
+	if base == "" {
+		base = "/"
+	}
+	param := "twoFactor=required"
+	if pending.EnrollmentRequired {
+		param = "enrollment=required"
+	}
+	if strings.Contains(base, "?") {
+		return base + "&" + param
+	}
+	return base + "?" + param
 }
 
 // Logout is the method to logout by http post.
 // @Summary Logout.
-// @Description Logout.
+// @Description Logout, and revoke the current session.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
@@ -101,7 +463,258 @@ func (controller *accountController) Login(c echo.Context) error {
 // @Router /auth/logout [post]
 func (controller *accountController) Logout(c echo.Context) error {
 	sess := controller.context.GetSession()
+	if account := sess.GetAccount(c); account != nil {
+		recordAudit(controller.auditService, newAuditLog(c, model.AuditLogout).WithActor(account), nil)
+	}
+	controller.sessionService.End(sess.GetSessionToken(c))
 	_ = sess.SetAccount(c, nil)
 	_ = sess.Delete(c)
 	return c.NoContent(http.StatusOK)
 }
+
+// UpdateLocale saves the preferred locale of logged-in user by http put.
+// @Summary Change the preferred locale of logged-in user.
+// @Description Change the preferred locale of logged-in user, which is used for the messages unless the lang parameter is given.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.LocaleDto true "The locale such as en and ja."
+// @Success 200 {object} model.Account "Success to change the locale."
+// @Failure 400 {object} controller.Problem "The locale isn't supported."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Router /auth/locale [put]
+func (controller *accountController) UpdateLocale(c echo.Context) error {
+	localeDto := dto.NewLocaleDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(localeDto); err != nil {
+		return err
+	}
+
+	sess := controller.context.GetSession()
+	account := sess.GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	result, err := controller.service.UpdateLocale(account, localeDto)
+	if err != nil {
+		return err
+	}
+	_ = sess.SetAccount(c, result)
+	_ = sess.Save(c)
+	return c.JSON(http.StatusOK, result)
+}
+
+// ChangePassword changes the password of logged-in user by http post.
+// @Summary Change the password of logged-in user.
+// @Description Change the password of logged-in user after checking the current password. The other sessions of the user are logged out.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.PasswordDto true "The current password and the new password."
+// @Success 200 {object} model.Account "Success to change the password."
+// @Failure 400 {object} controller.Problem "The current password is incorrect, or the new password is invalid."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 500 {object} controller.Problem "Failed to the update."
+// @Router /auth/password [post]
+func (controller *accountController) ChangePassword(c echo.Context) error {
+	passwordDto := dto.NewPasswordDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(passwordDto); err != nil {
+		return err
+	}
+
+	sess := controller.context.GetSession()
+	account := sess.GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	result, err := controller.passwordService.ChangePassword(account, passwordDto)
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditPasswordChange).WithActor(account), err)
+	if err != nil {
+		return err
+	}
+	if err = controller.startSession(c, result); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// RequestPasswordReset requests the token to reset the password by http post.
+// @Summary Request the password reset.
+// @Description Request the token to reset the password by the username or the email. The token is delivered by the notifier, and it is accepted even if the account doesn't exist.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.PasswordResetRequestDto true "The username or the email."
+// @Success 202
+// @Failure 400 {object} controller.Problem "Neither the username nor the email is given."
+// @Failure 429 {object} controller.Problem "Too many requests for the account or from the remote IP address."
+// @Failure 500 {object} controller.Problem "Failed to create the token."
+// @Router /auth/password/reset [post]
+func (controller *accountController) RequestPasswordReset(c echo.Context) error {
+	requestDto := dto.NewPasswordResetRequestDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(requestDto); err != nil {
+		return err
+	}
+	if err := controller.passwordService.RequestReset(requestDto, c.RealIP()); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ResetPassword sets the new password by using the reset token by http post.
+// @Summary Reset the password.
+// @Description Set the new password by using the token of the password reset. The token can be used once, and all the sessions of the account are logged out.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.PasswordResetDto true "The token and the new password."
+// @Success 200
+// @Failure 400 {object} controller.Problem "The token is invalid or has expired, or the new password is invalid."
+// @Failure 500 {object} controller.Problem "Failed to the update."
+// @Router /auth/password/reset/confirm [post]
+func (controller *accountController) ResetPassword(c echo.Context) error {
+	resetDto := dto.NewPasswordResetDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(resetDto); err != nil {
+		return err
+	}
+	account, err := controller.passwordService.ResetPassword(resetDto)
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditPasswordReset).WithActor(account), err)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// GetAccount returns one record matched account's id.
+// @Summary Get an account
+// @Description Get an account. The password hash is never returned.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param account_id path int true "Account ID"
+// @Success 200 {object} model.Account "Success to fetch data."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The account doesn't exist."
+// @Router /admin/accounts/{account_id} [get]
+func (controller *accountController) GetAccount(c echo.Context) error {
+	account, err := controller.service.FindAccountByID(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+// GetAccountList returns the list of matched accounts by searching.
+// @Summary Get an account list
+// @Description Get the list of matched accounts by searching in order of the ID
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param query query string false "Keyword of the name"
+// @Param authorityId query int false "Authority ID"
+// @Param page query int false "Page number"
+// @Param size query int false "Item size per page"
+// @Success 200 {object} model.AccountPage "Success to fetch an account list."
+// @Failure 400 {object} controller.Problem "The parameters are invalid."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/accounts [get]
+func (controller *accountController) GetAccountList(c echo.Context) error {
+	accounts, err := controller.service.FindAccounts(c.QueryParam("query"), c.QueryParam("authorityId"),
+		c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, accounts)
+}
+
+// CreateAccount create a new account by http post.
+// @Summary Create a new account
+// @Description Create a new account. The password is stored as the bcrypt hash.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param data body dto.AccountDto true "a new account data for creating"
+// @Success 200 {object} model.Account "Success to create a new account."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 409 {object} controller.Problem "The name is already used."
+// @Failure 422 {object} controller.Problem "The authority doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /admin/accounts [post]
+func (controller *accountController) CreateAccount(c echo.Context) error {
+	accountDto := dto.NewAccountDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(accountDto); err != nil {
+		return err
+	}
+	account, err := controller.service.CreateAccount(accountDto)
+	log := newAuditLog(c, model.AuditAccountCreate).WithActor(controller.context.GetSession().GetAccount(c)).
+		WithDetails(accountDto.Name)
+	if account != nil {
+		log.WithTarget(strconv.FormatUint(uint64(account.ID), 10))
+	}
+	recordAudit(controller.auditService, log, err)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+// UpdateAccount update the existing account by http put.
+// @Summary Update the existing account
+// @Description Update the name, the authority and the status of the existing account. The password is kept if it is omitted, and setting it logs out all the sessions and revokes the refresh tokens of the account.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param account_id path int true "Account ID"
+// @Param data body dto.AccountDto true "the account data for updating"
+// @Success 200 {object} model.Account "Success to update the existing account."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The account doesn't exist."
+// @Failure 409 {object} controller.Problem "The name is already used, or the account is the last administrator."
+// @Failure 422 {object} controller.Problem "The authority doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to the update."
+// @Router /admin/accounts/{account_id} [put]
+func (controller *accountController) UpdateAccount(c echo.Context) error {
+	accountDto := dto.NewAccountDto(controller.context.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(accountDto); err != nil {
+		return err
+	}
+	account, err := controller.service.UpdateAccount(accountDto, c.Param("id"))
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditAccountUpdate).
+		WithActor(controller.context.GetSession().GetAccount(c)).WithTarget(c.Param("id")).
+		WithDetails(accountDto.Name), err)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+// DeleteAccount deletes the existing account by http delete.
+// @Summary Delete the existing account
+// @Description Delete the existing account. The last administrator can't be deleted.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param account_id path int true "Account ID"
+// @Success 200 {object} model.Account "Success to delete the existing account."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The account doesn't exist."
+// @Failure 409 {object} controller.Problem "The account is the last administrator."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /admin/accounts/{account_id} [delete]
+func (controller *accountController) DeleteAccount(c echo.Context) error {
+	account, err := controller.service.DeleteAccount(c.Param("id"))
+	recordAudit(controller.auditService, newAuditLog(c, model.AuditAccountDelete).
+		WithActor(controller.context.GetSession().GetAccount(c)).WithTarget(c.Param("id")), err)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, account)
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	appMiddleware "github.com/lyh-demo/go-webapp-demo/middleware"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCCallback_Provision(t *testing.T) {
+	router, container, mock := prepareForOIDCTest()
+	defer mock.Close()
+	client := newTestClient(router)
+
+	code, state := authorizeOIDC(t, client, mock, config.APIAccountOIDCLogin, "sub-1",
+		map[string]interface{}{"preferred_username": "alice"})
+	rec := client.send("GET", callbackURL(code, state), nil)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/home", rec.Header().Get(echo.HeaderLocation))
+
+	account := loginAccount(client)
+	assert.Equal(t, "alice", account.Name)
+	assert.Equal(t, model.AuthorityUser, account.Authority.Name)
+	assertIdentity(t, container, "sub-1", account.ID, true)
+}
+
+func TestOIDCCallback_InvalidState(t *testing.T) {
+	router, _, mock := prepareForOIDCTest()
+	defer mock.Close()
+	client := newTestClient(router)
+
+	code, _ := authorizeOIDC(t, client, mock, config.APIAccountOIDCLogin, "sub-1", nil)
+	rec := client.send("GET", callbackURL(code, "forged state"), nil)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Nil(t, loginAccount(client))
+}
+
+func TestOIDCCallback_Replay(t *testing.T) {
+	router, _, mock := prepareForOIDCTest()
+	defer mock.Close()
+	client := newTestClient(router)
+
+	code, state := authorizeOIDC(t, client, mock, config.APIAccountOIDCLogin, "sub-1", nil)
+	assert.Equal(t, http.StatusFound, client.send("GET", callbackURL(code, state), nil).Code)
+	rec := client.send("GET", callbackURL(code, state), nil)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestOIDCCallback_InvalidIDToken(t *testing.T) {
+	router, _, mock := prepareForOIDCTest()
+	defer mock.Close()
+
+	for name, claims := range map[string]map[string]interface{}{
+		"issuer":   {"iss": "http://evil.example.com"},
+		"audience": {"aud": "another client"},
+		"nonce":    {"nonce": "replayed nonce"},
+	} {
+		client := newTestClient(router)
+		code, state := authorizeOIDC(t, client, mock, config.APIAccountOIDCLogin, "sub-1", claims)
+		rec := client.send("GET", callbackURL(code, state), nil)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		assert.Nil(t, loginAccount(client), name)
+	}
+}
+
+func TestOIDCCallback_NotLinkedByEmail(t *testing.T) {
+	router, container, mock := prepareForOIDCTest()
+	defer mock.Close()
+	container.GetRepository().Exec("update account_master set email = ? where name = ?", "admin@example.com", "test")
+	client := newTestClient(router)
+
+	code, state := authorizeOIDC(t, client, mock, config.APIAccountOIDCLogin, "sub-1",
+		map[string]interface{}{"preferred_username": "test", "email": "admin@example.com", "email_verified": true})
+	assert.Equal(t, http.StatusFound, client.send("GET", callbackURL(code, state), nil).Code)
+
+	account := loginAccount(client)
+	assert.Equal(t, "test-2", account.Name)
+	assert.Equal(t, "", account.Email)
+	assert.Equal(t, model.AuthorityUser, account.Authority.Name)
+}
+
+func TestOIDCCallback_MapGroupsOfProvisionedAccount(t *testing.T) {
+	router, _, mock := prepareForOIDCTest()
+	defer mock.Close()
+	client := newTestClient(router)
+
+	code, state := authorizeOIDC(t, client, mock, config.APIAccountOIDCLogin, "sub-1",
+		map[string]interface{}{"preferred_username": "alice", "groups": []string{"admins"}})
+	client.send("GET", callbackURL(code, state), nil)
+
+	assert.Equal(t, model.AuthorityAdmin, loginAccount(client).Authority.Name)
+}
+
+func TestOIDCLink_Success(t *testing.T) {
+	router, container, mock := prepareForOIDCTest()
+	defer mock.Close()
+	client := newTestClient(router)
+	client.send("POST", config.APIAccountLogin, map[string]string{"username": "test2", "password": "test2"})
+
+	code, state := authorizeOIDC(t, client, mock, config.APIAccountOIDCLink, "sub-2", nil)
+	rec := client.send("GET", callbackURL(code, state), nil)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assertIdentity(t, container, "sub-2", 2, false)
+
+	// the linked account logs in through the provider, but the groups don't change its authorities.
+	other := newTestClient(router)
+	code, state = authorizeOIDC(t, other, mock, config.APIAccountOIDCLogin, "sub-2",
+		map[string]interface{}{"groups": []string{"users"}})
+	assert.Equal(t, http.StatusFound, other.send("GET", callbackURL(code, state), nil).Code)
+	account := loginAccount(other)
+	assert.Equal(t, "test2", account.Name)
+	assert.Equal(t, model.AuthorityAdmin, account.Authority.Name)
+}
+
+func TestOIDCLink_LinkedToAnotherAccount(t *testing.T) {
+	router, container, mock := prepareForOIDCTest()
+	defer mock.Close()
+	provisioned := newTestClient(router)
+	code, state := authorizeOIDC(t, provisioned, mock, config.APIAccountOIDCLogin, "sub-1", nil)
+	provisioned.send("GET", callbackURL(code, state), nil)
+
+	client := newTestClient(router)
+	client.send("POST", config.APIAccountLogin, map[string]string{"username": "test2", "password": "test2"})
+	code, state = authorizeOIDC(t, client, mock, config.APIAccountOIDCLink, "sub-1", nil)
+	rec := client.send("GET", callbackURL(code, state), nil)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assertIdentity(t, container, "sub-1", loginAccount(provisioned).ID, true)
+}
+
+func TestOIDCLink_NotLoggedIn(t *testing.T) {
+	router, _, mock := prepareForOIDCTest()
+	defer mock.Close()
+	client := newTestClient(router)
+
+	rec := client.send("POST", config.APIAccountOIDCLink, nil)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func prepareForOIDCTest() (*echo.Echo, container.Container, *test.OIDCProvider) {
+	router, container := test.PrepareForControllerTest(true)
+	mock := test.NewOIDCProvider("client", "secret")
+	container.GetConfig().OIDC = config.OIDCConfig{Enabled: true, Issuer: mock.URL(), ClientID: mock.ClientID,
+		ClientSecret: mock.ClientSecret, RedirectURL: "http://localhost" + config.APIAccountOIDCCallback,
+		PostLoginURL: "/home", DefaultAuthority: model.AuthorityUser,
+		AuthorityMappings: []config.OIDCAuthorityMapping{{Group: "admins", Authority: model.AuthorityAdmin},
+			{Group: "users", Authority: model.AuthorityUser}}}
+
+	router.HTTPErrorHandler = NewErrorController(container).JSONError
+	account := NewAccountController(container)
+	router.GET(config.APIAccountLoginAccount, func(c echo.Context) error { return account.GetLoginAccount(c) })
+	router.POST(config.APIAccountLogin, func(c echo.Context) error { return account.Login(c) })
+	router.GET(config.APIAccountOIDCLogin, func(c echo.Context) error { return account.OIDCLogin(c) })
+	router.GET(config.APIAccountOIDCCallback, func(c echo.Context) error { return account.OIDCCallback(c) })
+	router.POST(config.APIAccountOIDCLink, func(c echo.Context) error { return account.OIDCLink(c) })
+	return router, container, mock
+}
+
+// authorizeOIDC starts the login or the link by given path, and returns the code and the state which
+// the mock provider redirects to the callback with.
+func authorizeOIDC(t *testing.T, client *testClient, mock *test.OIDCProvider, path string, subject string,
+	claims map[string]interface{}) (string, string) {
+	var authURL string
+	if path == config.APIAccountOIDCLink {
+		rec := client.send(http.MethodPost, path, nil)
+		link := &model.IdentityLink{}
+		_ = json.Unmarshal(rec.Body.Bytes(), link)
+		authURL = link.URL
+	} else {
+		authURL = client.send(http.MethodGet, path, nil).Header().Get(echo.HeaderLocation)
+	}
+	code, state, err := mock.Authorize(authURL, subject, claims)
+	assert.NoError(t, err)
+	return code, state
+}
+
+func callbackURL(code string, state string) string {
+	return config.APIAccountOIDCCallback + "?code=" + url.QueryEscape(code) + "&state=" + url.QueryEscape(state)
+}
+
+func loginAccount(client *testClient) *model.Account {
+	rec := client.send(http.MethodGet, config.APIAccountLoginAccount, nil)
+	var account *model.Account
+	_ = json.Unmarshal(rec.Body.Bytes(), &account)
+	return account
+}
+
+func assertIdentity(t *testing.T, container container.Container, subject string, accountID uint, provisioned bool) {
+	ai := model.AccountIdentity{}
+	identity, err := ai.FindByIssuerAndSubject(container.GetRepository(), container.GetConfig().OIDC.Issuer,
+		subject).Take()
+	assert.NoError(t, err)
+	assert.Equal(t, accountID, identity.AccountID)
+	assert.Equal(t, provisioned, identity.Provisioned)
+}
+
+// testClient sends the requests to the router with the cookies which the responses have set.
+type testClient struct {
+	router  *echo.Echo
+	cookies map[string]*http.Cookie
+}
+
+func newTestClient(router *echo.Echo) *testClient {
+	return &testClient{router: router, cookies: map[string]*http.Cookie{}}
+}
+
+func (client *testClient) send(method string, target string, body interface{}) *httptest.ResponseRecorder {
+	req := test.NewJSONRequest(method, target, body)
+	for _, cookie := range client.cookies {
+		req.AddCookie(cookie)
+	}
+	if cookie, ok := client.cookies["XSRF-TOKEN"]; ok {
+		req.Header.Set(appMiddleware.HeaderCSRFToken, cookie.Value)
+	}
+	rec := httptest.NewRecorder()
+	client.router.ServeHTTP(rec, req)
+	for _, cookie := range rec.Result().Cookies() {
+		client.cookies[cookie.Name] = cookie
+	}
+	return rec
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// APIKeyController is a controller for managing the personal API keys.
+type APIKeyController interface {
+	GetAPIKeyList(c echo.Context) error
+	CreateAPIKey(c echo.Context) error
+	RevokeAPIKey(c echo.Context) error
+	GetAllAPIKeyList(c echo.Context) error
+	RevokeAnyAPIKey(c echo.Context) error
+}
+
+type apiKeyController struct {
+	container container.Container
+	service   service.APIKeyService
+}
+
+// NewAPIKeyController is constructor.
+func NewAPIKeyController(container container.Container) APIKeyController {
+	return &apiKeyController{container: container, service: service.NewAPIKeyService(container)}
+}
+
+// GetAPIKeyList returns the API keys of logged-in user.
+// @Summary Get the API keys of logged-in user.
+// @Description Get the API keys of logged-in user, including the revoked and expired ones. The keys themselves aren't returned.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.APIKey "Success to fetch the API keys."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /auth/apikeys [get]
+func (controller *apiKeyController) GetAPIKeyList(c echo.Context) error {
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	keys, err := controller.service.FindAPIKeys(account)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey creates a new API key of logged-in user by http post.
+// @Summary Create a new API key of logged-in user.
+// @Description Create a new API key, which is sent by the X-API-Key header. The key is returned only this time, so keep it safely. The scopes must be the permissions which the user has.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.APIKeyDto true "The name, the scopes and the expiration date of the key."
+// @Success 200 {object} model.APIKey "Success to create a new API key."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /auth/apikeys [post]
+func (controller *apiKeyController) CreateAPIKey(c echo.Context) error {
+	apiKeyDto := dto.NewAPIKeyDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(apiKeyDto); err != nil {
+		return err
+	}
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	key, err := controller.service.CreateAPIKey(account, apiKeyDto)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, key)
+}
+
+// RevokeAPIKey revokes the API key of logged-in user by http delete.
+// @Summary Revoke the API key of logged-in user.
+// @Description Revoke the API key of logged-in user. The revoked key remains in the list.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param api_key_id path int true "API key ID"
+// @Success 200 {object} model.APIKey "Success to revoke the API key."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 404 {object} controller.Problem "The API key doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to revoke the API key."
+// @Router /auth/apikeys/{api_key_id} [delete]
+func (controller *apiKeyController) RevokeAPIKey(c echo.Context) error {
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	key, err := controller.service.RevokeAPIKey(account, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, key)
+}
+
+// GetAllAPIKeyList returns the API keys of all accounts.
+// @Summary Get the API keys of all accounts
+// @Description Get the API keys of all accounts, or the ones of the account if its ID is given.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param accountId query int false "Account ID"
+// @Success 200 {array} model.APIKey "Success to fetch the API keys."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/apikeys [get]
+func (controller *apiKeyController) GetAllAPIKeyList(c echo.Context) error {
+	keys, err := controller.service.FindAllAPIKeys(c.QueryParam("accountId"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAnyAPIKey revokes the API key of any account by http delete.
+// @Summary Revoke the API key of any account
+// @Description Revoke the API key of any account. The revoked key remains in the list.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param api_key_id path int true "API key ID"
+// @Success 200 {object} model.APIKey "Success to revoke the API key."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The API key doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to revoke the API key."
+// @Router /admin/apikeys/{api_key_id} [delete]
+func (controller *apiKeyController) RevokeAnyAPIKey(c echo.Context) error {
+	key, err := controller.service.RevokeAnyAPIKey(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, key)
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+	"strconv"
+)
+
+// HeaderAuditLogTruncated is the header which tells that the older records are left out of the CSV by the export limit.
+const HeaderAuditLogTruncated = "X-Audit-Log-Truncated"
+
+// AuditController is a controller for querying the security audit log.
+type AuditController interface {
+	GetAuditLogList(c echo.Context) error
+	GetAuditLogCSV(c echo.Context) error
+	VerifyAuditLog(c echo.Context) error
+}
+
+type auditController struct {
+	container container.Container
+	service   service.AuditService
+}
+
+// NewAuditController is constructor.
+func NewAuditController(container container.Container) AuditController {
+	return &auditController{container: container, service: service.NewAuditService(container)}
+}
+
+// GetAuditLogList returns the records of the audit log matched the filters.
+// @Summary Get the records of the audit log
+// @Description Get the records of the security audit log matched the filters in order of the newest. The times are given in RFC 3339 or as the dates in UTC.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param event query string false "Event such as login, access.forbidden and account.update"
+// @Param outcome query string false "Outcome of success, failure or denied"
+// @Param actor query string false "Name of the actor"
+// @Param target query string false "Target of the event"
+// @Param ip query string false "Remote IP address"
+// @Param from query string false "Inclusive lower bound of the time"
+// @Param until query string false "Exclusive upper bound of the time"
+// @Param page query int false "Page number"
+// @Param size query int false "Item size per page"
+// @Success 200 {object} model.AuditLogPage "Success to fetch the records."
+// @Failure 400 {object} controller.Problem "The time is invalid."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/auditlogs [get]
+func (controller *auditController) GetAuditLogList(c echo.Context) error {
+	filterDto := dto.NewAuditLogFilterDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(filterDto); err != nil {
+		return err
+	}
+	logs, err := controller.service.FindAuditLogs(filterDto, c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, logs)
+}
+
+// GetAuditLogCSV returns the records of the audit log matched the filters as a CSV file.
+// @Summary Download the records of the audit log as CSV
+// @Description Download the newest records of the security audit log matched the filters as CSV in order of the oldest, up to the export limit of the configuration. If the older records are left out, the X-Audit-Log-Truncated header is true. The values given by the clients which start with =, +, - or @ are prefixed with a quote, not to be evaluated as formulas by the spreadsheets.
+// @Tags Accounts
+// @Produce  text/csv
+// @Param event query string false "Event such as login, access.forbidden and account.update"
+// @Param outcome query string false "Outcome of success, failure or denied"
+// @Param actor query string false "Name of the actor"
+// @Param target query string false "Target of the event"
+// @Param ip query string false "Remote IP address"
+// @Param from query string false "Inclusive lower bound of the time"
+// @Param until query string false "Exclusive upper bound of the time"
+// @Success 200 {string} string "The records in CSV."
+// @Header 200 {boolean} X-Audit-Log-Truncated "Whether the older records are left out by the export limit."
+// @Failure 400 {object} controller.Problem "The time is invalid."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/auditlogs.csv [get]
+func (controller *auditController) GetAuditLogCSV(c echo.Context) error {
+	filterDto := dto.NewAuditLogFilterDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(filterDto); err != nil {
+		return err
+	}
+	csv, truncated, err := controller.service.CreateAuditLogCSV(filterDto)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(HeaderAuditLogTruncated, strconv.FormatBool(truncated))
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="auditlogs.csv"`)
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", csv)
+}
+
+// VerifyAuditLog verifies the chain of the audit log.
+// @Summary Verify the chain of the audit log
+// @Description Verify the hash chain of the security audit log from the oldest record, and report the first record which breaks it. Keep the last hash outside to detect the deletion of the latest records.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.AuditLogVerification "The result of the verification."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/auditlogs/verify [get]
+func (controller *auditController) VerifyAuditLog(c echo.Context) error {
+	result, err := controller.service.Verify()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// newAuditLog returns the record of the audit log of the event caused by the request, which has succeeded
+// unless recordAudit is given an error.
+func newAuditLog(c echo.Context, event string) *model.AuditLog {
+	return model.NewAuditLog(event, model.AuditSuccess, c.RealIP(), c.Request().UserAgent())
+}
+
+// recordAudit records the event to the audit log. If err isn't nil, the event has failed,
+// and the reason is appended to the details.
+func recordAudit(audit service.AuditService, log *model.AuditLog, err error) {
+	if err != nil {
+		log.Outcome = model.AuditFailure
+		if log.Details != "" {
+			log.Details += ": "
+		}
+		log.Details += service.AsError(err).Detail
+	}
+	audit.Record(log)
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// AuthorityController is a controller for managing the authorities and their permissions.
+type AuthorityController interface {
+	GetAuthorityList(c echo.Context) error
+	CreateAuthority(c echo.Context) error
+	UpdateAuthorityPermissions(c echo.Context) error
+	GetPermissionList(c echo.Context) error
+}
+
+type authorityController struct {
+	container container.Container
+	service   service.AuthorityService
+}
+
+// NewAuthorityController is constructor.
+func NewAuthorityController(container container.Container) AuthorityController {
+	return &authorityController{container: container, service: service.NewAuthorityService(container)}
+}
+
+// GetAuthorityList returns the list of all authorities.
+// @Summary Get an authority list
+// @Description Get the list of all authorities with their permissions. The administrator has all permissions.
+// @Tags Authorities
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.Authority "Success to fetch an authority list."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/authorities [get]
+func (controller *authorityController) GetAuthorityList(c echo.Context) error {
+	authorities, err := controller.service.FindAllAuthorities()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, authorities)
+}
+
+// CreateAuthority create a new authority by http post.
+// @Summary Create a new authority
+// @Description Create a new authority with its permissions.
+// @Tags Authorities
+// @Accept  json
+// @Produce  json
+// @Param data body dto.AuthorityDto true "a new authority data for creating"
+// @Success 200 {object} model.Authority "Success to create a new authority."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 409 {object} controller.Problem "The name is already used."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /admin/authorities [post]
+func (controller *authorityController) CreateAuthority(c echo.Context) error {
+	authorityDto := dto.NewAuthorityDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(authorityDto); err != nil {
+		return err
+	}
+	authority, err := controller.service.CreateAuthority(authorityDto)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, authority)
+}
+
+// UpdateAuthorityPermissions replaces the permissions of the existing authority by http put.
+// @Summary Update the permissions of the existing authority
+// @Description Replace the permissions of the existing authority. The name in the body is ignored, and the permissions of the administrator can't be changed.
+// @Tags Authorities
+// @Accept  json
+// @Produce  json
+// @Param authority_id path int true "Authority ID"
+// @Param data body dto.AuthorityDto true "the permissions for updating"
+// @Success 200 {object} model.Authority "Success to update the permissions."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the permissions."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The authority doesn't exist."
+// @Failure 409 {object} controller.Problem "The authority is the administrator."
+// @Failure 500 {object} controller.Problem "Failed to the update."
+// @Router /admin/authorities/{authority_id}/permissions [put]
+func (controller *authorityController) UpdateAuthorityPermissions(c echo.Context) error {
+	authorityDto := dto.NewAuthorityDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(authorityDto); err != nil {
+		return err
+	}
+	authority, err := controller.service.UpdatePermissions(authorityDto, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, authority)
+}
+
+// GetPermissionList returns the list of all permissions.
+// @Summary Get a permission list
+// @Description Get the list of all permissions which can be assigned to authorities
+// @Tags Authorities
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.Permission "Success to fetch a permission list."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Router /admin/permissions [get]
+func (controller *authorityController) GetPermissionList(c echo.Context) error {
+	return c.JSON(http.StatusOK, controller.service.FindAllPermissions())
+}
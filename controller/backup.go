@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/backup"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+	"time"
+)
+
+// BackupController is a controller for the logical backup and restore of the whole dataset.
+type BackupController interface {
+	GetBackup(c echo.Context) error
+	Restore(c echo.Context) error
+}
+
+type backupController struct {
+	container container.Container
+	service   service.BackupService
+}
+
+// NewBackupController is constructor.
+func NewBackupController(container container.Container) BackupController {
+	return &backupController{container: container, service: service.NewBackupService(container)}
+}
+
+// GetBackup returns the archive of every table.
+// @Summary Download the backup archive
+// @Description Download every table as the tar.gz archive which has a manifest and NDJSON files
+// @Tags Admin
+// @Produce  application/gzip
+// @Success 200 {file} file "The backup archive."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to create the backup."
+// @Router /admin/backup [get]
+func (controller *backupController) GetBackup(c echo.Context) error {
+	archive, err := controller.service.BackupToBytes()
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("backup-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Blob(http.StatusOK, backup.ContentType, archive)
+}
+
+// Restore replaces the data with the rows of the uploaded archive.
+// @Summary Restore the backup archive
+// @Description Replace the whole data with the tar.gz archive in a transaction, preserving the IDs. The sessions, the refresh tokens and the API keys are deleted as well, so every account including the current one has to log in again.
+// @Tags Admin
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "The backup archive"
+// @Success 200 {object} backup.Manifest "Success to restore the archive."
+// @Failure 400 {object} controller.Problem "The archive is invalid."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to restore the archive."
+// @Router /admin/restore [post]
+func (controller *backupController) Restore(c echo.Context) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return service.NewValidationError("The request has invalid fields",
+			map[string]string{"file": "The archive is required"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	manifest, err := controller.service.Restore(file, true)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, manifest)
+}
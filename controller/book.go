@@ -3,6 +3,7 @@ package controller
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
 	"github.com/lyh-demo/go-webapp-demo/model/dto"
 	"github.com/lyh-demo/go-webapp-demo/service"
 	"net/http"
@@ -35,13 +36,14 @@ func NewBookController(container container.Container) BookController {
 // @Produce  json
 // @Param book_id path int true "Book ID"
 // @Success 200 {object} model.Book "Success to fetch data."
-// @Failure 400 {string} message "Failed to fetch data."
-// @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The book doesn't exist."
 // @Router /books/{book_id} [get]
 func (controller *bookController) GetBook(c echo.Context) error {
 	book, err := controller.service.FindByID(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, book)
 }
@@ -56,13 +58,14 @@ func (controller *bookController) GetBook(c echo.Context) error {
 // @Param page query int false "Page number"
 // @Param size query int false "Item size per page"
 // @Success 200 {object} model.Page "Success to fetch a book list."
-// @Failure 400 {string} message "Failed to fetch data."
-// @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
 // @Router /books [get]
 func (controller *bookController) GetBookList(c echo.Context) error {
 	book, err := controller.service.FindBooksByTitle(c.QueryParam("query"), c.QueryParam("page"), c.QueryParam("size"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return err
 	}
 	return c.JSON(http.StatusOK, book)
 }
@@ -75,17 +78,20 @@ func (controller *bookController) GetBookList(c echo.Context) error {
 // @Produce  json
 // @Param data body dto.BookDto true "a new book data for creating"
 // @Success 200 {object} model.Book "Success to create a new book."
-// @Failure 400 {string} message "Failed to the registration."
-// @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 422 {object} controller.Problem "The category or the format doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
 // @Router /books [post]
 func (controller *bookController) CreateBook(c echo.Context) error {
-	bookDto := dto.NewBookDto(controller.container.GetMessages())
+	bookDto := dto.NewBookDto(controller.container.GetMessages(i18n.GetLocale(c)))
 	if err := c.Bind(bookDto); err != nil {
-		return c.JSON(http.StatusBadRequest, bookDto)
+		return err
 	}
-	book, result := controller.service.CreateBook(bookDto)
-	if result != nil {
-		return c.JSON(http.StatusBadRequest, result)
+	book, err := controller.service.CreateBook(bookDto)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, book)
 }
@@ -99,17 +105,21 @@ func (controller *bookController) CreateBook(c echo.Context) error {
 // @Param book_id path int true "Book ID"
 // @Param data body dto.BookDto true "the book data for updating"
 // @Success 200 {object} model.Book "Success to update the existing book."
-// @Failure 400 {string} message "Failed to the update."
-// @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The book doesn't exist."
+// @Failure 422 {object} controller.Problem "The category or the format doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to the update."
 // @Router /books/{book_id} [put]
 func (controller *bookController) UpdateBook(c echo.Context) error {
-	bookDto := dto.NewBookDto(controller.container.GetMessages())
+	bookDto := dto.NewBookDto(controller.container.GetMessages(i18n.GetLocale(c)))
 	if err := c.Bind(bookDto); err != nil {
-		return c.JSON(http.StatusBadRequest, bookDto)
+		return err
 	}
-	book, result := controller.service.UpdateBook(bookDto, c.Param("id"))
-	if result != nil {
-		return c.JSON(http.StatusBadRequest, result)
+	book, err := controller.service.UpdateBook(bookDto, c.Param("id"))
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, book)
 }
@@ -122,13 +132,15 @@ func (controller *bookController) UpdateBook(c echo.Context) error {
 // @Produce  json
 // @Param book_id path int true "Book ID"
 // @Success 200 {object} model.Book "Success to delete the existing book."
-// @Failure 400 {string} message "Failed to delete."
-// @Failure 401 {boolean} bool "Failed to the authentication. Returns false."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The book doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to delete."
 // @Router /books/{book_id} [delete]
 func (controller *bookController) DeleteBook(c echo.Context) error {
-	book, result := controller.service.DeleteBook(c.Param("id"))
-	if result != nil {
-		return c.JSON(http.StatusBadRequest, result)
+	book, err := controller.service.DeleteBook(c.Param("id"))
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, book)
 }
@@ -29,7 +29,8 @@ func NewCategoryController(container container.Container) CategoryController {
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} model.Category "Success to fetch a category list."
-// @Failure 401 {string} false "Failed to the authentication."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
 // @Router /categories [get]
 func (controller *categoryController) GetCategoryList(c echo.Context) error {
 	return c.JSON(http.StatusOK, controller.service.FindAllCategories())
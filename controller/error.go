@@ -2,15 +2,59 @@ package controller
 
 import (
 	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/service"
 	"net/http"
+	"strconv"
+	"time"
 )
 
-// APIError has an error code and a message.
-type APIError struct {
-	Code    int
-	Message string
+const (
+	// ProblemContentType is the media type of the error responses in RFC 7807.
+	ProblemContentType = "application/problem+json"
+	// problemTypePrefix is the prefix of the URI which identifies the type of the problem.
+	problemTypePrefix = "urn:problem-type:"
+	// problemTypeBlank is the type of the problem which has no additional semantics beyond the status code.
+	problemTypeBlank = "about:blank"
+)
+
+// errorStatuses are the status codes for the kinds of the errors of services.
+var errorStatuses = map[service.ErrorKind]int{
+	service.KindValidation:       http.StatusBadRequest,
+	service.KindInvalidReference: http.StatusUnprocessableEntity,
+	service.KindNotFound:         http.StatusNotFound,
+	service.KindConflict:         http.StatusConflict,
+	service.KindUnauthorized:     http.StatusUnauthorized,
+	service.KindForbidden:        http.StatusForbidden,
+	service.KindTooManyRequests:  http.StatusTooManyRequests,
+	service.KindInternal:         http.StatusInternalServerError,
+}
+
+// errorMessageKeys are the keys of the messages for the status codes of errors.
+var errorMessageKeys = map[int]string{
+	http.StatusBadRequest:          "ErrMessageBadRequest",
+	http.StatusUnauthorized:        "ErrMessageUnauthorized",
+	http.StatusForbidden:           "ErrMessageForbidden",
+	http.StatusNotFound:            "ErrMessageNotFound",
+	http.StatusMethodNotAllowed:    "ErrMessageMethodNotAllowed",
+	http.StatusConflict:            "ErrMessageConflict",
+	http.StatusUnprocessableEntity: "ErrMessageUnprocessableEntity",
+	http.StatusTooManyRequests:     "ErrMessageTooManyRequests",
+	http.StatusInternalServerError: "ErrMessageInternalServerError",
+}
+
+// Problem represents the details of an error response in RFC 7807.
+type Problem struct {
+	Type      string            `json:"type" example:"urn:problem-type:validation"`
+	Title     string            `json:"title" example:"Bad Request"`
+	Status    int               `json:"status" example:"400"`
+	Detail    string            `json:"detail,omitempty" example:"The request has invalid fields"`
+	Instance  string            `json:"instance,omitempty" example:"/api/books"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	RequestID string            `json:"requestId,omitempty" example:"3Y8sYkZ1nQ0pWJcFzWn1lq2N8XxT0b7e"`
 }
 
 // ErrorController is a controller for handling errors.
@@ -27,26 +71,62 @@ func NewErrorController(container container.Container) ErrorController {
 	return &errorController{container: container}
 }
 
-// JSONError is customize error handler
+// JSONError is customize error handler, which renders all errors as application/problem+json.
 func (controller *errorController) JSONError(err error, c echo.Context) {
 	logger := controller.container.GetLogger()
-	code := http.StatusInternalServerError
-	msg := http.StatusText(code)
+	problem := controller.newProblem(err, c)
+
+	if !c.Response().Committed {
+		c.Response().Header().Set(echo.HeaderContentType, ProblemContentType)
+		var resErr error
+		if c.Request().Method == http.MethodHead {
+			resErr = c.NoContent(problem.Status)
+		} else {
+			resErr = c.JSON(problem.Status, problem)
+		}
+		if resErr != nil {
+			logger.GetZapLogger().Errorf(resErr.Error())
+		}
+	}
+	logger.GetZapLogger().Debugf(err.Error())
+}
+
+// newProblem converts the error returned by the handlers to the problem. The errors of echo such as
+// 404 of unknown paths have the blank type, and the others are regarded as the errors of services.
+// The Retry-After header is set if the error of services tells how long to wait.
+func (controller *errorController) newProblem(err error, c echo.Context) *Problem {
+	problem := &Problem{
+		Instance:  c.Request().URL.Path,
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
 
 	var he *echo.HTTPError
 	if errors.As(err, &he) {
-		code = he.Code
-		msg = he.Message.(string)
+		problem.Type = problemTypeBlank
+		problem.Status = he.Code
+		if msg := fmt.Sprint(he.Message); msg != http.StatusText(he.Code) {
+			problem.Detail = msg
+		}
+	} else {
+		e := service.AsError(err)
+		problem.Type = problemTypePrefix + string(e.Kind)
+		problem.Status = http.StatusInternalServerError
+		if status, ok := errorStatuses[e.Kind]; ok {
+			problem.Status = status
+		}
+		problem.Detail = e.Detail
+		problem.Errors = e.Fields
+		if e.RetryAfter > 0 {
+			seconds := int64((e.RetryAfter + time.Second - 1) / time.Second)
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.FormatInt(seconds, 10))
+		}
 	}
 
-	var apiErr APIError
-	apiErr.Code = code
-	apiErr.Message = msg
-
-	if !c.Response().Committed {
-		if resErr := c.JSON(code, apiErr); resErr != nil {
-			logger.GetZapLogger().Errorf(resErr.Error())
+	problem.Title = http.StatusText(problem.Status)
+	if key, ok := errorMessageKeys[problem.Status]; ok {
+		if localized, ok := controller.container.GetMessages(i18n.GetLocale(c))[key]; ok {
+			problem.Title = localized
 		}
 	}
-	logger.GetZapLogger().Debugf(err.Error())
+	return problem
 }
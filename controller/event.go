@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/broker"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+	"time"
+)
+
+const (
+	// lastEventIDHeader is the header which EventSource sends when reconnecting.
+	lastEventIDHeader = "Last-Event-ID"
+	// lastEventIDParam is the query parameter used instead of the header by WebSocket clients.
+	lastEventIDParam = "lastEventId"
+	// resetEvent is the event which tells the client to reload the data as some events can't be resumed.
+	resetEvent = "reset"
+	// sseRetry is the reconnection time in milliseconds which is sent to EventSource.
+	sseRetry = 3000
+	// wsWriteWait is the time allowed to write a message to WebSocket.
+	wsWriteWait = 10 * time.Second
+)
+
+// EventController is a controller for pushing the live events of data changes.
+type EventController interface {
+	StreamEvents(c echo.Context) error
+	StreamEventsOverWebSocket(c echo.Context) error
+}
+
+type eventController struct {
+	container container.Container
+	service   service.EventService
+	upgrader  websocket.Upgrader
+}
+
+// NewEventController is constructor.
+// The WebSocket upgrader keeps the default origin check, as the connections are authenticated by the session cookie.
+func NewEventController(container container.Container) EventController {
+	return &eventController{container: container, service: service.NewEventService(container)}
+}
+
+// StreamEvents pushes the events of data changes as Server-Sent Events.
+// @Summary Stream the events of data changes
+// @Description Stream the events of data changes as Server-Sent Events.
+// @Description The event ID can be sent by Last-Event-ID header to resume, and "reset" event is sent if it can't be resumed.
+// @Tags Events
+// @Produce  text/event-stream
+// @Param types query string false "Comma separated entity types to subscribe (book)"
+// @Param Last-Event-ID header string false "The last event ID received"
+// @Success 200 {string} string "The stream of events."
+// @Failure 400 {object} controller.Problem "Failed to subscribe."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Router /events [get]
+func (controller *eventController) StreamEvents(c echo.Context) error {
+	lastEventID := c.Request().Header.Get(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = c.QueryParam(lastEventIDParam)
+	}
+	subscription, err := controller.service.Subscribe(c.QueryParam("types"), lastEventID)
+	if err != nil {
+		return err
+	}
+	defer controller.service.Unsubscribe(subscription)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", sseRetry); err != nil {
+		return nil
+	}
+	if subscription.Missed {
+		if _, err := fmt.Fprintf(res, "event: %s\ndata: {}\n\n", resetEvent); err != nil {
+			return nil
+		}
+	}
+	for _, e := range subscription.Replay {
+		if err := writeServerSentEvent(res, e); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(controller.service.Heartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case e, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+			if err := writeServerSentEvent(res, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// StreamEventsOverWebSocket pushes the events of data changes as the JSON messages of WebSocket.
+// @Summary Stream the events of data changes over WebSocket
+// @Description Stream the events of data changes as the JSON messages of WebSocket.
+// @Description The event ID can be sent by lastEventId parameter to resume, and "reset" message is sent if it can't be resumed.
+// @Tags Events
+// @Param types query string false "Comma separated entity types to subscribe (book)"
+// @Param lastEventId query int false "The last event ID received"
+// @Success 101 {object} broker.Event "Switching to WebSocket."
+// @Failure 400 {object} controller.Problem "Failed to subscribe."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Router /events/ws [get]
+func (controller *eventController) StreamEventsOverWebSocket(c echo.Context) error {
+	subscription, err := controller.service.Subscribe(c.QueryParam("types"), c.QueryParam(lastEventIDParam))
+	if err != nil {
+		return err
+	}
+	defer controller.service.Unsubscribe(subscription)
+
+	conn, err := controller.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	heartbeatInterval := controller.service.Heartbeat()
+	closed := readUntilClosed(conn, heartbeatInterval)
+
+	if subscription.Missed {
+		if err := writeWebSocketMessage(conn, &broker.Event{Type: resetEvent}); err != nil {
+			return nil
+		}
+	}
+	for _, e := range subscription.Replay {
+		if err := writeWebSocketMessage(conn, e); err != nil {
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-closed:
+			return nil
+		case e, ok := <-subscription.Events():
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind"), time.Now().Add(wsWriteWait))
+				return nil
+			}
+			if err := writeWebSocketMessage(conn, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// readUntilClosed reads the connection to process the control messages, and returns the channel closed
+// when the client closed the connection or didn't answer the heartbeats.
+func readUntilClosed(conn *websocket.Conn, heartbeat time.Duration) <-chan struct{} {
+	closed := make(chan struct{})
+	_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return closed
+}
+
+func writeServerSentEvent(res *echo.Response, e *broker.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+func writeWebSocketMessage(conn *websocket.Conn, e *broker.Event) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(e)
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/feed"
+	"github.com/lyh-demo/go-webapp-demo/service"
+)
+
+// FeedController is a controller for serving the syndication feeds of newly added books.
+type FeedController interface {
+	GetNewArrivalsAtom(c echo.Context) error
+	GetNewArrivalsRSS(c echo.Context) error
+}
+
+type feedController struct {
+	container container.Container
+	service   service.FeedService
+}
+
+// NewFeedController is constructor.
+func NewFeedController(container container.Container) FeedController {
+	return &feedController{container: container, service: service.NewFeedService(container)}
+}
+
+// GetNewArrivalsAtom returns the Atom feed of newly added books.
+// The books can be filtered by the category and format query parameters.
+func (controller *feedController) GetNewArrivalsAtom(c echo.Context) error {
+	f, err := controller.service.CreateNewArrivalsAtom(baseURL(c), c.QueryParam("category"), c.QueryParam("format"))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.AtomType, f)
+}
+
+// GetNewArrivalsRSS returns the RSS feed of newly added books.
+// The books can be filtered by the category and format query parameters.
+func (controller *feedController) GetNewArrivalsRSS(c echo.Context) error {
+	f, err := controller.service.CreateNewArrivalsRSS(baseURL(c), c.QueryParam("category"), c.QueryParam("format"))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.RSSType, f)
+}
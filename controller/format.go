@@ -29,7 +29,8 @@ func NewFormatController(container container.Container) FormatController {
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} model.Format "Success to fetch a format list."
-// @Failure 401 {string} false "Failed to the authentication."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
 // @Router /formats [get]
 func (controller *formatController) GetFormatList(c echo.Context) error {
 	return c.JSON(http.StatusOK, controller.service.FindAllFormats())
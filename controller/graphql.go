@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/graph"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// GraphQLController is a controller for executing GraphQL queries and mutations.
+type GraphQLController interface {
+	Query(c echo.Context) error
+}
+
+type graphqlController struct {
+	container container.Container
+	schema    graph.Schema
+}
+
+// NewGraphQLController is constructor.
+func NewGraphQLController(container container.Container) GraphQLController {
+	schema, err := graph.NewSchema(container)
+	if err != nil {
+		container.GetLogger().GetZapLogger().Panicf("Failed to build the GraphQL schema: %s", err.Error())
+	}
+	return &graphqlController{container: container, schema: schema}
+}
+
+// Query executes a GraphQL request sent by http get or http post. The mutations are accepted only by http post,
+// because http get isn't protected against the cross-site request forgery.
+// @Summary Execute a GraphQL request
+// @Description Execute a GraphQL query or mutation over books, categories, formats and the logged-in account. The mutations have to be sent by http post.
+// @Tags GraphQL
+// @Accept  json
+// @Produce  json
+// @Param data body dto.GraphQLDto true "GraphQL query, variables and operation name"
+// @Success 200 {object} map[string]interface{} "The result of the request which has data and errors."
+// @Failure 400 {object} controller.Problem "Failed to parse the request."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 405 {object} controller.Problem "The mutation is sent by http get."
+// @Router /graphql [post]
+func (controller *graphqlController) Query(c echo.Context) error {
+	request := dto.NewGraphQLDto()
+	if c.Request().Method == http.MethodGet {
+		request.Query = c.QueryParam("query")
+		request.OperationName = c.QueryParam("operationName")
+		if variables := c.QueryParam("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return service.NewValidationError("The request has invalid fields",
+					map[string]string{"variables": err.Error()})
+			}
+		}
+		if op := controller.schema.OperationType(request); op != "" && op != ast.OperationTypeQuery {
+			c.Response().Header().Set(echo.HeaderAllow, http.MethodPost)
+			return echo.NewHTTPError(http.StatusMethodNotAllowed, "The "+op+" has to be sent by http post")
+		}
+	} else if err := c.Bind(request); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, controller.schema.Execute(c, request))
+}
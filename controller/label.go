@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// LabelController is a controller for printing the labels of books.
+type LabelController interface {
+	GetLabel(c echo.Context) error
+	GetLabelSheet(c echo.Context) error
+}
+
+type labelController struct {
+	container container.Container
+	service   service.LabelService
+}
+
+// NewLabelController is constructor.
+func NewLabelController(container container.Container) LabelController {
+	return &labelController{container: container, service: service.NewLabelService(container)}
+}
+
+// GetLabel returns the label of the given book.
+// @Summary Print the label of a book
+// @Description Print the label which has the title, the barcode of ISBN and the QR code linking to the book page
+// @Tags Labels
+// @Produce  image/svg+xml,image/png
+// @Param book_id path int true "Book ID"
+// @Param format query string false "svg (default) or png"
+// @Param symbology query string false "code128 or ean13. The default is ean13 for ISBN-13, otherwise code128."
+// @Success 200 {file} file "The label of the book."
+// @Failure 400 {object} controller.Problem "The parameters are invalid."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The book doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to print the label."
+// @Router /books/{book_id}/label [get]
+func (controller *labelController) GetLabel(c echo.Context) error {
+	result, contentType, err := controller.service.CreateLabel(baseURL(c), c.Param("id"),
+		c.QueryParam("format"), c.QueryParam("symbology"))
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, contentType, result)
+}
+
+// GetLabelSheet returns the labels of the given books laid out on A4 sheets.
+// @Summary Print the labels of books on A4 sheets
+// @Description Print the labels of books on A4 sheets of 3 x 8 labels. SVG has a sheet, and PDF has as many pages as needed.
+// @Tags Labels
+// @Produce  application/pdf,image/svg+xml
+// @Param ids query string true "Comma separated book IDs"
+// @Param format query string false "pdf (default) or svg"
+// @Param symbology query string false "code128 or ean13. The default is ean13 for ISBN-13, otherwise code128."
+// @Param skip query int false "The number of used positions on the first sheet"
+// @Success 200 {file} file "The sheets of labels."
+// @Failure 400 {object} controller.Problem "The parameters are invalid."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The book doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to print the labels."
+// @Router /books/labels [get]
+func (controller *labelController) GetLabelSheet(c echo.Context) error {
+	result, contentType, err := controller.service.CreateSheet(baseURL(c), c.QueryParam("ids"),
+		c.QueryParam("format"), c.QueryParam("symbology"), c.QueryParam("skip"))
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, contentType, result)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+	"strings"
+)
+
+// LockoutController is a controller for managing the lockouts of the login.
+type LockoutController interface {
+	GetLockoutList(c echo.Context) error
+	Unlock(c echo.Context) error
+}
+
+type lockoutController struct {
+	container container.Container
+	service   service.LockoutService
+	audit     service.AuditService
+}
+
+// NewLockoutController is constructor.
+func NewLockoutController(container container.Container) LockoutController {
+	return &lockoutController{container: container, service: service.NewLockoutService(container),
+		audit: service.NewAuditService(container)}
+}
+
+// GetLockoutList returns the usernames and the remote IP addresses which are locked out.
+// @Summary Get the lockouts of the login
+// @Description Get the usernames and the remote IP addresses which are locked out now after too many failed logins.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.LoginAttempt "Success to fetch the lockouts."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/lockouts [get]
+func (controller *lockoutController) GetLockoutList(c echo.Context) error {
+	lockouts, err := controller.service.FindLockouts()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, lockouts)
+}
+
+// Unlock releases the lockout of the username or the remote IP address by http post.
+// @Summary Release the lockout of the login
+// @Description Release the lockout of the username or the remote IP address, and forget their failed logins.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param data body dto.UnlockDto true "The username or the remote IP address."
+// @Success 200
+// @Failure 400 {object} controller.Problem "Neither the username nor the remote IP address is given."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /admin/lockouts/unlock [post]
+func (controller *lockoutController) Unlock(c echo.Context) error {
+	unlockDto := dto.NewUnlockDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(unlockDto); err != nil {
+		return err
+	}
+	err := controller.service.Unlock(unlockDto)
+	recordAudit(controller.audit, newAuditLog(c, model.AuditLockoutUnlock).
+		WithActor(controller.container.GetSession().GetAccount(c)).
+		WithTarget(strings.TrimSpace(unlockDto.UserName+" "+unlockDto.RemoteIP)), err)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
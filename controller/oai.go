@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/oai"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// OaiController is a controller for providing the metadata of books to harvesters over OAI-PMH.
+type OaiController interface {
+	HandleRequest(c echo.Context) error
+}
+
+type oaiController struct {
+	container container.Container
+	service   service.OaiService
+}
+
+// NewOaiController is constructor.
+func NewOaiController(container container.Container) OaiController {
+	return &oaiController{container: container, service: service.NewOaiService(container)}
+}
+
+// HandleRequest processes the OAI-PMH request sent by http get or http post.
+func (controller *oaiController) HandleRequest(c echo.Context) error {
+	if err := c.Request().ParseForm(); err != nil {
+		return service.NewValidationError(err.Error(), nil)
+	}
+
+	res, err := controller.service.HandleRequest(baseURL(c), c.Request().Form)
+	if err != nil {
+		return err
+	}
+	bytes, err := oai.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, oai.ContentType, bytes)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/feed"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// OpdsController is a controller for serving the OPDS catalog to e-reader applications.
+type OpdsController interface {
+	GetRootFeed(c echo.Context) error
+	GetCategoryFeed(c echo.Context) error
+	GetCategoryBooksFeed(c echo.Context) error
+	GetFormatFeed(c echo.Context) error
+	GetFormatBooksFeed(c echo.Context) error
+	GetAllBooksFeed(c echo.Context) error
+	GetSearchFeed(c echo.Context) error
+	GetOpenSearchDescription(c echo.Context) error
+}
+
+type opdsController struct {
+	container container.Container
+	service   service.OpdsService
+}
+
+// NewOpdsController is constructor.
+func NewOpdsController(container container.Container) OpdsController {
+	return &opdsController{container: container, service: service.NewOpdsService(container)}
+}
+
+// GetRootFeed returns the navigation feed of the catalog root.
+func (controller *opdsController) GetRootFeed(c echo.Context) error {
+	return writeXML(c, feed.NavigationType, controller.service.CreateRootFeed(baseURL(c)))
+}
+
+// GetCategoryFeed returns the navigation feed of categories.
+func (controller *opdsController) GetCategoryFeed(c echo.Context) error {
+	f, err := controller.service.CreateCategoryNavigationFeed(baseURL(c))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.NavigationType, f)
+}
+
+// GetCategoryBooksFeed returns the acquisition feed of books belonging to the category.
+func (controller *opdsController) GetCategoryBooksFeed(c echo.Context) error {
+	f, err := controller.service.CreateCategoryBooksFeed(baseURL(c), c.Param("id"), c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.AcquisitionType, f)
+}
+
+// GetFormatFeed returns the navigation feed of formats.
+func (controller *opdsController) GetFormatFeed(c echo.Context) error {
+	f, err := controller.service.CreateFormatNavigationFeed(baseURL(c))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.NavigationType, f)
+}
+
+// GetFormatBooksFeed returns the acquisition feed of books published in the format.
+func (controller *opdsController) GetFormatBooksFeed(c echo.Context) error {
+	f, err := controller.service.CreateFormatBooksFeed(baseURL(c), c.Param("id"), c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.AcquisitionType, f)
+}
+
+// GetAllBooksFeed returns the acquisition feed of all books.
+func (controller *opdsController) GetAllBooksFeed(c echo.Context) error {
+	f, err := controller.service.CreateAllBooksFeed(baseURL(c), c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.AcquisitionType, f)
+}
+
+// GetSearchFeed returns the acquisition feed of books matched the keyword.
+func (controller *opdsController) GetSearchFeed(c echo.Context) error {
+	f, err := controller.service.CreateSearchFeed(baseURL(c), c.QueryParam("q"), c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return writeXML(c, feed.AcquisitionType, f)
+}
+
+// GetOpenSearchDescription returns the OpenSearch description document of the catalog.
+func (controller *opdsController) GetOpenSearchDescription(c echo.Context) error {
+	return writeXML(c, feed.OpenSearchType, controller.service.CreateOpenSearchDescription(baseURL(c)))
+}
+
+// baseURL returns the scheme and the host of the current request.
+func baseURL(c echo.Context) string {
+	return c.Scheme() + "://" + c.Request().Host
+}
+
+// writeXML writes the given element as an XML document with the given content type.
+func writeXML(c echo.Context, contentType string, v interface{}) error {
+	bytes, err := feed.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, contentType+"; charset=utf-8", bytes)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// ReportController is a controller for reporting the statistics of the catalog.
+type ReportController interface {
+	GetSummary(c echo.Context) error
+	GetSummaryCSV(c echo.Context) error
+}
+
+type reportController struct {
+	container container.Container
+	service   service.ReportService
+}
+
+// NewReportController is constructor.
+func NewReportController(container container.Container) ReportController {
+	return &reportController{container: container, service: service.NewReportService(container)}
+}
+
+// GetSummary returns the number of books grouped by category, format and month.
+// @Summary Get the summary report
+// @Description Get the number of books grouped by category, format and the month of registration
+// @Tags Reports
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.Summary "Success to aggregate the catalog."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /reports/summary [get]
+func (controller *reportController) GetSummary(c echo.Context) error {
+	summary, err := controller.service.CreateSummary()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, summary)
+}
+
+// GetSummaryCSV returns the summary report as a CSV file.
+// @Summary Download the summary report as CSV
+// @Description Download the summary report as CSV which has the columns of section, id, name and count
+// @Tags Reports
+// @Produce  text/csv
+// @Success 200 {string} string "The summary report in CSV."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /reports/summary.csv [get]
+func (controller *reportController) GetSummaryCSV(c echo.Context) error {
+	csv, err := controller.service.CreateSummaryCSV()
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="summary.csv"`)
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", csv)
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// SessionController is a controller for managing the login sessions.
+type SessionController interface {
+	GetSessionList(c echo.Context) error
+	RevokeSession(c echo.Context) error
+	RevokeOtherSessions(c echo.Context) error
+	GetAccountSessionList(c echo.Context) error
+	ForceLogout(c echo.Context) error
+}
+
+type sessionController struct {
+	container container.Container
+	service   service.SessionService
+	audit     service.AuditService
+}
+
+// NewSessionController is constructor.
+func NewSessionController(container container.Container) SessionController {
+	return &sessionController{container: container, service: service.NewSessionService(container),
+		audit: service.NewAuditService(container)}
+}
+
+// GetSessionList returns the sessions of logged-in user.
+// @Summary Get the sessions of logged-in user
+// @Description Get the login sessions of logged-in user with the creation time, the last activity, the IP address and the user agent. The session of the current request is marked as current.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.AccountSession "Success to fetch the sessions."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /auth/sessions [get]
+func (controller *sessionController) GetSessionList(c echo.Context) error {
+	sess := controller.container.GetSession()
+	account := sess.GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	sessions, err := controller.service.FindSessions(account, sess.GetSessionToken(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession revokes the session of logged-in user by http delete.
+// @Summary Revoke the session of logged-in user.
+// @Description Revoke the session of logged-in user, which is logged out immediately. If it is the current session, the current user is logged out.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param session_id path int true "Session ID"
+// @Success 200
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 404 {object} controller.Problem "The session doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /auth/sessions/{session_id} [delete]
+func (controller *sessionController) RevokeSession(c echo.Context) error {
+	sess := controller.container.GetSession()
+	account := sess.GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	current, err := controller.service.Revoke(account, c.Param("id"), sess.GetSessionToken(c))
+	if err != nil {
+		return err
+	}
+	if current {
+		_ = sess.SetAccount(c, nil)
+		_ = sess.Delete(c)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// RevokeOtherSessions revokes the sessions of logged-in user except the current one by http delete.
+// @Summary Revoke the other sessions of logged-in user.
+// @Description Revoke all sessions of logged-in user except the current one, which are logged out immediately.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /auth/sessions [delete]
+func (controller *sessionController) RevokeOtherSessions(c echo.Context) error {
+	sess := controller.container.GetSession()
+	account := sess.GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	if err := controller.service.RevokeOthers(account, sess.GetSessionToken(c)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// GetAccountSessionList returns the sessions of the account.
+// @Summary Get the sessions of the account
+// @Description Get the login sessions of the account with the creation time, the last activity, the IP address and the user agent.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param account_id path int true "Account ID"
+// @Success 200 {array} model.AccountSession "Success to fetch the sessions."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The account doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /admin/accounts/{account_id}/sessions [get]
+func (controller *sessionController) GetAccountSessionList(c echo.Context) error {
+	sessions, err := controller.service.FindAccountSessions(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// ForceLogout forces the account to logout by http delete.
+// @Summary Force the account to logout
+// @Description Revoke all sessions and refresh tokens of the account, which is logged out immediately. The access tokens already issued are no longer valid as well.
+// @Tags Accounts
+// @Accept  json
+// @Produce  json
+// @Param account_id path int true "Account ID"
+// @Success 200
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The account doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /admin/accounts/{account_id}/sessions [delete]
+func (controller *sessionController) ForceLogout(c echo.Context) error {
+	err := controller.service.ForceLogout(c.Param("id"))
+	recordAudit(controller.audit, newAuditLog(c, model.AuditAccountLogout).
+		WithActor(controller.container.GetSession().GetAccount(c)).WithTarget(c.Param("id")), err)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+const (
+	noStore    = "no-store"
+	jwksMaxAge = "public, max-age=300"
+)
+
+// TokenController is a controller for the access tokens of the bearer authentication.
+type TokenController interface {
+	IssueToken(c echo.Context) error
+	RefreshToken(c echo.Context) error
+	RevokeToken(c echo.Context) error
+	GetJWKS(c echo.Context) error
+}
+
+type tokenController struct {
+	container container.Container
+	service   service.TokenService
+	audit     service.AuditService
+}
+
+// NewTokenController is constructor.
+func NewTokenController(container container.Container) TokenController {
+	return &tokenController{container: container, service: service.NewTokenService(container),
+		audit: service.NewAuditService(container)}
+}
+
+// IssueToken issues the access token and the refresh token by using username and password.
+// @Summary Issue the access token.
+// @Description Issue the short-lived access token for the Authorization: Bearer header, and the refresh token to issue a new one.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.LoginDto true "Username and Password."
+// @Success 200 {object} model.TokenResponse "Success to the authentication."
+// @Failure 400 {object} controller.Problem "Failed to parse the request."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 429 {object} controller.Problem "Too many failed logins of the username or from the remote IP address."
+// @Failure 500 {object} controller.Problem "Failed to issue the token."
+// @Router /auth/token [post]
+func (controller *tokenController) IssueToken(c echo.Context) error {
+	loginDto := dto.NewLoginDto()
+	if err := c.Bind(loginDto); err != nil {
+		return err
+	}
+	result, err := controller.service.IssueToken(loginDto, c.RealIP())
+	recordAudit(controller.audit, newAuditLog(c, model.AuditLogin).WithActorName(loginDto.UserName).
+		WithDetails("token"), err)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, result)
+}
+
+// RefreshToken issues a new access token and refresh token by using the refresh token.
+// @Summary Refresh the access token.
+// @Description Issue a new access token and refresh token. The given refresh token is revoked, and using it again revokes all refresh tokens of the account.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.RefreshTokenDto true "The refresh token."
+// @Success 200 {object} model.TokenResponse "Success to refresh the token."
+// @Failure 400 {object} controller.Problem "Failed to parse the request."
+// @Failure 401 {object} controller.Problem "The refresh token is invalid, revoked or has expired."
+// @Failure 500 {object} controller.Problem "Failed to issue the token."
+// @Router /auth/token/refresh [post]
+func (controller *tokenController) RefreshToken(c echo.Context) error {
+	refreshDto := dto.NewRefreshTokenDto()
+	if err := c.Bind(refreshDto); err != nil {
+		return err
+	}
+	result, err := controller.service.RefreshToken(refreshDto)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, result)
+}
+
+// RevokeToken revokes the refresh token.
+// @Summary Revoke the refresh token.
+// @Description Revoke the refresh token. It succeeds even if the token is unknown or has already been revoked.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.RefreshTokenDto true "The refresh token."
+// @Success 200
+// @Failure 400 {object} controller.Problem "Failed to parse the request."
+// @Failure 500 {object} controller.Problem "Failed to revoke the token."
+// @Router /auth/token/revoke [post]
+func (controller *tokenController) RevokeToken(c echo.Context) error {
+	refreshDto := dto.NewRefreshTokenDto()
+	if err := c.Bind(refreshDto); err != nil {
+		return err
+	}
+	if err := controller.service.RevokeToken(refreshDto); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// GetJWKS returns the public keys to verify the access tokens as the JSON web key set.
+func (controller *tokenController) GetJWKS(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, jwksMaxAge)
+	return c.JSON(http.StatusOK, controller.service.FindKeys())
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// TwoFactorController is a controller for managing the two-factor authentication of logged-in user.
+type TwoFactorController interface {
+	GetTwoFactorStatus(c echo.Context) error
+	SetupTwoFactor(c echo.Context) error
+	ConfirmTwoFactor(c echo.Context) error
+	RegenerateRecoveryCodes(c echo.Context) error
+	DisableTwoFactor(c echo.Context) error
+}
+
+type twoFactorController struct {
+	container container.Container
+	service   service.TwoFactorService
+}
+
+// NewTwoFactorController is constructor.
+func NewTwoFactorController(container container.Container) TwoFactorController {
+	return &twoFactorController{container: container, service: service.NewTwoFactorService(container)}
+}
+
+// GetTwoFactorStatus returns the status of the two-factor authentication of logged-in user.
+// @Summary Get the status of the two-factor authentication.
+// @Description Get whether the two-factor authentication of logged-in user is enabled or required, and the number of the unused recovery codes.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.TwoFactorStatus "Success to fetch the status."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /auth/2fa [get]
+func (controller *twoFactorController) GetTwoFactorStatus(c echo.Context) error {
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	status, err := controller.service.FindStatus(account)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// SetupTwoFactor creates a new TOTP secret of logged-in user by http post.
+// @Summary Set up the two-factor authentication.
+// @Description Create a new TOTP secret, which is enabled after a code of it is confirmed. Register the provisioning URI or the QR code to the authenticator app.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.TwoFactorEnrollment "Success to create a new secret."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 409 {object} controller.Problem "The two-factor authentication is already enabled."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /auth/2fa/setup [post]
+func (controller *twoFactorController) SetupTwoFactor(c echo.Context) error {
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	enrollment, err := controller.service.Setup(account)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactor enables the TOTP secret of logged-in user by http post.
+// @Summary Enable the two-factor authentication.
+// @Description Enable the TOTP secret by confirming a code of it. The recovery codes are returned only this time, so keep them safely.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.TwoFactorCodeDto true "The TOTP code of the new secret."
+// @Success 200 {object} model.RecoveryCodes "Success to enable the two-factor authentication."
+// @Failure 400 {object} controller.Problem "The code is incorrect."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 409 {object} controller.Problem "The secret hasn't been set up, or is already enabled."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /auth/2fa/confirm [post]
+func (controller *twoFactorController) ConfirmTwoFactor(c echo.Context) error {
+	codeDto := dto.NewTwoFactorCodeDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(codeDto); err != nil {
+		return err
+	}
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	codes, err := controller.service.Confirm(account, codeDto)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, codes)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of logged-in user by http post.
+// @Summary Regenerate the recovery codes.
+// @Description Replace the recovery codes after checking the TOTP code or a recovery code. The new codes are returned only this time, so keep them safely.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.TwoFactorCodeDto true "The TOTP code or a recovery code."
+// @Success 200 {object} model.RecoveryCodes "Success to regenerate the recovery codes."
+// @Failure 400 {object} controller.Problem "The code is incorrect."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 409 {object} controller.Problem "The two-factor authentication isn't enabled."
+// @Failure 500 {object} controller.Problem "Failed to the update."
+// @Router /auth/2fa/recoveryCodes [post]
+func (controller *twoFactorController) RegenerateRecoveryCodes(c echo.Context) error {
+	codeDto := dto.NewTwoFactorCodeDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(codeDto); err != nil {
+		return err
+	}
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	codes, err := controller.service.RegenerateRecoveryCodes(account, codeDto)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, noStore)
+	return c.JSON(http.StatusOK, codes)
+}
+
+// DisableTwoFactor disables the two-factor authentication of logged-in user by http post.
+// @Summary Disable the two-factor authentication.
+// @Description Delete the TOTP secret and the recovery codes after checking the password and the code. The administrators can't disable it if it is required for them.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param data body dto.TwoFactorDisableDto true "The current password and the TOTP code or a recovery code."
+// @Success 200
+// @Failure 400 {object} controller.Problem "The password or the code is incorrect."
+// @Failure 401 {object} controller.Problem "The current user haven't logged-in yet."
+// @Failure 403 {object} controller.Problem "The account isn't logged in by the session."
+// @Failure 409 {object} controller.Problem "The two-factor authentication is required."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /auth/2fa/disable [post]
+func (controller *twoFactorController) DisableTwoFactor(c echo.Context) error {
+	disableDto := dto.NewTwoFactorDisableDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(disableDto); err != nil {
+		return err
+	}
+	account := controller.container.GetSession().GetAccount(c)
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	if err := controller.service.Disable(account, disableDto); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
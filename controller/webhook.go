@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"net/http"
+)
+
+// WebhookController is a controller for managing webhook subscriptions.
+type WebhookController interface {
+	GetWebhook(c echo.Context) error
+	GetWebhookList(c echo.Context) error
+	CreateWebhook(c echo.Context) error
+	UpdateWebhook(c echo.Context) error
+	DeleteWebhook(c echo.Context) error
+	GetDeliveryList(c echo.Context) error
+	Redeliver(c echo.Context) error
+}
+
+type webhookController struct {
+	container container.Container
+	service   service.WebhookService
+}
+
+// NewWebhookController is constructor.
+func NewWebhookController(container container.Container) WebhookController {
+	return &webhookController{container: container, service: service.NewWebhookService(container)}
+}
+
+// GetWebhook returns one record matched webhook's id.
+// @Summary Get a webhook
+// @Description Get a webhook subscription
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param webhook_id path int true "Webhook ID"
+// @Success 200 {object} model.Webhook "Success to fetch data."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The webhook doesn't exist."
+// @Router /webhooks/{webhook_id} [get]
+func (controller *webhookController) GetWebhook(c echo.Context) error {
+	webhook, err := controller.service.FindByID(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// GetWebhookList returns the list of all webhooks.
+// @Summary Get a webhook list
+// @Description Get the list of all webhook subscriptions
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.Webhook "Success to fetch a webhook list."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /webhooks [get]
+func (controller *webhookController) GetWebhookList(c echo.Context) error {
+	webhooks, err := controller.service.FindAllWebhooks()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook create a new webhook by http post.
+// @Summary Create a new webhook
+// @Description Create a new webhook subscription. The requests to the URL are signed with the secret.
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param data body dto.WebhookDto true "a new webhook data for creating"
+// @Success 200 {object} model.Webhook "Success to create a new webhook."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 500 {object} controller.Problem "Failed to the registration."
+// @Router /webhooks [post]
+func (controller *webhookController) CreateWebhook(c echo.Context) error {
+	webhookDto := dto.NewWebhookDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(webhookDto); err != nil {
+		return err
+	}
+	webhook, err := controller.service.CreateWebhook(webhookDto)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook update the existing webhook by http put.
+// @Summary Update the existing webhook
+// @Description Update the existing webhook subscription. The secret is kept if it is omitted.
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param webhook_id path int true "Webhook ID"
+// @Param data body dto.WebhookDto true "the webhook data for updating"
+// @Success 200 {object} model.Webhook "Success to update the existing webhook."
+// @Failure 400 {object} controller.Problem "Failed to the validation of the fields."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The webhook doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to the update."
+// @Router /webhooks/{webhook_id} [put]
+func (controller *webhookController) UpdateWebhook(c echo.Context) error {
+	webhookDto := dto.NewWebhookDto(controller.container.GetMessages(i18n.GetLocale(c)))
+	if err := c.Bind(webhookDto); err != nil {
+		return err
+	}
+	webhook, err := controller.service.UpdateWebhook(webhookDto, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook deletes the existing webhook by http delete.
+// @Summary Delete the existing webhook
+// @Description Delete the existing webhook subscription with its delivery history
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param webhook_id path int true "Webhook ID"
+// @Success 200 {object} model.Webhook "Success to delete the existing webhook."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The webhook doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to delete."
+// @Router /webhooks/{webhook_id} [delete]
+func (controller *webhookController) DeleteWebhook(c echo.Context) error {
+	webhook, err := controller.service.DeleteWebhook(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// GetDeliveryList returns the delivery history of the webhook.
+// @Summary Get the delivery history of a webhook
+// @Description Get the deliveries of a webhook in order of the newest
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param webhook_id path int true "Webhook ID"
+// @Param page query int false "Page number"
+// @Param size query int false "Item size per page"
+// @Success 200 {array} model.WebhookDelivery "Success to fetch the delivery history."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The webhook doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to fetch data."
+// @Router /webhooks/{webhook_id}/deliveries [get]
+func (controller *webhookController) GetDeliveryList(c echo.Context) error {
+	deliveries, err := controller.service.FindDeliveries(c.Param("id"), c.QueryParam("page"), c.QueryParam("size"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver queues the payload of the delivery again.
+// @Summary Redeliver a webhook delivery
+// @Description Queue the payload of the delivery again as a new delivery
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} model.WebhookDelivery "Success to queue the delivery."
+// @Failure 401 {object} controller.Problem "Failed to the authentication."
+// @Failure 403 {object} controller.Problem "The account doesn't have the permission."
+// @Failure 404 {object} controller.Problem "The delivery doesn't exist."
+// @Failure 500 {object} controller.Problem "Failed to the redelivery."
+// @Router /webhooks/deliveries/{delivery_id}/redeliver [post]
+func (controller *webhookController) Redeliver(c echo.Context) error {
+	delivery, err := controller.service.Redeliver(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, delivery)
+}
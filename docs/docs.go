@@ -19,127 +19,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Login using username and password.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Login using username and password.",
-                "parameters": [
-                    {
-                        "description": "Username and Password for logged-in.",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.LoginDto"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Success to the authentication.",
-                        "schema": {
-                            "$ref": "#/definitions/model.Account"
-                        }
-                    },
-                    "401": {
-                        "description": "Failed to the authentication.",
-                        "schema": {
-                            "type": "boolean"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/loginAccount": {
-            "get": {
-                "description": "Get the account data of logged-in user.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Get the account data of logged-in user.",
-                "responses": {
-                    "200": {
-                        "description": "Success to fetch the account data. If the security function is disable, it returns the dummy data.",
-                        "schema": {
-                            "$ref": "#/definitions/model.Account"
-                        }
-                    },
-                    "401": {
-                        "description": "The current user haven't logged-in yet. Returns false.",
-                        "schema": {
-                            "type": "boolean"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/loginStatus": {
-            "get": {
-                "description": "Get the login status of current logged-in user.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Get the login status.",
-                "responses": {
-                    "200": {
-                        "description": "The current user have already logged-in. Returns true.",
-                        "schema": {
-                            "type": "boolean"
-                        }
-                    },
-                    "401": {
-                        "description": "The current user haven't logged-in yet. Returns false.",
-                        "schema": {
-                            "type": "boolean"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "description": "Logout.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Logout.",
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    }
-                }
-            }
-        },
-        "/books": {
+        "/admin/accounts": {
             "get": {
-                "description": "Get the list of matched books by searching",
+                "description": "Get the list of matched accounts by searching in order of the ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -147,16 +29,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Accounts"
                 ],
-                "summary": "Get a book list",
+                "summary": "Get an account list",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Keyword",
+                        "description": "Keyword of the name",
                         "name": "query",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "description": "Authority ID",
+                        "name": "authorityId",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number",
@@ -172,27 +60,39 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success to fetch a book list.",
+                        "description": "Success to fetch an account list.",
                         "schema": {
-                            "$ref": "#/definitions/model.Page"
+                            "$ref": "#/definitions/model.AccountPage"
                         }
                     },
                     "400": {
-                        "description": "Failed to fetch data.",
+                        "description": "The parameters are invalid.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     },
                     "401": {
-                        "description": "Failed to the authentication. Returns false.",
+                        "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new book",
+                "description": "Create a new account. The password is stored as the bcrypt hash.",
                 "consumes": [
                     "application/json"
                 ],
@@ -200,45 +100,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Accounts"
                 ],
-                "summary": "Create a new book",
+                "summary": "Create a new account",
                 "parameters": [
                     {
-                        "description": "a new book data for creating",
+                        "description": "a new account data for creating",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.BookDto"
+                            "$ref": "#/definitions/dto.AccountDto"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success to create a new book.",
+                        "description": "Success to create a new account.",
                         "schema": {
-                            "$ref": "#/definitions/model.Book"
+                            "$ref": "#/definitions/model.Account"
                         }
                     },
                     "400": {
-                        "description": "Failed to the registration.",
+                        "description": "Failed to the validation of the fields.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     },
                     "401": {
-                        "description": "Failed to the authentication. Returns false.",
+                        "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The name is already used.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "The authority doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             }
         },
-        "/books/{book_id}": {
+        "/admin/accounts/{account_id}": {
             "get": {
-                "description": "Get a book",
+                "description": "Get an account. The password hash is never returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -246,14 +170,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Accounts"
                 ],
-                "summary": "Get a book",
+                "summary": "Get an account",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
-                        "name": "book_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
                     }
@@ -262,25 +186,31 @@ const docTemplate = `{
                     "200": {
                         "description": "Success to fetch data.",
                         "schema": {
-                            "$ref": "#/definitions/model.Book"
+                            "$ref": "#/definitions/model.Account"
                         }
                     },
-                    "400": {
-                        "description": "Failed to fetch data.",
+                    "401": {
+                        "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Failed to the authentication. Returns false.",
+                    "403": {
+                        "description": "The account doesn't have the permission.",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The account doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update the existing book",
+                "description": "Update the name, the authority and the status of the existing account. The password is kept if it is omitted, and setting it logs out all the sessions and revokes the refresh tokens of the account.",
                 "consumes": [
                     "application/json"
                 ],
@@ -288,50 +218,80 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Accounts"
                 ],
-                "summary": "Update the existing book",
+                "summary": "Update the existing account",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
-                        "name": "book_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "the book data for updating",
+                        "description": "the account data for updating",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.BookDto"
+                            "$ref": "#/definitions/dto.AccountDto"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success to update the existing book.",
+                        "description": "Success to update the existing account.",
                         "schema": {
-                            "$ref": "#/definitions/model.Book"
+                            "$ref": "#/definitions/model.Account"
                         }
                     },
                     "400": {
-                        "description": "Failed to the update.",
+                        "description": "Failed to the validation of the fields.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     },
                     "401": {
-                        "description": "Failed to the authentication. Returns false.",
+                        "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The account doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The name is already used, or the account is the last administrator.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "The authority doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete the existing book",
+                "description": "Delete the existing account. The last administrator can't be deleted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -339,43 +299,61 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Books"
+                    "Accounts"
                 ],
-                "summary": "Delete the existing book",
+                "summary": "Delete the existing account",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
-                        "name": "book_id",
+                        "description": "Account ID",
+                        "name": "account_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success to delete the existing book.",
+                        "description": "Success to delete the existing account.",
                         "schema": {
-                            "$ref": "#/definitions/model.Book"
+                            "$ref": "#/definitions/model.Account"
                         }
                     },
-                    "400": {
-                        "description": "Failed to delete.",
+                    "401": {
+                        "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Failed to the authentication. Returns false.",
+                    "403": {
+                        "description": "The account doesn't have the permission.",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The account doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The account is the last administrator.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             }
         },
-        "/categories": {
+        "/admin/accounts/{account_id}/sessions": {
             "get": {
-                "description": "Get a category list",
+                "description": "Get the login sessions of the account with the creation time, the last activity, the IP address and the user agent.",
                 "consumes": [
                     "application/json"
                 ],
@@ -383,31 +361,109 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Accounts"
+                ],
+                "summary": "Get the sessions of the account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get a category list",
                 "responses": {
                     "200": {
-                        "description": "Success to fetch a category list.",
+                        "description": "Success to fetch the sessions.",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Category"
+                                "$ref": "#/definitions/model.AccountSession"
                             }
                         }
                     },
                     "401": {
                         "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
                         }
-                    }
-                }
-            }
-        },
-        "/formats": {
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The account doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke all sessions and refresh tokens of the account, which is logged out immediately. The access tokens already issued are no longer valid as well.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Force the account to logout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The account doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/apikeys": {
             "get": {
-                "description": "Get a format list",
+                "description": "Get the API keys of all accounts, or the ones of the account if its ID is given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -415,31 +471,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Formats"
+                    "Accounts"
+                ],
+                "summary": "Get the API keys of all accounts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "accountId",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get a format list",
                 "responses": {
                     "200": {
-                        "description": "Success to fetch a format list.",
+                        "description": "Success to fetch the API keys.",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Format"
+                                "$ref": "#/definitions/model.APIKey"
                             }
                         }
                     },
                     "401": {
                         "description": "Failed to the authentication.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             }
         },
-        "/health": {
-            "get": {
-                "description": "Get the status of this application",
+        "/admin/apikeys/{api_key_id}": {
+            "delete": {
+                "description": "Revoke the API key of any account. The revoked key remains in the list.",
                 "consumes": [
                     "application/json"
                 ],
@@ -447,77 +523,3795 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Accounts"
+                ],
+                "summary": "Revoke the API key of any account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "api_key_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get the status of this application",
                 "responses": {
                     "200": {
-                        "description": "healthy: This application is started.",
+                        "description": "Success to revoke the API key.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.APIKey"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     },
                     "404": {
-                        "description": "None: This application is stopped.",
+                        "description": "The API key doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revoke the API key.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/auditlogs": {
+            "get": {
+                "description": "Get the records of the security audit log matched the filters in order of the newest. The times are given in RFC 3339 or as the dates in UTC.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get the records of the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event such as login, access.forbidden and account.update",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Outcome of success, failure or denied",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target of the event",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Remote IP address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inclusive lower bound of the time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exclusive upper bound of the time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item size per page",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the records.",
+                        "schema": {
+                            "$ref": "#/definitions/model.AuditLogPage"
+                        }
+                    },
+                    "400": {
+                        "description": "The time is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/auditlogs.csv": {
+            "get": {
+                "description": "Download the newest records of the security audit log matched the filters as CSV in order of the oldest, up to the export limit of the configuration. If the older records are left out, the X-Audit-Log-Truncated header is true. The values given by the clients which start with =, +, - or @ are prefixed with a quote, not to be evaluated as formulas by the spreadsheets.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Download the records of the audit log as CSV",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event such as login, access.forbidden and account.update",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Outcome of success, failure or denied",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target of the event",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Remote IP address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inclusive lower bound of the time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exclusive upper bound of the time",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The records in CSV.",
                         "schema": {
                             "type": "string"
+                        },
+                        "headers": {
+                            "X-Audit-Log-Truncated": {
+                                "type": "boolean",
+                                "description": "Whether the older records are left out by the export limit."
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "The time is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.BookDto": {
-            "type": "object",
-            "required": [
-                "isbn",
-                "title"
-            ],
+        },
+        "/admin/auditlogs/verify": {
+            "get": {
+                "description": "Verify the hash chain of the security audit log from the oldest record, and report the first record which breaks it. Keep the last hash outside to detect the deletion of the latest records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Verify the chain of the audit log",
+                "responses": {
+                    "200": {
+                        "description": "The result of the verification.",
+                        "schema": {
+                            "$ref": "#/definitions/model.AuditLogVerification"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/authorities": {
+            "get": {
+                "description": "Get the list of all authorities with their permissions. The administrator has all permissions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorities"
+                ],
+                "summary": "Get an authority list",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch an authority list.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Authority"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new authority with its permissions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorities"
+                ],
+                "summary": "Create a new authority",
+                "parameters": [
+                    {
+                        "description": "a new authority data for creating",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorityDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to create a new authority.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Authority"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the fields.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The name is already used.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/authorities/{authority_id}/permissions": {
+            "put": {
+                "description": "Replace the permissions of the existing authority. The name in the body is ignored, and the permissions of the administrator can't be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorities"
+                ],
+                "summary": "Update the permissions of the existing authority",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Authority ID",
+                        "name": "authority_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the permissions for updating",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorityDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to update the permissions.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Authority"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the permissions.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The authority doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The authority is the administrator.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/backup": {
+            "get": {
+                "description": "Download every table as the tar.gz archive which has a manifest and NDJSON files",
+                "produces": [
+                    "application/gzip"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Download the backup archive",
+                "responses": {
+                    "200": {
+                        "description": "The backup archive.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the backup.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/lockouts": {
+            "get": {
+                "description": "Get the usernames and the remote IP addresses which are locked out now after too many failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get the lockouts of the login",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the lockouts.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.LoginAttempt"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/lockouts/unlock": {
+            "post": {
+                "description": "Release the lockout of the username or the remote IP address, and forget their failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Release the lockout of the login",
+                "parameters": [
+                    {
+                        "description": "The username or the remote IP address.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UnlockDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Neither the username nor the remote IP address is given.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/permissions": {
+            "get": {
+                "description": "Get the list of all permissions which can be assigned to authorities",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorities"
+                ],
+                "summary": "Get a permission list",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch a permission list.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Permission"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/restore": {
+            "post": {
+                "description": "Replace the whole data with the tar.gz archive in a transaction, preserving the IDs. The sessions, the refresh tokens and the API keys are deleted as well, so every account including the current one has to log in again.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Restore the backup archive",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "The backup archive",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to restore the archive.",
+                        "schema": {
+                            "$ref": "#/definitions/backup.Manifest"
+                        }
+                    },
+                    "400": {
+                        "description": "The archive is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore the archive.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa": {
+            "get": {
+                "description": "Get whether the two-factor authentication of logged-in user is enabled or required, and the number of the unused recovery codes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the status of the two-factor authentication.",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the status.",
+                        "schema": {
+                            "$ref": "#/definitions/model.TwoFactorStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/confirm": {
+            "post": {
+                "description": "Enable the TOTP secret by confirming a code of it. The recovery codes are returned only this time, so keep them safely.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Enable the two-factor authentication.",
+                "parameters": [
+                    {
+                        "description": "The TOTP code of the new secret.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to enable the two-factor authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "The code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The secret hasn't been set up, or is already enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/disable": {
+            "post": {
+                "description": "Delete the TOTP secret and the recovery codes after checking the password and the code. The administrators can't disable it if it is required for them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Disable the two-factor authentication.",
+                "parameters": [
+                    {
+                        "description": "The current password and the TOTP code or a recovery code.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorDisableDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "The password or the code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The two-factor authentication is required.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/recoveryCodes": {
+            "post": {
+                "description": "Replace the recovery codes after checking the TOTP code or a recovery code. The new codes are returned only this time, so keep them safely.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Regenerate the recovery codes.",
+                "parameters": [
+                    {
+                        "description": "The TOTP code or a recovery code.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to regenerate the recovery codes.",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "The code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The two-factor authentication isn't enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/setup": {
+            "post": {
+                "description": "Create a new TOTP secret, which is enabled after a code of it is confirmed. Register the provisioning URI or the QR code to the authenticator app.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Set up the two-factor authentication.",
+                "responses": {
+                    "200": {
+                        "description": "Success to create a new secret.",
+                        "schema": {
+                            "$ref": "#/definitions/model.TwoFactorEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The two-factor authentication is already enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/apikeys": {
+            "get": {
+                "description": "Get the API keys of logged-in user, including the revoked and expired ones. The keys themselves aren't returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the API keys of logged-in user.",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the API keys.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new API key, which is sent by the X-API-Key header. The key is returned only this time, so keep it safely. The scopes must be the permissions which the user has.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Create a new API key of logged-in user.",
+                "parameters": [
+                    {
+                        "description": "The name, the scopes and the expiration date of the key.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.APIKeyDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to create a new API key.",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the fields.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/apikeys/{api_key_id}": {
+            "delete": {
+                "description": "Revoke the API key of logged-in user. The revoked key remains in the list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Revoke the API key of logged-in user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "api_key_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to revoke the API key.",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIKey"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The API key doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revoke the API key.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/csrf": {
+            "get": {
+                "description": "Get the CSRF token of the session, which is also set to the XSRF-TOKEN cookie. The requests authenticated by the session have to send it back by the X-XSRF-TOKEN header, except for GET, HEAD and OPTIONS.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the CSRF token of the session.",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the CSRF token.",
+                        "schema": {
+                            "$ref": "#/definitions/model.CSRFToken"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to issue the CSRF token.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/locale": {
+            "put": {
+                "description": "Change the preferred locale of logged-in user, which is used for the messages unless the lang parameter is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Change the preferred locale of logged-in user.",
+                "parameters": [
+                    {
+                        "description": "The locale such as en and ja.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LocaleDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to change the locale.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "The locale isn't supported.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login using username and password. If the account has enabled the two-factor authentication, or it is required to enable it, the login waits for the second step and returns 202.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login using username and password.",
+                "parameters": [
+                    {
+                        "description": "Username and Password for logged-in.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Account"
+                        }
+                    },
+                    "202": {
+                        "description": "The code of the two-factor authentication, or the enrollment of it is required.",
+                        "schema": {
+                            "$ref": "#/definitions/model.LoginChallenge"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to parse the request.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins of the username or from the remote IP address.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Complete the login which has passed the password by the TOTP code or a recovery code. The login has to start again after 5 incorrect codes, and the incorrect codes are counted as the failed logins of the account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Complete the login by the code of the two-factor authentication.",
+                "parameters": [
+                    {
+                        "description": "The TOTP code or a recovery code.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "The code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The login hasn't passed the password, has expired, or requires the enrollment.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins of the account or from the remote IP address.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa/confirm": {
+            "post": {
+                "description": "Enable the TOTP secret by confirming a code of it, and complete the login. The recovery codes are returned only this time, so keep them safely.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Enable the two-factor authentication required to login.",
+                "parameters": [
+                    {
+                        "description": "The TOTP code of the new secret.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "The code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The login hasn't passed the password, or has expired.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The secret hasn't been set up.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa/setup": {
+            "post": {
+                "description": "Create a new TOTP secret of the account whose login requires the enrollment of the two-factor authentication. Register the provisioning URI or the QR code to the authenticator app.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Set up the two-factor authentication required to login.",
+                "responses": {
+                    "200": {
+                        "description": "Success to create a new secret.",
+                        "schema": {
+                            "$ref": "#/definitions/model.TwoFactorEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "The login hasn't passed the password, or has expired.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/loginAccount": {
+            "get": {
+                "description": "Get the account data of logged-in user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the account data of logged-in user.",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the account data. If the security function is disable, it returns the dummy data.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Account"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/loginStatus": {
+            "get": {
+                "description": "Get the login status of current logged-in user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the login status.",
+                "responses": {
+                    "200": {
+                        "description": "The current user have already logged-in. Returns true.",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Logout, and revoke the current session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Exchange the authorization code for the ID token, and login with the account linked to the identity. The account is created if it doesn't exist, and the authorities of the created account are mapped from the groups of the identity. If the request has been started by the link, the identity is linked to the logged-in account instead. If the account has enabled or is required to enable the two-factor authentication, it redirects to the two-factor URL with twoFactor=required or enrollment=required, and the login is completed by the second step as well as the login by the password.",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Complete the login through the OpenID Connect provider.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The authorization code.",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The state of the authorization request.",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The error of the authorization request.",
+                        "name": "error",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "401": {
+                        "description": "The state or the ID token is invalid, the provider denied the login, or the account is disabled.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The account is the last administrator, or the identity is linked to another account.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to connect to the identity provider.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/link": {
+            "post": {
+                "description": "Return the URL of the authorization endpoint of the OpenID Connect provider to link the identity to the account logged in by the session. The identity is linked by the callback after the login to the provider, and the account can login through the provider since then. The email of the identity never links it to an account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Link the identity of the OpenID Connect provider.",
+                "responses": {
+                    "200": {
+                        "description": "The URL of the provider to redirect to.",
+                        "schema": {
+                            "$ref": "#/definitions/model.IdentityLink"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session, or the CSRF token is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to connect to the identity provider.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirect to the authorization endpoint of the OpenID Connect provider by the authorization code flow with PKCE. The provider redirects to the callback after the login.",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login through the OpenID Connect provider.",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "500": {
+                        "description": "Failed to connect to the identity provider.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password": {
+            "post": {
+                "description": "Change the password of logged-in user after checking the current password. The other sessions of the user are logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Change the password of logged-in user.",
+                "parameters": [
+                    {
+                        "description": "The current password and the new password.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasswordDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to change the password.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "The current password is incorrect, or the new password is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Request the token to reset the password by the username or the email. The token is delivered by the notifier, and it is accepted even if the account doesn't exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request the password reset.",
+                "parameters": [
+                    {
+                        "description": "The username or the email.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasswordResetRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Neither the username nor the email is given.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests for the account or from the remote IP address.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the token.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset/confirm": {
+            "post": {
+                "description": "Set the new password by using the token of the password reset. The token can be used once, and all the sessions of the account are logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset the password.",
+                "parameters": [
+                    {
+                        "description": "The token and the new password.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasswordResetDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "The token is invalid or has expired, or the new password is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "description": "Get the login sessions of logged-in user with the creation time, the last activity, the IP address and the user agent. The session of the current request is marked as current.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get the sessions of logged-in user",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the sessions.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AccountSession"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke all sessions of logged-in user except the current one, which are logged out immediately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Revoke the other sessions of logged-in user.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{session_id}": {
+            "delete": {
+                "description": "Revoke the session of logged-in user, which is logged out immediately. If it is the current session, the current user is logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Revoke the session of logged-in user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "The current user haven't logged-in yet.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account isn't logged in by the session.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The session doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token": {
+            "post": {
+                "description": "Issue the short-lived access token for the Authorization: Bearer header, and the refresh token to issue a new one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Issue the access token.",
+                "parameters": [
+                    {
+                        "description": "Username and Password.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/model.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to parse the request.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins of the username or from the remote IP address.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to issue the token.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token/refresh": {
+            "post": {
+                "description": "Issue a new access token and refresh token. The given refresh token is revoked, and using it again revokes all refresh tokens of the account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh the access token.",
+                "parameters": [
+                    {
+                        "description": "The refresh token.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to refresh the token.",
+                        "schema": {
+                            "$ref": "#/definitions/model.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to parse the request.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "The refresh token is invalid, revoked or has expired.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to issue the token.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token/revoke": {
+            "post": {
+                "description": "Revoke the refresh token. It succeeds even if the token is unknown or has already been revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Revoke the refresh token.",
+                "parameters": [
+                    {
+                        "description": "The refresh token.",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshTokenDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Failed to parse the request.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revoke the token.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/books": {
+            "get": {
+                "description": "Get the list of matched books by searching",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Get a book list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Keyword",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item size per page",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch a book list.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Page"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Create a new book",
+                "parameters": [
+                    {
+                        "description": "a new book data for creating",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to create a new book.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the fields.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "The category or the format doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/labels": {
+            "get": {
+                "description": "Print the labels of books on A4 sheets of 3 x 8 labels. SVG has a sheet, and PDF has as many pages as needed.",
+                "produces": [
+                    "application/pdf",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Print the labels of books on A4 sheets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated book IDs",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pdf (default) or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "code128 or ean13. The default is ean13 for ISBN-13, otherwise code128.",
+                        "name": "symbology",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The number of used positions on the first sheet",
+                        "name": "skip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The sheets of labels.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "The parameters are invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The book doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to print the labels.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{book_id}": {
+            "get": {
+                "description": "Get a book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Get a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The book doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the existing book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Update the existing book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the book data for updating",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to update the existing book.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the fields.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The book doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "The category or the format doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the existing book",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Books"
+                ],
+                "summary": "Delete the existing book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to delete the existing book.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The book doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{book_id}/label": {
+            "get": {
+                "description": "Print the label which has the title, the barcode of ISBN and the QR code linking to the book page",
+                "produces": [
+                    "image/svg+xml",
+                    "image/png"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Print the label of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "svg (default) or png",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "code128 or ean13. The default is ean13 for ISBN-13, otherwise code128.",
+                        "name": "symbology",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The label of the book.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "The parameters are invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The book doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to print the label.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Get a category list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get a category list",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch a category list.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Category"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Stream the events of data changes as Server-Sent Events.\nThe event ID can be sent by Last-Event-ID header to resume, and \"reset\" event is sent if it can't be resumed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Stream the events of data changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated entity types to subscribe (book)",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The last event ID received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The stream of events.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to subscribe.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/ws": {
+            "get": {
+                "description": "Stream the events of data changes as the JSON messages of WebSocket.\nThe event ID can be sent by lastEventId parameter to resume, and \"reset\" message is sent if it can't be resumed.",
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Stream the events of data changes over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated entity types to subscribe (book)",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The last event ID received",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching to WebSocket.",
+                        "schema": {
+                            "$ref": "#/definitions/broker.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to subscribe.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/formats": {
+            "get": {
+                "description": "Get a format list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Formats"
+                ],
+                "summary": "Get a format list",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch a format list.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Format"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Execute a GraphQL query or mutation over books, categories, formats and the logged-in account. The mutations have to be sent by http post.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "Execute a GraphQL request",
+                "parameters": [
+                    {
+                        "description": "GraphQL query, variables and operation name",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GraphQLDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The result of the request which has data and errors.",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to parse the request.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "405": {
+                        "description": "The mutation is sent by http get.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Get the status of this application",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Get the status of this application",
+                "responses": {
+                    "200": {
+                        "description": "healthy: This application is started.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "None: This application is stopped.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/summary": {
+            "get": {
+                "description": "Get the number of books grouped by category, format and the month of registration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get the summary report",
+                "responses": {
+                    "200": {
+                        "description": "Success to aggregate the catalog.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Summary"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/summary.csv": {
+            "get": {
+                "description": "Download the summary report as CSV which has the columns of section, id, name and count",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Download the summary report as CSV",
+                "responses": {
+                    "200": {
+                        "description": "The summary report in CSV.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Get the list of all webhook subscriptions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook list",
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch a webhook list.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new webhook subscription. The requests to the URL are signed with the secret.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create a new webhook",
+                "parameters": [
+                    {
+                        "description": "a new webhook data for creating",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to create a new webhook.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the fields.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the registration.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "description": "Queue the payload of the delivery again as a new delivery",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Redeliver a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to queue the delivery.",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The delivery doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the redelivery.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook_id}": {
+            "get": {
+                "description": "Get a webhook subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The webhook doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the existing webhook subscription. The secret is kept if it is omitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update the existing webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the webhook data for updating",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to update the existing webhook.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to the validation of the fields.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The webhook doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to the update.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the existing webhook subscription with its delivery history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete the existing webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to delete the existing webhook.",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The webhook doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "description": "Get the deliveries of a webhook in order of the newest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get the delivery history of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Item size per page",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success to fetch the delivery history.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to the authentication.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The account doesn't have the permission.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The webhook doesn't exist.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch data.",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "backup.Manifest": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "dialect": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backup.TableEntry"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "backup.TableEntry": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "broker.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "data": {},
+                "entity": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "The request has invalid fields"
+                },
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/books"
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "3Y8sYkZ1nQ0pWJcFzWn1lq2N8XxT0b7e"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:problem-type:validation"
+                }
+            }
+        },
+        "dto.APIKeyDto": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.AccountDto": {
+            "type": "object",
+            "required": [
+                "authorityId",
+                "name"
+            ],
+            "properties": {
+                "authorityId": {
+                    "type": "integer"
+                },
+                "authorityIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                }
+            }
+        },
+        "dto.AuthorityDto": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 2
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.BookDto": {
+            "type": "object",
+            "required": [
+                "isbn",
+                "title"
+            ],
+            "properties": {
+                "categoryId": {
+                    "type": "integer"
+                },
+                "formatId": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 10
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3
+                }
+            }
+        },
+        "dto.GraphQLDto": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "dto.LocaleDto": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginDto": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PasswordDto": {
+            "type": "object",
+            "required": [
+                "currentPassword",
+                "newPassword"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "type": "string"
+                },
+                "newPassword": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                }
+            }
+        },
+        "dto.PasswordResetDto": {
+            "type": "object",
+            "required": [
+                "newPassword",
+                "token"
+            ],
+            "properties": {
+                "newPassword": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PasswordResetRequestDto": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RefreshTokenDto": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TwoFactorCodeDto": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TwoFactorDisableDto": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UnlockDto": {
+            "type": "object",
+            "properties": {
+                "remoteIp": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.WebhookDto": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "model.APIKey": {
+            "type": "object",
+            "properties": {
+                "accountId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.Account": {
+            "type": "object",
+            "properties": {
+                "authorities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Authority"
+                    }
+                },
+                "authority": {
+                    "$ref": "#/definitions/model.Authority"
+                },
+                "authority_id": {
+                    "type": "integer"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AccountPage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Account"
+                    }
+                },
+                "last": {
+                    "type": "boolean"
+                },
+                "numberOfElements": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "totalElements": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AccountSession": {
+            "type": "object",
+            "properties": {
+                "accountId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastActiveAt": {
+                    "type": "string"
+                },
+                "remoteIp": {
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AuditLog": {
+            "type": "object",
             "properties": {
-                "categoryId": {
+                "actorId": {
                     "type": "integer"
                 },
-                "formatId": {
+                "actorName": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "isbn": {
-                    "type": "string",
-                    "maxLength": 20,
-                    "minLength": 10
+                "outcome": {
+                    "type": "string"
                 },
-                "title": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 3
+                "prevHash": {
+                    "type": "string"
+                },
+                "remoteIp": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string"
                 }
             }
         },
-        "dto.LoginDto": {
+        "model.AuditLogPage": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string"
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditLog"
+                    }
                 },
-                "username": {
-                    "type": "string"
+                "last": {
+                    "type": "boolean"
+                },
+                "numberOfElements": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "totalElements": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
                 }
             }
         },
-        "model.Account": {
+        "model.AuditLogVerification": {
             "type": "object",
             "properties": {
-                "authority": {
-                    "$ref": "#/definitions/model.Authority"
+                "brokenId": {
+                    "type": "integer"
                 },
-                "authority_id": {
+                "checked": {
                     "type": "integer"
                 },
-                "id": {
+                "firstId": {
                     "type": "integer"
                 },
-                "name": {
+                "lastHash": {
+                    "type": "string"
+                },
+                "lastId": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
                 }
             }
         },
@@ -529,6 +4323,12 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -541,6 +4341,9 @@ const docTemplate = `{
                 "categoryId": {
                     "type": "integer"
                 },
+                "createdAt": {
+                    "type": "string"
+                },
                 "format": {
                     "$ref": "#/definitions/model.Format"
                 },
@@ -555,6 +4358,20 @@ const docTemplate = `{
                 },
                 "title": {
                     "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CSRFToken": {
+            "type": "object",
+            "properties": {
+                "headerName": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
                 }
             }
         },
@@ -578,6 +4395,23 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "mediaType": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GroupCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -586,6 +4420,59 @@ const docTemplate = `{
                 }
             }
         },
+        "model.IdentityLink": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LoginAttempt": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lastFailedAt": {
+                    "type": "string"
+                },
+                "lockedUntil": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LoginChallenge": {
+            "type": "object",
+            "properties": {
+                "enrollmentRequired": {
+                    "type": "boolean"
+                },
+                "twoFactorRequired": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.MonthlyCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "month": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Page": {
             "type": "object",
             "properties": {
@@ -614,6 +4501,173 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "model.Permission": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Read the books and their labels, feeds and events."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "book:read"
+                }
+            }
+        },
+        "model.RecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.Summary": {
+            "type": "object",
+            "properties": {
+                "byCategory": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GroupCount"
+                    }
+                },
+                "byFormat": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GroupCount"
+                    }
+                },
+                "byMonth": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MonthlyCount"
+                    }
+                },
+                "generatedAt": {
+                    "type": "string"
+                },
+                "totalBooks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "model.TwoFactorEnrollment": {
+            "type": "object",
+            "properties": {
+                "provisioningUri": {
+                    "type": "string"
+                },
+                "qrCode": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TwoFactorStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "recoveryCodesRemaining": {
+                    "type": "integer"
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastAttemptAt": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,100 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const (
+	// AtomNamespace is the XML namespace of the Atom syndication format.
+	AtomNamespace = "http://www.w3.org/2005/Atom"
+	// DublinCoreNamespace is the XML namespace of the DCMI metadata terms.
+	DublinCoreNamespace = "http://purl.org/dc/terms/"
+	// AtomType is the media type of an Atom document.
+	AtomType = "application/atom+xml"
+)
+
+// Feed represents the root element of an Atom feed.
+type Feed struct {
+	XMLName      xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID           string   `xml:"id"`
+	Title        string   `xml:"title"`
+	Subtitle     string   `xml:"subtitle,omitempty"`
+	Updated      string   `xml:"updated"`
+	Author       *Person  `xml:"author,omitempty"`
+	Links        []Link   `xml:"link"`
+	TotalResults int      `xml:"http://a9.com/-/spec/opensearch/1.1/ totalResults,omitempty"`
+	ItemsPerPage int      `xml:"http://a9.com/-/spec/opensearch/1.1/ itemsPerPage,omitempty"`
+	StartIndex   int      `xml:"http://a9.com/-/spec/opensearch/1.1/ startIndex,omitempty"`
+	Entries      []Entry  `xml:"entry"`
+}
+
+// Entry represents an entry element of an Atom feed.
+type Entry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    string     `xml:"updated"`
+	Published  string     `xml:"published,omitempty"`
+	Authors    []Person   `xml:"author,omitempty"`
+	Identifier string     `xml:"http://purl.org/dc/terms/ identifier,omitempty"`
+	Categories []Category `xml:"category,omitempty"`
+	Summary    *Text      `xml:"summary,omitempty"`
+	Content    *Text      `xml:"content,omitempty"`
+	Links      []Link     `xml:"link"`
+}
+
+// Person represents an author or a contributor of an Atom feed.
+type Person struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Link represents a link element of an Atom feed.
+type Link struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+// Category represents a category element of an Atom entry.
+type Category struct {
+	Term   string `xml:"term,attr"`
+	Label  string `xml:"label,attr,omitempty"`
+	Scheme string `xml:"scheme,attr,omitempty"`
+}
+
+// Text represents a text construct such as summary and content.
+type Text struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+// NewFeed is constructor.
+func NewFeed(id string, title string, updated time.Time) *Feed {
+	return &Feed{ID: id, Title: title, Updated: FormatTime(updated)}
+}
+
+// AddLink appends a link to this feed.
+func (f *Feed) AddLink(rel string, href string, linkType string) {
+	f.Links = append(f.Links, Link{Rel: rel, Href: href, Type: linkType})
+}
+
+// AddEntry appends an entry to this feed.
+func (f *Feed) AddEntry(entry Entry) {
+	f.Entries = append(f.Entries, entry)
+}
+
+// FormatTime formats the given time according to RFC 3339 which is used by Atom.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Marshal returns the XML document of the given feed element including the XML header.
+func Marshal(v interface{}) ([]byte, error) {
+	bytes, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), bytes...), nil
+}
@@ -0,0 +1,37 @@
+package feed
+
+// Media types and link relations defined by OPDS Catalog 1.2.
+// ref: https://specs.opds.io/opds-1.2
+const (
+	// NavigationType is the media type of an OPDS navigation feed.
+	NavigationType = AtomType + ";profile=opds-catalog;kind=navigation"
+	// AcquisitionType is the media type of an OPDS acquisition feed.
+	AcquisitionType = AtomType + ";profile=opds-catalog;kind=acquisition"
+	// EntryType is the media type of a complete OPDS catalog entry.
+	EntryType = AtomType + ";type=entry;profile=opds-catalog"
+)
+
+const (
+	// RelSelf represents the link to the feed itself.
+	RelSelf = "self"
+	// RelStart represents the link to the root of the catalog.
+	RelStart = "start"
+	// RelUp represents the link to the parent feed.
+	RelUp = "up"
+	// RelNext represents the link to the next page.
+	RelNext = "next"
+	// RelPrevious represents the link to the previous page.
+	RelPrevious = "previous"
+	// RelFirst represents the link to the first page.
+	RelFirst = "first"
+	// RelSearch represents the link to the OpenSearch description document.
+	RelSearch = "search"
+	// RelSubsection represents the link to a sub catalog.
+	RelSubsection = "subsection"
+	// RelAlternate represents the link to an alternate representation.
+	RelAlternate = "alternate"
+	// RelAcquisition represents the link to the file of the publication which can be acquired without a payment.
+	RelAcquisition = "http://opds-spec.org/acquisition"
+	// RelSortNew represents the link to the feed sorted by the newest entries.
+	RelSortNew = "http://opds-spec.org/sort/new"
+)
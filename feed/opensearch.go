@@ -0,0 +1,33 @@
+package feed
+
+import "encoding/xml"
+
+const (
+	// OpenSearchType is the media type of an OpenSearch description document.
+	OpenSearchType = "application/opensearchdescription+xml"
+	// SearchTermsParam is the template parameter replaced by the search keyword.
+	SearchTermsParam = "{searchTerms}"
+)
+
+// OpenSearchDescription represents the OpenSearch 1.1 description document.
+// ref: https://github.com/dewitt/opensearch/blob/master/opensearch-1-1-draft-6.md
+type OpenSearchDescription struct {
+	XMLName        xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName      string          `xml:"ShortName"`
+	Description    string          `xml:"Description"`
+	InputEncoding  string          `xml:"InputEncoding"`
+	OutputEncoding string          `xml:"OutputEncoding"`
+	URLs           []OpenSearchURL `xml:"Url"`
+}
+
+// OpenSearchURL represents the template of a search request.
+type OpenSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// NewOpenSearchDescription is constructor.
+func NewOpenSearchDescription(shortName string, description string, urls ...OpenSearchURL) *OpenSearchDescription {
+	return &OpenSearchDescription{ShortName: shortName, Description: description,
+		InputEncoding: "UTF-8", OutputEncoding: "UTF-8", URLs: urls}
+}
@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// RSSType is the media type of an RSS document.
+const RSSType = "application/rss+xml"
+
+// RSS represents the root element of an RSS 2.0 feed.
+// ref: https://www.rssboard.org/rss-specification
+type RSS struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	XmlnsAtom string   `xml:"xmlns:atom,attr"`
+	Channel   Channel  `xml:"channel"`
+}
+
+// Channel represents the channel element of an RSS feed.
+type Channel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      *AtomLink `xml:"atom:link,omitempty"`
+	Items         []Item    `xml:"item"`
+}
+
+// AtomLink represents the link to the feed itself which is recommended by the RSS advisory board.
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// Item represents an item element of an RSS feed.
+type Item struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description,omitempty"`
+	Category    string `xml:"category,omitempty"`
+	GUID        GUID   `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+}
+
+// GUID represents the unique identifier of an item.
+type GUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// NewRSS is constructor.
+func NewRSS(title string, link string, description string, selfURL string, built time.Time) *RSS {
+	return &RSS{
+		Version:   "2.0",
+		XmlnsAtom: AtomNamespace,
+		Channel: Channel{
+			Title:         title,
+			Link:          link,
+			Description:   description,
+			LastBuildDate: FormatRSSTime(built),
+			AtomLink:      &AtomLink{Rel: RelSelf, Href: selfURL, Type: RSSType},
+		},
+	}
+}
+
+// AddItem appends an item to this feed.
+func (r *RSS) AddItem(item Item) {
+	r.Channel.Items = append(r.Channel.Items, item)
+}
+
+// FormatRSSTime formats the given time according to RFC 1123 which is used by RSS.
+func FormatRSSTime(t time.Time) string {
+	return t.UTC().Format(time.RFC1123Z)
+}
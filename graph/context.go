@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/service"
+)
+
+type contextKey string
+
+const (
+	echoContextKey    contextKey = "echoContext"
+	categoryLoaderKey contextKey = "categoryBookLoader"
+	formatLoaderKey   contextKey = "formatBookLoader"
+)
+
+// newContext returns the context of a request, and it holds the echo context and the loaders of the request.
+func newContext(c echo.Context, books service.BookService) context.Context {
+	ctx := context.WithValue(c.Request().Context(), echoContextKey, c)
+	ctx = context.WithValue(ctx, categoryLoaderKey, newBookLoader(books.FindBooksByCategoryIDs,
+		func(book *model.Book) uint { return book.CategoryID }))
+	ctx = context.WithValue(ctx, formatLoaderKey, newBookLoader(books.FindBooksByFormatIDs,
+		func(book *model.Book) uint { return book.FormatID }))
+	return ctx
+}
+
+func echoContext(ctx context.Context) echo.Context {
+	c, _ := ctx.Value(echoContextKey).(echo.Context)
+	return c
+}
+
+func loader(ctx context.Context, key contextKey) *bookLoader {
+	l, _ := ctx.Value(key).(*bookLoader)
+	return l
+}
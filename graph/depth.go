@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// depthCounter counts the depth of the fields selected by a document. The depth of each fragment is counted
+// only once, so that the fragments spread many times don't make the counting expensive.
+type depthCounter struct {
+	fragments map[string]*ast.FragmentDefinition
+	depths    map[string]int
+	expanding map[string]bool
+}
+
+// queryDepth returns the max depth of the fields selected by the operations of the document, where the fields
+// of the operation are at the depth 1. The fragments are expanded where they are spread, and the fields of
+// the introspection are not counted because they only read the schema.
+func queryDepth(doc *ast.Document) int {
+	counter := &depthCounter{fragments: map[string]*ast.FragmentDefinition{}, depths: map[string]int{},
+		expanding: map[string]bool{}}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			counter.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth := 0
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			if d := counter.selectionDepth(op.SelectionSet); d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth
+}
+
+// selectionDepth returns the depth of the selection set.
+func (counter *depthCounter) selectionDepth(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+	depth := 0
+	for _, selection := range set.Selections {
+		d := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Name != nil && strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			d = 1 + counter.selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			d = counter.selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Name != nil {
+				d = counter.fragmentDepth(s.Name.Value)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// fragmentDepth returns the depth of the fragment of given name. The fragment which is being expanded is
+// skipped, so that the cycles of the fragments don't recurse forever before the validation rejects them.
+func (counter *depthCounter) fragmentDepth(name string) int {
+	if d, ok := counter.depths[name]; ok {
+		return d
+	}
+	fragment, ok := counter.fragments[name]
+	if !ok || counter.expanding[name] {
+		return 0
+	}
+	counter.expanding[name] = true
+	d := counter.selectionDepth(fragment.SelectionSet)
+	delete(counter.expanding, name)
+	counter.depths[name] = d
+	return d
+}
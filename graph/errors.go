@@ -0,0 +1,38 @@
+package graph
+
+import "github.com/lyh-demo/go-webapp-demo/service"
+
+// errorCodes are the codes in the extensions for the kinds of the errors of services.
+var errorCodes = map[service.ErrorKind]string{
+	service.KindValidation:       "VALIDATION_FAILED",
+	service.KindInvalidReference: "INVALID_REFERENCE",
+	service.KindNotFound:         "NOT_FOUND",
+	service.KindConflict:         "CONFLICT",
+	service.KindUnauthorized:     "UNAUTHENTICATED",
+	service.KindForbidden:        "FORBIDDEN",
+	service.KindInternal:         "INTERNAL_SERVER_ERROR",
+}
+
+// serviceError is the error of resolvers, and it has the kind and the messages of invalid fields as the extensions.
+type serviceError struct {
+	err *service.Error
+}
+
+// newServiceError converts the error returned by the services to the error of resolvers.
+func newServiceError(err error) error {
+	return &serviceError{err: service.AsError(err)}
+}
+
+// Error returns the error message.
+func (e *serviceError) Error() string {
+	return e.err.Detail
+}
+
+// Extensions returns the error code and the messages of invalid fields.
+func (e *serviceError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": errorCodes[e.err.Kind]}
+	if len(e.err.Fields) > 0 {
+		extensions["fields"] = e.err.Fields
+	}
+	return extensions
+}
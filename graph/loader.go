@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"sync"
+)
+
+// pageKey identifies the page requested by the books field of categories and formats.
+type pageKey struct {
+	page int
+	size int
+}
+
+// batchFunc fetches a page of books per given ID by one query.
+type batchFunc func(ids []uint, page int, size int) (*[]model.Book, error)
+
+// bookLoader collects the IDs requested by the resolvers of the same depth, and fetches the books of them at once.
+// It avoids the N+1 queries when listing categories or formats together with their books.
+// A loader lives only during the execution of one request.
+type bookLoader struct {
+	mu      sync.Mutex
+	fetch   batchFunc
+	group   func(book *model.Book) uint
+	pending map[pageKey][]uint
+	results map[pageKey]map[uint][]model.Book
+}
+
+func newBookLoader(fetch batchFunc, group func(book *model.Book) uint) *bookLoader {
+	return &bookLoader{
+		fetch:   fetch,
+		group:   group,
+		pending: make(map[pageKey][]uint),
+		results: make(map[pageKey]map[uint][]model.Book),
+	}
+}
+
+// Load registers the given ID and returns the thunk which is resolved after all resolvers of the depth have run.
+func (l *bookLoader) Load(id uint, page int, size int) func() (interface{}, error) {
+	key := pageKey{page: page, size: size}
+	l.mu.Lock()
+	l.pending[key] = append(l.pending[key], id)
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if ids := l.pending[key]; len(ids) > 0 {
+			delete(l.pending, key)
+			books, err := l.fetch(ids, page, size)
+			if err != nil {
+				return nil, err
+			}
+			if l.results[key] == nil {
+				l.results[key] = make(map[uint][]model.Book)
+			}
+			for _, i := range ids {
+				if _, ok := l.results[key][i]; !ok {
+					l.results[key][i] = []model.Book{}
+				}
+			}
+			for i := range *books {
+				g := l.group(&(*books)[i])
+				l.results[key][g] = append(l.results[key][g], (*books)[i])
+			}
+		}
+		return l.results[key][id], nil
+	}
+}
@@ -0,0 +1,484 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPageSize    = 20
+	defaultMaxPageSize = 100
+	defaultMaxDepth    = 8
+)
+
+// Schema executes GraphQL queries and mutations over books, categories, formats and the logged-in account.
+type Schema interface {
+	Execute(c echo.Context, request *dto.GraphQLDto) *graphql.Result
+	OperationType(request *dto.GraphQLDto) string
+}
+
+type schema struct {
+	container    container.Container
+	schema       graphql.Schema
+	books        service.BookService
+	categories   service.CategoryService
+	formats      service.FormatService
+	accounts     service.AccountService
+	dummyAccount *model.Account
+}
+
+// NewSchema is constructor.
+func NewSchema(container container.Container) (Schema, error) {
+	s := &schema{
+		container:    container,
+		books:        service.NewBookService(container),
+		categories:   service.NewCategoryService(container),
+		formats:      service.NewFormatService(container),
+		accounts:     service.NewAccountService(container),
+		dummyAccount: model.NewAccountWithPlainPassword("test", "test", 1),
+	}
+
+	var err error
+	if s.schema, err = s.build(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Execute runs the given request in the context of the current http request.
+// The request which selects the fields too deep is rejected without being executed.
+func (s *schema) Execute(c echo.Context, request *dto.GraphQLDto) *graphql.Result {
+	if err := s.checkDepth(request); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{*err}}
+	}
+	return graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        newContext(c, s.books),
+	})
+}
+
+// OperationType returns the type of the operation which the request executes, such as query and mutation.
+// The operation is chosen by the operation name as well as Execute. It returns the empty string if the request
+// can't be parsed or the operation can't be chosen, and then Execute executes nothing and returns the errors.
+func (s *schema) OperationType(request *dto.GraphQLDto) string {
+	doc, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return ""
+	}
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if request.OperationName == "" {
+			if operation != nil {
+				return ""
+			}
+			operation = op
+		} else if op.GetName() != nil && op.GetName().Value == request.OperationName {
+			operation = op
+		}
+	}
+	if operation == nil {
+		return ""
+	}
+	return operation.GetOperation()
+}
+
+func (s *schema) build() (graphql.Schema, error) {
+	authorityType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Authority",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	accountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Account",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"authority": &graphql.Field{Type: authorityType},
+		},
+	})
+
+	var bookType *graphql.Object
+	pageArgs := graphql.FieldConfigArgument{
+		"page": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		"size": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+	}
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"books": &graphql.Field{
+					Type: graphql.NewList(bookType),
+					Args: pageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						category := p.Source.(model.Category)
+						page, size := s.pageArguments(p)
+						return loader(p.Context, categoryLoaderKey).Load(category.ID, page, size), nil
+					},
+				},
+			}
+		}),
+	})
+
+	formatType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Format",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"books": &graphql.Field{
+					Type: graphql.NewList(bookType),
+					Args: pageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						format := p.Source.(model.Format)
+						page, size := s.pageArguments(p)
+						return loader(p.Context, formatLoaderKey).Load(format.ID, page, size), nil
+					},
+				},
+			}
+		}),
+	})
+
+	bookType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Book",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"isbn":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"categoryId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"formatId":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if c := asBook(p.Source).Category; c != nil {
+						return *c, nil
+					}
+					return nil, nil
+				},
+			},
+			"format": &graphql.Field{
+				Type: formatType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := asBook(p.Source).Format; f != nil {
+						return *f, nil
+					}
+					return nil, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return asBook(p.Source).CreatedAt.Format(time.RFC3339), nil
+				},
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return asBook(p.Source).UpdatedAt.Format(time.RFC3339), nil
+				},
+			},
+		},
+	})
+
+	bookPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "BookPage",
+		Fields: graphql.Fields{
+			"content": &graphql.Field{
+				Type: graphql.NewList(bookType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if content := p.Source.(*model.Page).Content; content != nil {
+						return *content, nil
+					}
+					return []model.Book{}, nil
+				},
+			},
+			"page":             &graphql.Field{Type: graphql.Int},
+			"size":             &graphql.Field{Type: graphql.Int},
+			"numberOfElements": &graphql.Field{Type: graphql.Int},
+			"totalElements":    &graphql.Field{Type: graphql.Int},
+			"totalPages":       &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	bookInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "BookInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"isbn":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"categoryId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"formatId":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"book": &graphql.Field{
+				Type: bookType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					book, err := s.books.FindByID(idArgument(p))
+					if err != nil {
+						return nil, nil
+					}
+					return book, nil
+				},
+			},
+			"books": &graphql.Field{
+				Type: bookPageType,
+				Args: graphql.FieldConfigArgument{
+					"query":      &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"categoryId": &graphql.ArgumentConfig{Type: graphql.Int},
+					"formatId":   &graphql.ArgumentConfig{Type: graphql.Int},
+					"page":       &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"size":       &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					page, size := s.pageArguments(p)
+					result, err := s.books.FindBooks(p.Args["query"].(string), optionalID(p, "categoryId"),
+						optionalID(p, "formatId"), strconv.Itoa(page), strconv.Itoa(size))
+					if err != nil {
+						return nil, newServiceError(err)
+					}
+					return result, nil
+				},
+			},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionCategoryRead); err != nil {
+						return nil, newServiceError(err)
+					}
+					category, err := s.categories.FindByID(idArgument(p))
+					if err != nil {
+						return nil, nil
+					}
+					return *category, nil
+				},
+			},
+			"categories": &graphql.Field{
+				Type: graphql.NewList(categoryType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionCategoryRead); err != nil {
+						return nil, newServiceError(err)
+					}
+					if categories := s.categories.FindAllCategories(); categories != nil {
+						return *categories, nil
+					}
+					return []model.Category{}, nil
+				},
+			},
+			"format": &graphql.Field{
+				Type: formatType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionFormatRead); err != nil {
+						return nil, newServiceError(err)
+					}
+					format, err := s.formats.FindByID(idArgument(p))
+					if err != nil {
+						return nil, nil
+					}
+					return *format, nil
+				},
+			},
+			"formats": &graphql.Field{
+				Type: graphql.NewList(formatType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionFormatRead); err != nil {
+						return nil, newServiceError(err)
+					}
+					if formats := s.formats.FindAllFormats(); formats != nil {
+						return *formats, nil
+					}
+					return []model.Format{}, nil
+				},
+			},
+			"me": &graphql.Field{
+				Type: accountType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if !s.container.GetConfig().Extension.SecurityEnabled {
+						return s.dummyAccount, nil
+					}
+					if account := s.container.GetSession().GetAccount(echoContext(p.Context)); account != nil {
+						return account, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	bookInputArgs := graphql.FieldConfigArgument{
+		"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInputType)},
+	}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createBook": &graphql.Field{
+				Type: bookType,
+				Args: bookInputArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionBookWrite); err != nil {
+						return nil, newServiceError(err)
+					}
+					book, err := s.books.CreateBook(s.bookDto(p))
+					if err != nil {
+						return nil, newServiceError(err)
+					}
+					return book, nil
+				},
+			},
+			"updateBook": &graphql.Field{
+				Type: bookType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionBookWrite); err != nil {
+						return nil, newServiceError(err)
+					}
+					book, err := s.books.UpdateBook(s.bookDto(p), idArgument(p))
+					if err != nil {
+						return nil, newServiceError(err)
+					}
+					return book, nil
+				},
+			},
+			"deleteBook": &graphql.Field{
+				Type: bookType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.authorize(p, model.PermissionBookWrite); err != nil {
+						return nil, newServiceError(err)
+					}
+					book, err := s.books.DeleteBook(idArgument(p))
+					if err != nil {
+						return nil, newServiceError(err)
+					}
+					return book, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// authorize returns the error if the logged-in account doesn't have the given permission.
+// The route of GraphQL only requires the permission to read the books, so the mutations check the permission
+// to write, and the queries of the categories and the formats check the permissions to read them.
+func (s *schema) authorize(p graphql.ResolveParams, permission string) error {
+	if !s.container.GetConfig().Extension.SecurityEnabled {
+		return nil
+	}
+	account := s.container.GetSession().GetAccount(echoContext(p.Context))
+	if account == nil {
+		return service.NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	return s.accounts.Authorize(account, permission)
+}
+
+// bookDto converts the input argument to the DTO, so that mutations are validated same as the REST API.
+func (s *schema) bookDto(p graphql.ResolveParams) *dto.BookDto {
+	input := p.Args["input"].(map[string]interface{})
+	bookDto := dto.NewBookDto(s.container.GetMessages(i18n.GetLocale(echoContext(p.Context))))
+	bookDto.Title = input["title"].(string)
+	bookDto.Isbn = input["isbn"].(string)
+	bookDto.CategoryID = uint(input["categoryId"].(int))
+	bookDto.FormatID = uint(input["formatId"].(int))
+	return bookDto
+}
+
+func asBook(source interface{}) *model.Book {
+	switch b := source.(type) {
+	case *model.Book:
+		return b
+	case model.Book:
+		return &b
+	}
+	return &model.Book{}
+}
+
+func idArgument(p graphql.ResolveParams) string {
+	return strconv.Itoa(p.Args["id"].(int))
+}
+
+func optionalID(p graphql.ResolveParams, name string) string {
+	if id, ok := p.Args[name].(int); ok {
+		return strconv.Itoa(id)
+	}
+	return ""
+}
+
+// checkDepth returns the error if the request selects the fields deeper than the max depth.
+// The request which can't be parsed is left to the execution, which returns the syntax error.
+func (s *schema) checkDepth(request *dto.GraphQLDto) *gqlerrors.FormattedError {
+	doc, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return nil
+	}
+	limit := s.container.GetConfig().GraphQL.MaxDepth
+	if limit <= 0 {
+		limit = defaultMaxDepth
+	}
+	if queryDepth(doc) <= limit {
+		return nil
+	}
+	e := &serviceError{err: service.NewValidationError(fmt.Sprintf("The query is too deep, the max depth is %d",
+		limit), nil)}
+	return &gqlerrors.FormattedError{Message: e.Error(), Locations: []location.SourceLocation{},
+		Extensions: e.Extensions()}
+}
+
+// pageArguments returns the page and the size of the arguments. The size is limited to the max page size,
+// so that a query can't fetch too many books at once.
+func (s *schema) pageArguments(p graphql.ResolveParams) (int, int) {
+	page, _ := p.Args["page"].(int)
+	size, _ := p.Args["size"].(int)
+	if page < 0 {
+		page = 0
+	}
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	limit := s.container.GetConfig().GraphQL.MaxPageSize
+	if limit <= 0 {
+		limit = defaultMaxPageSize
+	}
+	if size > limit {
+		size = limit
+	}
+	return page, size
+}
@@ -0,0 +1,130 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/controller"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// result is the response of the GraphQL API.
+type result struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func prepareForGraphQLTest() (*echo.Echo, container.Container) {
+	router, container := test.PrepareForControllerTest(true)
+	container.GetConfig().Security.AuthPath = []string{"/api/.*"}
+	graphql := controller.NewGraphQLController(container)
+	router.POST(config.APIGraphQL, func(c echo.Context) error { return graphql.Query(c) })
+	return router, container
+}
+
+// accessToken creates the account of the user authority which has given permissions, and returns its access token.
+func accessToken(t *testing.T, container container.Container, permissions ...string) string {
+	rep := container.GetRepository()
+	rep.Exec("delete from authority_permission where authority_id = ?", 2)
+	for _, permission := range permissions {
+		rep.Create(&model.AuthorityPermission{AuthorityID: 2, Permission: permission})
+	}
+	_, err := model.NewAccountWithPlainPassword("reader", "reader", 2).Create(rep)
+	assert.NoError(t, err)
+
+	response, err := service.NewTokenService(container).IssueToken(
+		&dto.LoginDto{UserName: "reader", Password: "reader"}, "192.0.2.1")
+	assert.NoError(t, err)
+	return response.AccessToken
+}
+
+func query(router *echo.Echo, token string, q string) *result {
+	req := test.NewJSONRequest(http.MethodPost, config.APIGraphQL, &dto.GraphQLDto{Query: q})
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	r := &result{}
+	_ = json.Unmarshal(rec.Body.Bytes(), r)
+	return r
+}
+
+func TestQuery_CategoriesWithoutPermission(t *testing.T) {
+	router, container := prepareForGraphQLTest()
+	token := accessToken(t, container, model.PermissionBookRead)
+
+	for _, q := range []string{"{ categories { id } }", "{ category(id: 1) { id } }", "{ formats { id } }",
+		"{ format(id: 1) { id } }"} {
+		r := query(router, token, q)
+
+		assert.Nil(t, r.Data[strings.Fields(q)[1]], q)
+		if assert.Len(t, r.Errors, 1, q) {
+			assert.Equal(t, "FORBIDDEN", r.Errors[0].Extensions["code"], q)
+		}
+	}
+}
+
+func TestQuery_CategoriesWithPermission(t *testing.T) {
+	router, container := prepareForGraphQLTest()
+	token := accessToken(t, container, model.PermissionBookRead, model.PermissionCategoryRead,
+		model.PermissionFormatRead)
+
+	r := query(router, token, "{ categories { id } formats { id } }")
+
+	assert.Empty(t, r.Errors)
+	assert.Len(t, r.Data["categories"], 3)
+	assert.Len(t, r.Data["formats"], 2)
+}
+
+func TestQuery_MaxPageSize(t *testing.T) {
+	router, container := prepareForGraphQLTest()
+	container.GetConfig().GraphQL.MaxPageSize = 2
+	for _, title := range []string{"Book 1", "Book 2", "Book 3"} {
+		_, _ = model.NewBook(title, "1234567890", 1, 1).Create(container.GetRepository())
+	}
+	token := accessToken(t, container, model.PermissionBookRead)
+
+	r := query(router, token, "{ books(size: 1000) { size numberOfElements } }")
+
+	assert.Empty(t, r.Errors)
+	books := r.Data["books"].(map[string]interface{})
+	assert.Equal(t, float64(2), books["size"])
+	assert.Equal(t, float64(2), books["numberOfElements"])
+}
+
+func TestQuery_MaxDepth(t *testing.T) {
+	router, container := prepareForGraphQLTest()
+	container.GetConfig().GraphQL.MaxDepth = 3
+	token := accessToken(t, container, model.PermissionBookRead)
+
+	r := query(router, token, "{ books { content { id } } }")
+	assert.Empty(t, r.Errors)
+
+	for _, q := range []string{
+		"{ books { content { category { id } } } }",
+		"query { books { ...page } } fragment page on BookPage { content { category { id } } }",
+	} {
+		r = query(router, token, q)
+
+		assert.Nil(t, r.Data, q)
+		if assert.Len(t, r.Errors, 1, q) {
+			assert.Equal(t, "VALIDATION_FAILED", r.Errors[0].Extensions["code"], q)
+		}
+	}
+
+	// the fields of the introspection aren't counted.
+	r = query(router, token, "{ __schema { types { fields { type { ofType { name } } } } } }")
+	assert.Empty(t, r.Errors)
+}
@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/language"
+	"sort"
+)
+
+// DefaultLocale is the language of the default bundle, messages.properties.
+const DefaultLocale = "en"
+
+// LocaleKey is the key of the locale of the current request in the echo context.
+const LocaleKey = "locale"
+
+// Bundles has the messages per locale, and it chooses the locale which fits the preferences of clients.
+type Bundles interface {
+	GetMessages(locale string) map[string]string
+	Match(preferences ...string) (string, bool)
+	Locales() []string
+}
+
+type bundles struct {
+	locales  []string
+	messages map[string]map[string]string
+	matcher  language.Matcher
+}
+
+// NewBundles is constructor. The bundles of other locales are filled up with the default messages,
+// so that the keys which aren't translated yet are shown in the default language.
+func NewBundles(defaultMessages map[string]string, localized map[string]map[string]string) Bundles {
+	b := &bundles{
+		locales:  []string{DefaultLocale},
+		messages: map[string]map[string]string{DefaultLocale: defaultMessages},
+	}
+	tags := []language.Tag{language.Make(DefaultLocale)}
+
+	locales := make([]string, 0, len(localized))
+	for locale := range localized {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	for _, locale := range locales {
+		messages := localized[locale]
+		tag, err := language.Parse(locale)
+		if err != nil || locale == DefaultLocale {
+			continue
+		}
+		merged := make(map[string]string, len(defaultMessages))
+		for key, message := range defaultMessages {
+			merged[key] = message
+		}
+		for key, message := range messages {
+			merged[key] = message
+		}
+		b.locales = append(b.locales, tag.String())
+		b.messages[tag.String()] = merged
+		tags = append(tags, tag)
+	}
+	b.matcher = language.NewMatcher(tags)
+	return b
+}
+
+// GetMessages returns the messages of the given locale, and the default messages if the locale isn't supported.
+func (b *bundles) GetMessages(locale string) map[string]string {
+	if messages, ok := b.messages[locale]; ok {
+		return messages
+	}
+	if matched, ok := b.Match(locale); ok {
+		return b.messages[matched]
+	}
+	return b.messages[DefaultLocale]
+}
+
+// Match returns the supported locale which fits the first preference having a supported language.
+// A preference is a language tag or the value of Accept-Language header.
+func (b *bundles) Match(preferences ...string) (string, bool) {
+	for _, preference := range preferences {
+		if preference == "" {
+			continue
+		}
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		if _, index, confidence := b.matcher.Match(tags...); confidence != language.No {
+			return b.locales[index], true
+		}
+	}
+	return DefaultLocale, false
+}
+
+// Locales returns the supported locales, and the first is the default locale.
+func (b *bundles) Locales() []string {
+	return b.locales
+}
+
+// GetLocale returns the locale of the current request, which is chosen by the locale middleware.
+func GetLocale(c echo.Context) string {
+	if locale, ok := c.Get(LocaleKey).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,202 @@
+package label
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"html"
+	"image"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// canvas draws black rectangles and text on a page. The coordinates are millimeters from the top left,
+// and the y of text is the baseline.
+type canvas interface {
+	rect(x, y, w, h float64)
+	text(x, y, size float64, s string)
+}
+
+// svgCanvas draws an SVG document. The rectangles are collected into a path to keep the document small.
+type svgCanvas struct {
+	width, height float64
+	path          strings.Builder
+	texts         strings.Builder
+}
+
+func newSVGCanvas(width, height float64) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (s *svgCanvas) rect(x, y, w, h float64) {
+	fmt.Fprintf(&s.path, "M%s %sh%sv%sh-%sz", num(x), num(y), num(w), num(h), num(w))
+}
+
+func (s *svgCanvas) text(x, y, size float64, text string) {
+	fmt.Fprintf(&s.texts, `<text x="%s" y="%s" font-size="%s">%s</text>`,
+		num(x), num(y), num(size), html.EscapeString(text))
+}
+
+func (s *svgCanvas) bytes() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %s %s">`,
+		num(s.width), num(s.height), num(s.width), num(s.height))
+	buf.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+	fmt.Fprintf(&buf, `<path fill="#000" d="%s"/>`, s.path.String())
+	fmt.Fprintf(&buf, `<g font-family="Helvetica, Arial, sans-serif" fill="#000">%s</g>`, s.texts.String())
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// pngCanvas draws a raster image with the given pixels per millimeter.
+// The text is drawn by the bitmap font scaled to the size, as no font files are bundled.
+type pngCanvas struct {
+	img   *image.Gray
+	scale float64
+}
+
+func newPNGCanvas(width, height, scale float64) *pngCanvas {
+	img := image.NewGray(image.Rect(0, 0, int(math.Ceil(width*scale)), int(math.Ceil(height*scale))))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return &pngCanvas{img: img, scale: scale}
+}
+
+func (p *pngCanvas) rect(x, y, w, h float64) {
+	r := image.Rect(p.px(x), p.px(y), p.px(x+w), p.px(y+h))
+	draw.Draw(p.img, r, image.Black, image.Point{}, draw.Src)
+}
+
+func (p *pngCanvas) text(x, y, size float64, text string) {
+	face := basicfont.Face7x13
+	metrics := face.Metrics()
+	height := (metrics.Ascent + metrics.Descent).Ceil()
+	width := font.MeasureString(face, text).Ceil()
+	if width == 0 {
+		return
+	}
+
+	src := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	d := &font.Drawer{Dst: src, Src: image.Black, Face: face, Dot: fixed.P(0, metrics.Ascent.Ceil())}
+	d.DrawString(text)
+
+	// the size is the height of the em box, which the bitmap font fills with its ascent and descent.
+	k := size * p.scale / float64(height)
+	top := y*p.scale - float64(metrics.Ascent.Ceil())*k
+	dst := image.Rect(p.px(x), int(math.Round(top)),
+		p.px(x)+int(math.Round(float64(width)*k)), int(math.Round(top+float64(height)*k)))
+	draw.NearestNeighbor.Scale(p.img, dst, src, src.Bounds(), draw.Over, nil)
+}
+
+func (p *pngCanvas) px(mm float64) int {
+	return int(math.Round(mm * p.scale))
+}
+
+func (p *pngCanvas) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, p.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfCanvas draws a page of PDF with the standard Helvetica font, so no fonts are embedded.
+type pdfCanvas struct {
+	height  float64
+	content bytes.Buffer
+}
+
+// pointsPerMM converts millimeters to the points of PDF.
+const pointsPerMM = 72 / 25.4
+
+func newPDFCanvas(height float64) *pdfCanvas {
+	return &pdfCanvas{height: height}
+}
+
+func (p *pdfCanvas) rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n",
+		num(x*pointsPerMM), num((p.height-y-h)*pointsPerMM), num(w*pointsPerMM), num(h*pointsPerMM))
+}
+
+func (p *pdfCanvas) text(x, y, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+		num(size*pointsPerMM), num(x*pointsPerMM), num((p.height-y)*pointsPerMM), pdfString(text))
+}
+
+// writePDF writes the PDF document which has the pages in order.
+func writePDF(width, height float64, pages []*pdfCanvas) ([]byte, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			num(width*pointsPerMM), num(height*pointsPerMM), 5+i*2))
+
+		var content bytes.Buffer
+		w := zlib.NewWriter(&content)
+		if _, err := w.Write(page.content.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes(), nil
+}
+
+// pdfString escapes the text for a string literal of PDF. The characters out of Latin-1 are replaced,
+// because the standard font only has the glyphs of WinAnsiEncoding.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0xff:
+			b.WriteByte('?')
+		case r > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// num formats the number with the precision enough for printing.
+func num(f float64) string {
+	s := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", f), "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,249 @@
+package label
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"image/color"
+	"strings"
+	"unicode"
+)
+
+// Symbologies of the barcode.
+const (
+	// Code128 encodes any ASCII text such as the ISBN with hyphens.
+	Code128 = "code128"
+	// EAN13 encodes 13 digits such as ISBN-13, which the point of sale scanners read.
+	EAN13 = "ean13"
+)
+
+// Formats of the rendered labels.
+const (
+	SVG = "svg"
+	PNG = "png"
+	PDF = "pdf"
+)
+
+// Media types of the formats.
+const (
+	SVGType = "image/svg+xml"
+	PNGType = "image/png"
+	PDFType = "application/pdf"
+)
+
+// The size of a label and the layout of the A4 sheet in millimeters, which fits 3 x 8 common label sheets.
+const (
+	Width        = 70.0
+	Height       = 37.0
+	SheetWidth   = 210.0
+	SheetHeight  = 297.0
+	SheetColumns = 3
+	SheetRows    = 8
+	// PerSheet is the number of labels on a sheet.
+	PerSheet = SheetColumns * SheetRows
+)
+
+const (
+	padding       = 3.0
+	qrSize        = 27.0
+	titleSize     = 3.2
+	titleLines    = 2
+	barcodeTop    = 13.0
+	barcodeHeight = 15.0
+	codeSize      = 2.8
+	// charWidth is the average width of a character per the font size, to fit the title in the width.
+	charWidth = 0.55
+	// pngScale is the pixels per millimeter of PNG, about 300 dpi.
+	pngScale = 12.0
+)
+
+// ErrUnknownSymbology is returned when the symbology isn't supported.
+var ErrUnknownSymbology = errors.New("unknown symbology")
+
+// Label represents a label of a book, which has the title, the barcode of the code and the QR code of the URL.
+type Label struct {
+	Title   string
+	Code    string
+	URL     string
+	barcode barcode.Barcode
+	qrCode  barcode.Barcode
+}
+
+// NewLabel is constructor. The barcode is EAN-13 if the symbology is empty and the code is a valid ISBN-13,
+// otherwise Code 128.
+func NewLabel(title string, code string, url string, symbology string) (*Label, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+
+	var bc barcode.Barcode
+	var err error
+	switch symbology {
+	case EAN13:
+		if bc, err = ean.Encode(digits); err == nil && len(digits) != 13 {
+			err = errors.New("EAN-13 requires 13 digits")
+		}
+	case Code128:
+		bc, err = code128.Encode(code)
+	case "":
+		if bc, err = ean.Encode(digits); err != nil || len(digits) != 13 {
+			bc, err = code128.Encode(code)
+		}
+	default:
+		return nil, ErrUnknownSymbology
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %q: %w", code, err)
+	}
+
+	qrCode, err := qr.Encode(url, qr.M, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %q: %w", url, err)
+	}
+	return &Label{Title: title, Code: code, URL: url, barcode: bc, qrCode: qrCode}, nil
+}
+
+// RenderSVG returns the label as an SVG document.
+func (l *Label) RenderSVG() []byte {
+	c := newSVGCanvas(Width, Height)
+	l.draw(c, 0, 0)
+	return c.bytes()
+}
+
+// RenderPNG returns the label as a PNG image of about 300 dpi.
+func (l *Label) RenderPNG() ([]byte, error) {
+	c := newPNGCanvas(Width, Height, pngScale)
+	l.draw(c, 0, 0)
+	return c.bytes()
+}
+
+// RenderSheetSVG returns an A4 sheet of the labels as an SVG document.
+// The first positions are skipped to reuse a partially used sheet.
+func RenderSheetSVG(labels []*Label, skip int) ([]byte, error) {
+	if skip+len(labels) > PerSheet {
+		return nil, fmt.Errorf("an SVG sheet has up to %d labels", PerSheet)
+	}
+	c := newSVGCanvas(SheetWidth, SheetHeight)
+	drawSheet(c, labels, skip)
+	return c.bytes(), nil
+}
+
+// RenderSheetPDF returns the A4 sheets of the labels as a PDF document, which has as many pages as needed.
+// The first positions of the first page are skipped to reuse a partially used sheet.
+func RenderSheetPDF(labels []*Label, skip int) ([]byte, error) {
+	var pages []*pdfCanvas
+	for start := 0; start == 0 || start < len(labels); {
+		end := start + PerSheet - skip
+		if end > len(labels) {
+			end = len(labels)
+		}
+		page := newPDFCanvas(SheetHeight)
+		drawSheet(page, labels[start:end], skip)
+		pages = append(pages, page)
+		start, skip = end, 0
+	}
+	return writePDF(SheetWidth, SheetHeight, pages)
+}
+
+// drawSheet draws the labels from the top left to the right, centering the grid on the sheet.
+func drawSheet(c canvas, labels []*Label, skip int) {
+	left := (SheetWidth - Width*SheetColumns) / 2
+	top := (SheetHeight - Height*SheetRows) / 2
+	for i, l := range labels {
+		position := skip + i
+		l.draw(c, left+float64(position%SheetColumns)*Width, top+float64(position/SheetColumns)*Height)
+	}
+}
+
+// draw draws the label at the given position. The title and the barcode are on the left,
+// and the QR code is on the right.
+func (l *Label) draw(c canvas, x float64, y float64) {
+	textWidth := Width - padding*3 - qrSize
+	for i, line := range wrap(l.Title, textWidth, titleSize, titleLines) {
+		c.text(x+padding, y+padding+titleSize*(float64(i)+1), titleSize, line)
+	}
+
+	drawModules(c, l.barcode, x+padding, y+barcodeTop, textWidth, barcodeHeight)
+	c.text(x+padding, y+barcodeTop+barcodeHeight+codeSize+0.5, codeSize, l.Code)
+
+	drawModules(c, l.qrCode, x+Width-padding-qrSize, y+(Height-qrSize)/2, qrSize, qrSize)
+}
+
+// drawModules draws the dark modules of the barcode, scaled into the given box.
+// The modules in a row are joined into a rectangle, and the rows of 1D barcodes are stretched to the height.
+func drawModules(c canvas, code barcode.Barcode, x float64, y float64, width float64, height float64) {
+	bounds := code.Bounds()
+	columns, rows := bounds.Dx(), bounds.Dy()
+	moduleWidth := width / float64(columns)
+	moduleHeight := height / float64(rows)
+
+	for row := 0; row < rows; row++ {
+		start := -1
+		for column := 0; column <= columns; column++ {
+			dark := column < columns && isDark(code.At(bounds.Min.X+column, bounds.Min.Y+row))
+			if dark && start < 0 {
+				start = column
+			} else if !dark && start >= 0 {
+				c.rect(x+float64(start)*moduleWidth, y+float64(row)*moduleHeight,
+					float64(column-start)*moduleWidth, moduleHeight)
+				start = -1
+			}
+		}
+	}
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 0x8000*3
+}
+
+// wrap splits the text into the lines which fit the width, and the last line is truncated with an ellipsis.
+func wrap(text string, width float64, size float64, maxLines int) []string {
+	limit := int(width / (size * charWidth))
+	words := strings.FieldsFunc(text, unicode.IsSpace)
+
+	var lines []string
+	line := ""
+	for i := 0; i < len(words); i++ {
+		word := []rune(words[i])
+		candidate := string(word)
+		if line != "" {
+			candidate = line + " " + candidate
+		}
+		if len([]rune(candidate)) <= limit {
+			line = candidate
+			continue
+		}
+		if line == "" {
+			// the word longer than a line is split.
+			line, words[i] = string(word[:limit]), string(word[limit:])
+			i--
+		} else {
+			i--
+		}
+		lines = append(lines, line)
+		line = ""
+		if len(lines) == maxLines {
+			return ellipsis(lines, limit)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func ellipsis(lines []string, limit int) []string {
+	last := []rune(lines[len(lines)-1])
+	if len(last) > limit-3 {
+		last = last[:limit-3]
+	}
+	lines[len(lines)-1] = strings.TrimRightFunc(string(last), unicode.IsSpace) + "..."
+	return lines
+}
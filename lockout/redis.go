@@ -0,0 +1,150 @@
+package lockout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"sort"
+	"time"
+)
+
+const (
+	keyPrefix   = "login_attempt:"
+	idleTimeout = 240 * time.Second
+	// maxRetries is the number of the retries of the update which conflicts with the others.
+	maxRetries = 10
+	scanCount  = 100
+)
+
+// redisStore persists the failed login attempts as the JSON values which expire after the TTL,
+// so the instances of this application share them.
+type redisStore struct {
+	pool *redis.Pool
+}
+
+func newRedisStore(conf *config.Config) *redisStore {
+	address := fmt.Sprintf("%s:%s", conf.Redis.Host, conf.Redis.Port)
+	return &redisStore{pool: &redis.Pool{
+		MaxIdle:     conf.Redis.ConnectionPoolSize,
+		IdleTimeout: idleTimeout,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", address)
+		},
+	}}
+}
+
+// Find returns the failed login attempts of given username or remote IP address.
+// It returns the attempts without failures if there are no attempts.
+func (s *redisStore) Find(kind string, value string) (*model.LoginAttempt, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+	return get(conn, kind, value)
+}
+
+// Update updates the failed login attempts of given username or remote IP address by the function.
+// The update is retried if the attempts are updated by another request at the same time.
+func (s *redisStore) Update(kind string, value string, ttl time.Duration,
+	update func(attempt *model.LoginAttempt)) (*model.LoginAttempt, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	key := keyPrefix + kind + ":" + value
+	seconds := int64(ttl / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	for i := 0; i < maxRetries; i++ {
+		if _, err := conn.Do("WATCH", key); err != nil {
+			return nil, err
+		}
+		attempt, err := get(conn, kind, value)
+		if err != nil {
+			return nil, err
+		}
+		update(attempt)
+		if attempt.IsLocked(time.Now()) {
+			if lock := int64(time.Until(*attempt.LockedUntil)/time.Second) + 1; lock > seconds {
+				seconds = lock
+			}
+		}
+		bytes, err := json.Marshal(attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		_ = conn.Send("MULTI")
+		_ = conn.Send("SET", key, bytes, "EX", seconds)
+		reply, err := conn.Do("EXEC")
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil {
+			return attempt, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to update %s because of the conflicts", key)
+}
+
+// Delete deletes the failed login attempts of given username or remote IP address.
+func (s *redisStore) Delete(kind string, value string) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("DEL", keyPrefix+kind+":"+value)
+	return err
+}
+
+// FindLocked returns the failed login attempts which are locked out at given time, in order of the lock time.
+func (s *redisStore) FindLocked(now time.Time) ([]model.LoginAttempt, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	result := []model.LoginAttempt{}
+	cursor := 0
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", keyPrefix+"*", "COUNT", scanCount))
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			bytes, err := redis.Bytes(conn.Do("GET", key))
+			if errors.Is(err, redis.ErrNil) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			attempt := model.LoginAttempt{}
+			if err = json.Unmarshal(bytes, &attempt); err == nil && attempt.IsLocked(now) {
+				result = append(result, attempt)
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LockedUntil.Before(*result[j].LockedUntil) })
+	return result, nil
+}
+
+// get returns the attempts of the key, or the attempts without failures if the key doesn't exist.
+func get(conn redis.Conn, kind string, value string) (*model.LoginAttempt, error) {
+	bytes, err := redis.Bytes(conn.Do("GET", keyPrefix+kind+":"+value))
+	if errors.Is(err, redis.ErrNil) {
+		return model.NewLoginAttempt(kind, value), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	attempt := &model.LoginAttempt{}
+	if err = json.Unmarshal(bytes, attempt); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
@@ -0,0 +1,86 @@
+package lockout
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/logger"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"sync"
+	"time"
+)
+
+// Store represents an interface for persisting the failed login attempts of the usernames and the remote IP
+// addresses. The attempts which haven't failed within the TTL are forgotten unless they are locked out.
+type Store interface {
+	Find(kind string, value string) (*model.LoginAttempt, error)
+	Update(kind string, value string, ttl time.Duration,
+		update func(attempt *model.LoginAttempt)) (*model.LoginAttempt, error)
+	Delete(kind string, value string) error
+	FindLocked(now time.Time) ([]model.LoginAttempt, error)
+}
+
+// NewStore is constructor. It returns the store on Redis if Redis is enabled, and the store on the database otherwise.
+func NewStore(logger logger.Logger, conf *config.Config, rep repository.Repository) Store {
+	if conf.Redis.Enabled {
+		logger.GetZapLogger().Infof("use redis for the failed login attempts")
+		return newRedisStore(conf)
+	}
+	logger.GetZapLogger().Infof("use the database for the failed login attempts")
+	return &databaseStore{rep: rep}
+}
+
+// databaseStore persists the failed login attempts in the login_attempt table.
+// The updates are serialized in this process, so the attempts from concurrent requests aren't lost.
+type databaseStore struct {
+	rep repository.Repository
+	mu  sync.Mutex
+}
+
+// Find returns the failed login attempts of given username or remote IP address.
+// It returns the attempts without failures if there are no attempts.
+func (s *databaseStore) Find(kind string, value string) (*model.LoginAttempt, error) {
+	la := model.LoginAttempt{}
+	if attempt, err := la.FindByKindAndValue(s.rep, kind, value).Take(); err == nil {
+		return attempt, nil
+	}
+	return model.NewLoginAttempt(kind, value), nil
+}
+
+// Update updates the failed login attempts of given username or remote IP address by the function,
+// and deletes the attempts of the others which have expired.
+func (s *databaseStore) Update(kind string, value string, ttl time.Duration,
+	update func(attempt *model.LoginAttempt)) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result *model.LoginAttempt
+	err := s.rep.Transaction(func(txRep repository.Repository) error {
+		la := model.LoginAttempt{}
+		attempt, err := la.FindByKindAndValue(txRep, kind, value).Take()
+		if err != nil {
+			attempt = model.NewLoginAttempt(kind, value)
+		}
+		update(attempt)
+		if result, err = attempt.Save(txRep); err != nil {
+			return err
+		}
+		return la.DeleteBefore(txRep, time.Now().Add(-ttl))
+	})
+	return result, err
+}
+
+// Delete deletes the failed login attempts of given username or remote IP address.
+func (s *databaseStore) Delete(kind string, value string) error {
+	la := model.LoginAttempt{}
+	return la.DeleteByKindAndValue(s.rep, kind, value)
+}
+
+// FindLocked returns the failed login attempts which are locked out at given time.
+func (s *databaseStore) FindLocked(now time.Time) ([]model.LoginAttempt, error) {
+	la := model.LoginAttempt{}
+	attempts, err := la.FindLocked(s.rep, now)
+	if err != nil {
+		return nil, err
+	}
+	return *attempts, nil
+}
@@ -2,15 +2,25 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/broker"
+	"github.com/lyh-demo/go-webapp-demo/command"
 	"github.com/lyh-demo/go-webapp-demo/config"
 	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/lockout"
 	"github.com/lyh-demo/go-webapp-demo/logger"
 	"github.com/lyh-demo/go-webapp-demo/middleware"
 	"github.com/lyh-demo/go-webapp-demo/migration"
+	"github.com/lyh-demo/go-webapp-demo/notifier"
 	"github.com/lyh-demo/go-webapp-demo/repository"
 	"github.com/lyh-demo/go-webapp-demo/router"
+	"github.com/lyh-demo/go-webapp-demo/rpc"
+	"github.com/lyh-demo/go-webapp-demo/service"
 	"github.com/lyh-demo/go-webapp-demo/session"
+	"github.com/lyh-demo/go-webapp-demo/token"
+	"github.com/lyh-demo/go-webapp-demo/webhook"
+	"os"
 )
 
 //go:embed resources/config/application.*.yml
@@ -22,7 +32,7 @@ var zapYamlFile embed.FS
 //go:embed resources/public/*
 var staticFile embed.FS
 
-//go:embed resources/config/messages.properties
+//go:embed resources/config/messages*.properties
 var propsFile embed.FS
 
 // @title go-webapp-demo API
@@ -42,11 +52,24 @@ func main() {
 	l.GetZapLogger().Infof("Loaded this configuration : application." + env + ".yml")
 
 	messages := config.LoadMessagesConfig(propsFile)
-	l.GetZapLogger().Infof("Loaded messages.properties")
+	l.GetZapLogger().Infof("Loaded the messages of the locales : %v", messages.Locales())
 
 	rep := repository.NewBookRepository(l, conf)
-	sess := session.NewSession(l, conf)
-	c := container.NewContainer(rep, sess, conf, messages, l, env)
+	sess := session.NewSession(l, conf, env)
+	b := broker.NewBroker(l, conf)
+	n := notifier.NewNotifier(l, conf)
+	k := token.NewKeySet(l, conf)
+	ls := lockout.NewStore(l, conf, rep)
+	c := container.NewContainer(rep, sess, b, n, k, ls, conf, messages, l, env)
+
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	if args := flag.Args(); len(args) > 0 {
+		status := command.Run(c, args)
+		_ = rep.Close()
+		os.Exit(status)
+	}
 
 	migration.CreateDatabase(c)
 	migration.InitMasterData(c)
@@ -56,6 +79,27 @@ func main() {
 	middleware.InitSessionMiddleware(e, c)
 	middleware.StaticContentsMiddleware(e, c, staticFile)
 
+	if conf.Grpc.Enabled {
+		server := rpc.NewServer(c)
+		go func() {
+			if err := server.Start(); err != nil {
+				l.GetZapLogger().Errorf(err.Error())
+			}
+		}()
+		defer server.Stop()
+	}
+
+	if conf.Audit.Enabled {
+		stopPurge := service.NewAuditService(c).StartPurge()
+		defer stopPurge()
+	}
+
+	if conf.Webhook.Enabled {
+		dispatcher := webhook.NewDispatcher(c)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+	}
+
 	if err := e.Start(":8080"); err != nil {
 		l.GetZapLogger().Errorf(err.Error())
 	}
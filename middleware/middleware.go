@@ -1,20 +1,41 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"embed"
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
 	echomd "github.com/labstack/echo/v4/middleware"
 	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/service"
 	"github.com/valyala/fasttemplate"
 	"io"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
+const (
+	// langParam is the query parameter to choose the locale of the messages.
+	langParam       = "lang"
+	acceptLanguage  = "Accept-Language"
+	contentLanguage = "Content-Language"
+	bearerPrefix    = "Bearer "
+)
+
+// HeaderAPIKey is the header of the personal API key, which is accepted instead of the session.
+const HeaderAPIKey = "X-API-Key"
+
+// HeaderCSRFToken is the header of the CSRF token, which is required for the state-changing requests
+// authenticated by the session.
+const HeaderCSRFToken = "X-XSRF-TOKEN"
+
 // InitLoggerMiddleware initialize a middleware for logger.
 func InitLoggerMiddleware(e *echo.Echo, container container.Container) {
+	e.Use(echomd.RequestID())
 	e.Use(RequestLoggerMiddleware(container))
 	e.Use(ActionLoggerMiddleware(container))
 }
@@ -24,8 +45,10 @@ func InitSessionMiddleware(e *echo.Echo, container container.Container) {
 	conf := container.GetConfig()
 
 	e.Use(session.Middleware(container.GetSession().GetStore()))
+	e.Use(LocaleMiddleware(container))
 	if conf.Extension.SecurityEnabled {
 		e.Use(AuthenticationMiddleware(container))
+		e.Use(CSRFMiddleware(container))
 	}
 }
 
@@ -44,6 +67,8 @@ func RequestLoggerMiddleware(container container.Container) echo.MiddlewareFunc
 				switch tag {
 				case "remote_ip":
 					return w.Write([]byte(c.RealIP()))
+				case "request_id":
+					return w.Write([]byte(res.Header().Get(echo.HeaderXRequestID)))
 				case "account_name":
 					if account := container.GetSession().GetAccount(c); account != nil {
 						return w.Write([]byte(account.Name))
@@ -102,12 +127,41 @@ func StaticContentsMiddleware(e *echo.Echo, container container.Container, stati
 	}
 }
 
+// LocaleMiddleware is the middleware which chooses the locale of the messages of the request.
+// The lang parameter comes first, and the preference of the logged-in account and Accept-Language header follow it.
+func LocaleMiddleware(container container.Container) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			preferences := []string{c.QueryParam(langParam)}
+			if account := container.GetSession().GetAccount(c); account != nil {
+				preferences = append(preferences, account.Locale)
+			}
+			preferences = append(preferences, c.Request().Header.Get(acceptLanguage))
+
+			locale, _ := container.GetBundles().Match(preferences...)
+			c.Set(i18n.LocaleKey, locale)
+			c.Response().Header().Set(contentLanguage, locale)
+			c.Response().Header().Add(echo.HeaderVary, acceptLanguage)
+			return next(c)
+		}
+	}
+}
+
 // AuthenticationMiddleware is the middleware of session authentication for echo.
+// The access token of the Authorization: Bearer header is accepted instead of the session if it is enabled,
+// and so is the API key of the X-API-Key header.
 func AuthenticationMiddleware(container container.Container) echo.MiddlewareFunc {
+	accountService := service.NewAccountService(container)
+	tokenService := service.NewTokenService(container)
+	apiKeyService := service.NewAPIKeyService(container)
+	sessionService := service.NewSessionService(container)
+	auditService := service.NewAuditService(container)
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			if !hasAuthorization(c, container) {
-				return c.JSON(http.StatusUnauthorized, false)
+			if err := authorize(c, container, accountService, tokenService, apiKeyService,
+				sessionService); err != nil {
+				recordDenial(c, auditService, nil, err)
+				return err
 			}
 			if err := next(c); err != nil {
 				c.Error(err)
@@ -117,28 +171,175 @@ func AuthenticationMiddleware(container container.Container) echo.MiddlewareFunc
 	}
 }
 
-// hasAuthorization judges whether the user has the right to access the path.
-func hasAuthorization(c echo.Context, container container.Container) bool {
+// authorize judges whether the user has logged-in to access the path.
+// It returns the unauthorized error if the user haven't logged-in or the session or the token is no longer valid.
+// The session is no longer valid if its version is old or it has been revoked.
+// The permissions of the account are checked by PermissionMiddleware of each route.
+func authorize(c echo.Context, container container.Container, accountService service.AccountService,
+	tokenService service.TokenService, apiKeyService service.APIKeyService,
+	sessionService service.SessionService) error {
 	currentPath := c.Path()
 	if equalPath(currentPath, container.GetConfig().Security.AuthPath) {
 		if equalPath(currentPath, container.GetConfig().Security.ExcludePath) {
-			return true
+			return nil
 		}
+		if accessToken, ok := bearerToken(c); ok && container.GetConfig().JWT.Enabled {
+			account, err := tokenService.Authenticate(accessToken)
+			if err != nil {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				return err
+			}
+			container.GetSession().SetRequestAccount(c, account)
+			return nil
+		}
+		if key := c.Request().Header.Get(HeaderAPIKey); key != "" {
+			account, err := apiKeyService.Authenticate(key)
+			if err != nil {
+				return err
+			}
+			container.GetSession().SetRequestAccount(c, account)
+			return nil
+		}
+
 		account := container.GetSession().GetAccount(c)
 		if account == nil {
-			return false
+			return service.NewUnauthorizedError("The current user haven't logged-in yet")
 		}
-		if account.Authority.Name == "Admin" && equalPath(currentPath, container.GetConfig().Security.AdminPath) {
-			_ = container.GetSession().Save(c)
-			return true
+		err := accountService.ValidateSession(account)
+		if err == nil {
+			err = sessionService.Validate(account, container.GetSession().GetSessionToken(c), c.RealIP())
 		}
-		if account.Authority.Name == "User" && equalPath(currentPath, container.GetConfig().Security.UserPath) {
-			_ = container.GetSession().Save(c)
-			return true
+		if err != nil {
+			_ = container.GetSession().SetAccount(c, nil)
+			_ = container.GetSession().Delete(c)
+			return err
 		}
-		return false
+		_ = container.GetSession().Save(c)
+	}
+	return nil
+}
+
+// CSRFMiddleware is the middleware which protects the requests authenticated by the session against
+// the cross-site request forgery. The requests of the methods other than GET, HEAD and OPTIONS have to send back
+// the token of the XSRF-TOKEN cookie by the X-XSRF-TOKEN header unless the path is exempted.
+// The requests authenticated by the access token or the API key aren't checked, because they don't rely on the cookie.
+// The routes of http get mustn't change the state, and the router checks that they are declared to be safe.
+func CSRFMiddleware(container container.Container) echo.MiddlewareFunc {
+	auditService := service.NewAuditService(container)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := verifyCSRFToken(c, container); err != nil {
+				recordDenial(c, auditService, container.GetSession().GetAccount(c), err)
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// verifyCSRFToken returns the forbidden error if the state-changing request authenticated by the session
+// doesn't have the CSRF token of the session. The token is issued to any request authenticated by the session.
+func verifyCSRFToken(c echo.Context, container container.Container) error {
+	sess := container.GetSession()
+	if !sess.IsSessionAuthenticated(c) {
+		return nil
+	}
+	token, err := sess.GetCSRFToken(c)
+	if err != nil {
+		container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return service.NewInternalError("Failed to issue the CSRF token", err)
+	}
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	if equalPath(c.Path(), container.GetConfig().Security.CSRFExcludePath) {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Request().Header.Get(HeaderCSRFToken)), []byte(token)) != 1 {
+		return service.NewForbiddenError("The CSRF token is missing or incorrect")
+	}
+	return nil
+}
+
+// bearerToken returns the access token of the Authorization: Bearer header.
+func bearerToken(c echo.Context) (string, bool) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix)), true
+}
+
+// PermissionMiddleware is the middleware which allows the request of the route if the logged-in account has
+// one of given permissions through its authorities. It does nothing if the security function is disabled.
+func PermissionMiddleware(container container.Container, permissions ...string) echo.MiddlewareFunc {
+	accountService := service.NewAccountService(container)
+	auditService := service.NewAuditService(container)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !container.GetConfig().Extension.SecurityEnabled {
+				return next(c)
+			}
+			account := container.GetSession().GetAccount(c)
+			if account == nil {
+				err := service.NewUnauthorizedError("The current user haven't logged-in yet")
+				recordDenial(c, auditService, nil, err)
+				return err
+			}
+			if err := accountService.Authorize(account, permissions...); err != nil {
+				recordDenial(c, auditService, account, err)
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// SessionOnlyMiddleware is the middleware which allows the request of the route only if it is authenticated
+// by the session, rather than the access token or the API key. It protects the routes changing the credentials
+// of the logged-in account, which the stolen token or key mustn't take over. It does nothing if the security function
+// is disabled.
+func SessionOnlyMiddleware(container container.Container) echo.MiddlewareFunc {
+	auditService := service.NewAuditService(container)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !container.GetConfig().Extension.SecurityEnabled {
+				return next(c)
+			}
+			sess := container.GetSession()
+			account := sess.GetAccount(c)
+			if account == nil {
+				err := service.NewUnauthorizedError("The current user haven't logged-in yet")
+				recordDenial(c, auditService, nil, err)
+				return err
+			}
+			if !sess.IsSessionAuthenticated(c) {
+				err := service.NewForbiddenError("The route can be used only by the account logged in by the session")
+				recordDenial(c, auditService, account, err)
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// recordDenial records the request denied by the unauthorized or the forbidden error to the audit log.
+// The other errors aren't the denials, so they aren't recorded.
+// The denials of the same remote IP address are recorded at most once per the denial interval.
+func recordDenial(c echo.Context, auditService service.AuditService, account *model.Account, err error) {
+	e := service.AsError(err)
+	var event string
+	switch e.Kind {
+	case service.KindUnauthorized:
+		event = model.AuditUnauthorized
+	case service.KindForbidden:
+		event = model.AuditForbidden
+	default:
+		return
 	}
-	return true
+	auditService.RecordDenial(model.NewAuditLog(event, model.AuditDenied, c.RealIP(), c.Request().UserAgent()).
+		WithActor(account).WithTarget(c.Request().Method + " " + c.Request().URL.Path).WithDetails(e.Detail))
 }
 
 // equalPath judges whether a given path contains in the path list.
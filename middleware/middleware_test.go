@@ -0,0 +1,144 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/controller"
+	"github.com/lyh-demo/go-webapp-demo/middleware"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareForCSRFTest() (*echo.Echo, container.Container) {
+	router, container := test.PrepareForControllerTest(true)
+	container.GetConfig().Security.AuthPath = []string{"/api/.*"}
+	container.GetConfig().Security.ExcludePath = []string{config.APIAccountLogin + "$"}
+	container.GetConfig().Security.CSRFExcludePath = []string{config.APIAccountLogin + "$"}
+
+	router.HTTPErrorHandler = controller.NewErrorController(container).JSONError
+	account := controller.NewAccountController(container)
+	router.POST(config.APIAccountLogin, func(c echo.Context) error { return account.Login(c) })
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	router.GET(config.APIBooks, ok)
+	router.POST(config.APIBooks, ok)
+	return router, container
+}
+
+// login logs in as the account test, and returns the cookies of the session and the CSRF token.
+func login(router *echo.Echo) []*http.Cookie {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, test.NewJSONRequest(http.MethodPost, config.APIAccountLogin,
+		map[string]string{"username": "test", "password": "test"}))
+	return rec.Result().Cookies()
+}
+
+func send(router *echo.Echo, method string, target string, cookies []*http.Cookie,
+	header map[string]string) *httptest.ResponseRecorder {
+	req := test.NewJSONRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func csrfToken(cookies []*http.Cookie) string {
+	for _, cookie := range cookies {
+		if cookie.Name == "XSRF-TOKEN" {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func TestCSRF_SessionRequest(t *testing.T) {
+	router, _ := prepareForCSRFTest()
+	cookies := login(router)
+	token := csrfToken(cookies)
+	assert.NotEmpty(t, token)
+
+	assert.Equal(t, http.StatusForbidden, send(router, http.MethodPost, config.APIBooks, cookies, nil).Code)
+	assert.Equal(t, http.StatusForbidden, send(router, http.MethodPost, config.APIBooks, cookies,
+		map[string]string{middleware.HeaderCSRFToken: "forged token"}).Code)
+	assert.Equal(t, http.StatusOK, send(router, http.MethodPost, config.APIBooks, cookies,
+		map[string]string{middleware.HeaderCSRFToken: token}).Code)
+}
+
+func TestCSRF_SafeMethod(t *testing.T) {
+	router, _ := prepareForCSRFTest()
+	cookies := login(router)
+
+	assert.Equal(t, http.StatusOK, send(router, http.MethodGet, config.APIBooks, cookies, nil).Code)
+}
+
+func TestCSRF_ExcludedPath(t *testing.T) {
+	router, _ := prepareForCSRFTest()
+	cookies := login(router)
+
+	rec := send(router, http.MethodPost, config.APIAccountLogin, cookies, nil)
+
+	assert.NotEqual(t, http.StatusForbidden, rec.Code)
+}
+
+func TestCSRF_BearerToken(t *testing.T) {
+	router, container := prepareForCSRFTest()
+	response, err := service.NewTokenService(container).IssueToken(
+		&dto.LoginDto{UserName: "test", Password: "test"}, "192.0.2.1")
+	assert.NoError(t, err)
+
+	// the request authenticated by the access token doesn't rely on the cookie, so it isn't checked.
+	rec := send(router, http.MethodPost, config.APIBooks, nil,
+		map[string]string{echo.HeaderAuthorization: "Bearer " + response.AccessToken})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCSRF_NotLoggedIn(t *testing.T) {
+	router, _ := prepareForCSRFTest()
+
+	assert.Equal(t, http.StatusUnauthorized, send(router, http.MethodPost, config.APIBooks, nil, nil).Code)
+}
+
+func prepareForSessionOnlyTest() (*echo.Echo, container.Container) {
+	router, container := prepareForCSRFTest()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	router.GET(config.APIAccountSessions, ok, middleware.SessionOnlyMiddleware(container))
+	return router, container
+}
+
+func TestSessionOnly_SessionRequest(t *testing.T) {
+	router, _ := prepareForSessionOnlyTest()
+	cookies := login(router)
+
+	assert.Equal(t, http.StatusOK, send(router, http.MethodGet, config.APIAccountSessions, cookies, nil).Code)
+}
+
+func TestSessionOnly_BearerToken(t *testing.T) {
+	router, container := prepareForSessionOnlyTest()
+	response, err := service.NewTokenService(container).IssueToken(
+		&dto.LoginDto{UserName: "test", Password: "test"}, "192.0.2.1")
+	assert.NoError(t, err)
+
+	rec := send(router, http.MethodGet, config.APIAccountSessions, nil,
+		map[string]string{echo.HeaderAuthorization: "Bearer " + response.AccessToken})
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestSessionOnly_NotLoggedIn(t *testing.T) {
+	router, _ := prepareForSessionOnlyTest()
+
+	assert.Equal(t, http.StatusUnauthorized, send(router, http.MethodGet, config.APIAccountSessions, nil, nil).Code)
+}
@@ -3,6 +3,7 @@ package migration
 import (
 	"github.com/lyh-demo/go-webapp-demo/container"
 	"github.com/lyh-demo/go-webapp-demo/model"
+	"time"
 )
 
 // CreateDatabase creates the tables used in this application.
@@ -11,15 +12,75 @@ func CreateDatabase(container container.Container) {
 		db := container.GetRepository()
 
 		_ = db.DropTableIfExists(&model.Book{})
+		_ = db.DropTableIfExists(&model.DeletedBook{})
 		_ = db.DropTableIfExists(&model.Category{})
 		_ = db.DropTableIfExists(&model.Format{})
 		_ = db.DropTableIfExists(&model.Account{})
 		_ = db.DropTableIfExists(&model.Authority{})
+		_ = db.DropTableIfExists(&model.AuthorityPermission{})
+		_ = db.DropTableIfExists(&model.AccountAuthority{})
+		_ = db.DropTableIfExists(&model.AccountIdentity{})
+		_ = db.DropTableIfExists(&model.AccountSession{})
+		_ = db.DropTableIfExists(&model.AuditLog{})
+		_ = db.DropTableIfExists(&model.Webhook{})
+		_ = db.DropTableIfExists(&model.WebhookDelivery{})
+		_ = db.DropTableIfExists(&model.PasswordResetToken{})
+		_ = db.DropTableIfExists(&model.RefreshToken{})
+		_ = db.DropTableIfExists(&model.APIKey{})
+		_ = db.DropTableIfExists(&model.TwoFactor{})
+		_ = db.DropTableIfExists(&model.RecoveryCode{})
+		_ = db.DropTableIfExists(&model.LoginAttempt{})
 
-		_ = db.AutoMigrate(&model.Book{})
-		_ = db.AutoMigrate(&model.Category{})
-		_ = db.AutoMigrate(&model.Format{})
-		_ = db.AutoMigrate(&model.Account{})
-		_ = db.AutoMigrate(&model.Authority{})
+		MigrateDatabase(container)
+	}
+}
+
+// MigrateDatabase creates the tables used in this application if they don't exist, without dropping the data.
+func MigrateDatabase(container container.Container) {
+	db := container.GetRepository()
+
+	migrateBook(container)
+	_ = db.AutoMigrate(&model.DeletedBook{})
+	_ = db.AutoMigrate(&model.Category{})
+	_ = db.AutoMigrate(&model.Format{})
+	_ = db.AutoMigrate(&model.Account{})
+	_ = db.AutoMigrate(&model.Authority{})
+	_ = db.AutoMigrate(&model.AuthorityPermission{})
+	_ = db.AutoMigrate(&model.AccountAuthority{})
+	_ = db.AutoMigrate(&model.AccountIdentity{})
+	_ = db.AutoMigrate(&model.AccountSession{})
+	_ = db.AutoMigrate(&model.AuditLog{})
+	_ = db.AutoMigrate(&model.Webhook{})
+	_ = db.AutoMigrate(&model.WebhookDelivery{})
+	_ = db.AutoMigrate(&model.PasswordResetToken{})
+	_ = db.AutoMigrate(&model.RefreshToken{})
+	_ = db.AutoMigrate(&model.APIKey{})
+	_ = db.AutoMigrate(&model.TwoFactor{})
+	_ = db.AutoMigrate(&model.RecoveryCode{})
+	_ = db.AutoMigrate(&model.LoginAttempt{})
+}
+
+// migrateBook creates the table of books, or fills the created and updated times which the books created by
+// the older versions don't have before the columns become not null.
+func migrateBook(container container.Container) {
+	db := container.GetRepository()
+	logger := container.GetLogger().GetZapLogger()
+
+	if db.HasTable(&model.Book{}) {
+		book := model.Book{}
+		count, err := book.FillTimestamps(db, time.Now())
+		if err != nil {
+			logger.Errorf(err.Error())
+			return
+		}
+		if count > 0 {
+			logger.Infof("the created and updated times of %d books are filled", count)
+		}
+	}
+	_ = db.AutoMigrate(&model.Book{})
+	for _, field := range []string{"CreatedAt", "UpdatedAt"} {
+		if err := db.AlterColumn(&model.Book{}, field); err != nil {
+			logger.Errorf(err.Error())
+		}
 	}
 }
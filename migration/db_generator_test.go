@@ -0,0 +1,33 @@
+package migration_test
+
+import (
+	"testing"
+
+	"github.com/lyh-demo/go-webapp-demo/migration"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateDatabase_FillBookTimestamps(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	rep := container.GetRepository()
+	// the table of the older versions allows the books without the created and updated times.
+	rep.Exec("drop table book")
+	rep.Exec("create table book (id integer primary key autoincrement, title text, isbn text, " +
+		"category_id integer, format_id integer, created_at datetime, updated_at datetime)")
+	rep.Exec("insert into book (title, isbn, category_id, format_id) values (?, ?, ?, ?)",
+		"Old Book", "9780000000001", 1, 1)
+
+	migration.MigrateDatabase(container)
+
+	book := model.Book{}
+	result, err := book.FindByID(rep, 1).Take()
+	assert.NoError(t, err)
+	assert.False(t, result.CreatedAt.IsZero())
+	assert.Equal(t, result.CreatedAt, result.UpdatedAt)
+
+	err = rep.Exec("insert into book (title, isbn, category_id, format_id) values (?, ?, ?, ?)",
+		"New Book", "9780000000002", 1, 1).Error
+	assert.Error(t, err)
+}
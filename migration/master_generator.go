@@ -10,8 +10,12 @@ func InitMasterData(container container.Container) {
 	if container.GetConfig().Extension.MasterGenerator {
 		rep := container.GetRepository()
 
-		r := model.NewAuthority("Admin")
+		r := model.NewAuthority(model.AuthorityAdmin)
 		_, _ = r.Create(rep)
+		u := model.NewAuthority(model.AuthorityUser)
+		_, _ = u.Create(rep)
+		p := model.AuthorityPermission{}
+		_ = p.InitDefaults(rep)
 		a := model.NewAccountWithPlainPassword("test", "test", r.ID)
 		_, _ = a.Create(rep)
 		a = model.NewAccountWithPlainPassword("test2", "test2", r.ID)
@@ -24,9 +28,9 @@ func InitMasterData(container container.Container) {
 		c = model.NewCategory("Novel")
 		_, _ = c.Create(rep)
 
-		f := model.NewFormat("Paper Book")
+		f := model.NewFormat("Paper Book", "")
 		_, _ = f.Create(rep)
-		f = model.NewFormat("e-Book")
+		f = model.NewFormat("e-Book", model.MediaTypeEPUB)
 		_, _ = f.Create(rep)
 	}
 }
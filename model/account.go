@@ -1,32 +1,50 @@
 package model
 
 import (
+	"database/sql"
 	"github.com/lyh-demo/go-webapp-demo/config"
 	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"github.com/moznion/go-optional"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm/clause"
+	"math"
 )
 
 // Account defines struct of account data.
 type Account struct {
-	ID          uint       `gorm:"primary_key" json:"id"`
-	Name        string     `json:"name"`
-	Password    string     `json:"-"`
-	AuthorityID uint       `json:"authority_id"`
-	Authority   *Authority `json:"authority"`
+	ID             uint        `gorm:"primary_key" json:"id"`
+	Name           string      `json:"name"`
+	Password       string      `json:"-"`
+	AuthorityID    uint        `json:"authority_id"`
+	Authority      *Authority  `json:"authority"`
+	Authorities    []Authority `gorm:"-" json:"authorities"`
+	Email          string      `json:"email"`
+	Locale         string      `json:"locale"`
+	Disabled       bool        `json:"disabled"`
+	SessionVersion uint        `json:"-"`
+	Permissions    []string    `gorm:"-" json:"-"`
 }
 
 // RecordAccount defines struct represents the record of the database.
 type RecordAccount struct {
-	ID            uint
-	Name          string
-	Password      string
-	AuthorityID   uint
-	AuthorityName string
+	ID             uint
+	Name           string
+	Password       string
+	AuthorityID    uint
+	AuthorityName  string
+	Email          string
+	Locale         string
+	Disabled       bool
+	SessionVersion uint
 }
 
-const selectAccount = "select a.id as id, a.name as name, a.password as password," +
-	" r.id as authority_id, r.name as authority_name " +
-	" from account_master a inner join authority_master r on a.authority_id = r.id "
+const (
+	selectAccount = "select a.id as id, a.name as name, a.password as password, a.email as email, a.locale as locale," +
+		" a.disabled as disabled, a.session_version as session_version, r.id as authority_id, r.name as authority_name " +
+		" from account_master a inner join authority_master r on a.authority_id = r.id "
+	countAccount = "select count(*) from account_master a inner join authority_master r on a.authority_id = r.id "
+)
 
 // TableName returns the table name of account struct, and it is used by gorm.
 func (a *Account) TableName() string {
@@ -52,20 +70,244 @@ func (a *Account) FindByName(rep repository.Repository, name string) (*Account,
 	rep.Raw(selectAccount+" where a.name = ?", name).Scan(&rec)
 	account = convertToAccount(&rec)
 
+	if account.ID != 0 {
+		if err := loadAuthorities(rep, account); err != nil {
+			return nil, err
+		}
+	}
 	return account, nil
 }
 
+// FindByEmail returns an account full matched given email address.
+func (a *Account) FindByEmail(rep repository.Repository, email string) optional.Option[*Account] {
+	var rec RecordAccount
+	rep.Raw(selectAccount+" where a.email = ?", email).Scan(&rec)
+	if rec.ID == 0 {
+		return optional.None[*Account]()
+	}
+	account := convertToAccount(&rec)
+	if err := loadAuthorities(rep, account); err != nil {
+		return optional.None[*Account]()
+	}
+	return optional.Some(account)
+}
+
+// FindByID returns an account full matched given account's ID.
+func (a *Account) FindByID(rep repository.Repository, id uint) optional.Option[*Account] {
+	var rec RecordAccount
+	rep.Raw(selectAccount+" where a.id = ?", id).Scan(&rec)
+	if rec.ID == 0 {
+		return optional.None[*Account]()
+	}
+	account := convertToAccount(&rec)
+	if err := loadAuthorities(rep, account); err != nil {
+		return optional.None[*Account]()
+	}
+	return optional.Some(account)
+}
+
+// FindByFilter returns the page object of accounts matched all given conditions in order of the ID.
+// The empty name and the zero ID aren't used as conditions, and the name is partially matched.
+func (a *Account) FindByFilter(rep repository.Repository, name string, authorityID uint,
+	page string, size string) (*AccountPage, error) {
+	where := " where 1 = 1 "
+	var args []interface{}
+
+	if name != "" {
+		where += " and a.name like ? "
+		args = append(args, "%"+name+"%")
+	}
+	if authorityID != 0 {
+		where += " and (a.authority_id = ? or a.id in (select account_id from account_authority where authority_id = ?)) "
+		args = append(args, authorityID, authorityID)
+	}
+
+	var total int64
+	if err := rep.Raw(countAccount+where, args...).Scan(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	var rows *sql.Rows
+	var err error
+	if rows, err = createRaw(rep, selectAccount+where+" order by a.id ", page, size, args).Rows(); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rec RecordAccount
+		if err = rep.ScanRows(rows, &rec); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *convertToAccount(&rec))
+	}
+
+	refs := make([]*Account, len(accounts))
+	for i := range accounts {
+		refs[i] = &accounts[i]
+	}
+	if err = loadAuthorities(rep, refs...); err != nil {
+		return nil, err
+	}
+	return createAccountPage(&accounts, total, page, size), nil
+}
+
+// LockByAuthorityName locks the enabled accounts which hold given authority as the primary or an additional
+// authority until the end of the transaction, and returns their IDs. The rows aren't locked on SQLite,
+// which serializes the transactions writing to the database instead.
+func (a *Account) LockByAuthorityName(rep repository.Repository, authorityName string) ([]uint, error) {
+	var ids []uint
+	if err := rep.Model(&Account{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("disabled = ? and (authority_id in (select id from authority_master where name = ?) or id in "+
+			"(select aa.account_id from account_authority aa inner join authority_master ar on aa.authority_id = ar.id "+
+			"where ar.name = ?))", false, authorityName, authorityName).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Exist returns true if an account of given name exists except for the account of given ID.
+func (a *Account) Exist(rep repository.Repository, name string, exceptID uint) (bool, error) {
+	var count int64
+	if err := rep.Model(&Account{}).Where("name = ? and id <> ?", name, exceptID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ExistEmail returns true if an account of given email exists except for the account of given ID.
+func (a *Account) ExistEmail(rep repository.Repository, email string, exceptID uint) (bool, error) {
+	var count int64
+	if err := rep.Model(&Account{}).Where("email = ? and id <> ?", email, exceptID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // Create persists this account data.
 func (a *Account) Create(rep repository.Repository) (*Account, error) {
-	if err := rep.Select("name", "password", "authority_id").Create(a).Error; err != nil {
+	if err := rep.Select("name", "password", "email", "authority_id", "disabled").Create(a).Error; err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
+// Update updates the name, the email, the authority, the status and the password of this account data.
+func (a *Account) Update(rep repository.Repository) (*Account, error) {
+	if err := rep.Model(a).Where("id = ?", a.ID).
+		Select("name", "password", "email", "authority_id", "disabled").Updates(a).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// UpdatePassword persists the password of this account, and increments the session version
+// so that the sessions created before the change are no longer valid.
+func (a *Account) UpdatePassword(rep repository.Repository) (*Account, error) {
+	a.SessionVersion++
+	if err := rep.Model(a).Where("id = ?", a.ID).
+		Select("password", "session_version").Updates(a).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// UpdateSessionVersion increments the session version so that the sessions and the access tokens
+// issued before are no longer valid.
+func (a *Account) UpdateSessionVersion(rep repository.Repository) (*Account, error) {
+	a.SessionVersion++
+	if err := rep.Model(a).Where("id = ?", a.ID).Update("session_version", a.SessionVersion).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// UpdateLocale persists the preferred locale of this account.
+func (a *Account) UpdateLocale(rep repository.Repository) (*Account, error) {
+	if err := rep.Model(a).Update("locale", a.Locale).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Delete deletes this account data.
+func (a *Account) Delete(rep repository.Repository) (*Account, error) {
+	if err := rep.Delete(a).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// HasAuthority returns true if this account holds given authority as the primary or an additional authority.
+func (a *Account) HasAuthority(name string) bool {
+	if a.Authority != nil && a.Authority.Name == name {
+		return true
+	}
+	for _, authority := range a.Authorities {
+		if authority.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AdditionalAuthorityIDs returns the IDs of the authorities held by this account except for the primary one.
+func (a *Account) AdditionalAuthorityIDs() []uint {
+	var ids []uint
+	for _, authority := range a.Authorities {
+		if authority.ID != a.AuthorityID {
+			ids = append(ids, authority.ID)
+		}
+	}
+	return ids
+}
+
+// loadAuthorities sets all authorities held by given accounts, and the primary authority is the first of them.
+func loadAuthorities(rep repository.Repository, accounts ...*Account) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	aa := AccountAuthority{}
+	additional, err := aa.FindByAccountIDs(rep, ids)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		account.Authorities = append([]Authority{*account.Authority}, additional[account.ID]...)
+	}
+	return nil
+}
+
 func convertToAccount(rec *RecordAccount) *Account {
 	r := &Authority{ID: rec.AuthorityID, Name: rec.AuthorityName}
-	return &Account{ID: rec.ID, Name: rec.Name, Password: rec.Password, AuthorityID: rec.AuthorityID, Authority: r}
+	return &Account{ID: rec.ID, Name: rec.Name, Password: rec.Password, AuthorityID: rec.AuthorityID, Authority: r,
+		Email: rec.Email, Locale: rec.Locale, Disabled: rec.Disabled, SessionVersion: rec.SessionVersion}
+}
+
+func createAccountPage(accounts *[]Account, total int64, page string, size string) *AccountPage {
+	p := NewAccountPage()
+	if util.IsNumeric(page) && util.IsNumeric(size) {
+		p.Page = util.ConvertToInt(page)
+		p.Size = util.ConvertToInt(size)
+	}
+	if accounts == nil || *accounts == nil {
+		accounts = &[]Account{}
+	}
+	p.Content = accounts
+	p.NumberOfElements = len(*accounts)
+	p.TotalElements = int(total)
+	if p.Size > 0 {
+		p.TotalPages = int(math.Ceil(float64(p.TotalElements) / float64(p.Size)))
+		p.Last = p.Page+1 >= p.TotalPages
+	} else {
+		p.TotalPages = 1
+		p.Last = true
+	}
+	return p
 }
 
 // ToString is return string of object
@@ -0,0 +1,60 @@
+package model
+
+import "github.com/lyh-demo/go-webapp-demo/repository"
+
+// AccountAuthority defines struct of an additional authority held by an account.
+// The primary authority of the account is the authority_id of the account table.
+type AccountAuthority struct {
+	ID          uint `gorm:"primary_key" json:"id"`
+	AccountID   uint `gorm:"index" json:"accountId"`
+	AuthorityID uint `json:"authorityId"`
+}
+
+// TableName returns the table name of account authority struct, and it is used by gorm.
+func (a *AccountAuthority) TableName() string {
+	return "account_authority"
+}
+
+// FindByAccountIDs returns the additional authorities of given accounts, which are grouped by the account ID.
+func (a *AccountAuthority) FindByAccountIDs(rep repository.Repository, accountIDs []uint) (map[uint][]Authority, error) {
+	type record struct {
+		AccountID     uint
+		AuthorityID   uint
+		AuthorityName string
+	}
+	var records []record
+	if err := rep.Raw("select aa.account_id as account_id, r.id as authority_id, r.name as authority_name "+
+		"from account_authority aa inner join authority_master r on aa.authority_id = r.id "+
+		"where aa.account_id in ? order by aa.account_id, r.id", accountIDs).Scan(&records).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint][]Authority)
+	for _, rec := range records {
+		result[rec.AccountID] = append(result[rec.AccountID], Authority{ID: rec.AuthorityID, Name: rec.AuthorityName})
+	}
+	return result, nil
+}
+
+// ReplaceByAccountID replaces the additional authorities of given account.
+func (a *AccountAuthority) ReplaceByAccountID(rep repository.Repository, accountID uint, authorityIDs []uint) error {
+	if err := a.DeleteByAccountID(rep, accountID); err != nil {
+		return err
+	}
+	for _, id := range authorityIDs {
+		if err := rep.Create(&AccountAuthority{AccountID: accountID, AuthorityID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteByAccountID deletes the additional authorities of given account.
+func (a *AccountAuthority) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&AccountAuthority{}).Error
+}
+
+// ToString is return string of object
+func (a *AccountAuthority) ToString() string {
+	return toString(a)
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// AccountIdentity defines struct of the identity of an external identity provider linked to an account.
+// The identity is identified by the issuer and the subject of the ID token. The identity is provisioned
+// if its account was created by the login through the provider, rather than linked by the logged-in account.
+type AccountIdentity struct {
+	ID          uint      `gorm:"primary_key" json:"id"`
+	AccountID   uint      `gorm:"index" json:"accountId"`
+	Issuer      string    `gorm:"uniqueIndex:idx_account_identity_issuer_subject" json:"issuer"`
+	Subject     string    `gorm:"uniqueIndex:idx_account_identity_issuer_subject" json:"subject"`
+	Provisioned bool      `json:"provisioned"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// IdentityLink represents the URL of the provider to link the identity to the logged-in account.
+type IdentityLink struct {
+	URL string `json:"url"`
+}
+
+// TableName returns the table name of account identity struct, and it is used by gorm.
+func (a *AccountIdentity) TableName() string {
+	return "account_identity"
+}
+
+// NewAccountIdentity is constructor.
+func NewAccountIdentity(accountID uint, issuer string, subject string, provisioned bool) *AccountIdentity {
+	return &AccountIdentity{AccountID: accountID, Issuer: issuer, Subject: subject, Provisioned: provisioned}
+}
+
+// FindByIssuerAndSubject returns the identity full matched given issuer and subject.
+func (a *AccountIdentity) FindByIssuerAndSubject(rep repository.Repository, issuer string,
+	subject string) optional.Option[*AccountIdentity] {
+	var identity AccountIdentity
+	if err := rep.Where("issuer = ? and subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		return optional.None[*AccountIdentity]()
+	}
+	return optional.Some(&identity)
+}
+
+// Create persists this identity data.
+func (a *AccountIdentity) Create(rep repository.Repository) (*AccountIdentity, error) {
+	if err := rep.Create(a).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// DeleteByAccountID deletes the identities linked to given account.
+func (a *AccountIdentity) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&AccountIdentity{}).Error
+}
+
+// ToString is return string of object
+func (a *AccountIdentity) ToString() string {
+	return toString(a)
+}
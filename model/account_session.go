@@ -0,0 +1,110 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// AccountSession defines struct of a login session of an account. The session is identified by a random token
+// kept in the session store, and only the hash of the token is persisted. Deleting the session revokes it.
+type AccountSession struct {
+	ID           uint      `gorm:"primary_key" json:"id"`
+	AccountID    uint      `gorm:"index" json:"accountId"`
+	TokenHash    string    `gorm:"uniqueIndex" json:"-"`
+	RemoteIP     string    `json:"remoteIp"`
+	UserAgent    string    `json:"userAgent"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	Current      bool      `gorm:"-" json:"current"`
+}
+
+// TableName returns the table name of account session struct, and it is used by gorm.
+func (s *AccountSession) TableName() string {
+	return "account_session"
+}
+
+// NewAccountSession is constructor.
+func NewAccountSession(accountID uint, tokenHash string, remoteIP string, userAgent string,
+	now time.Time) *AccountSession {
+	return &AccountSession{AccountID: accountID, TokenHash: tokenHash, RemoteIP: remoteIP, UserAgent: userAgent,
+		CreatedAt: now, LastActiveAt: now}
+}
+
+// FindByTokenHash returns the session full matched given token hash.
+func (s *AccountSession) FindByTokenHash(rep repository.Repository, tokenHash string) optional.Option[*AccountSession] {
+	var session AccountSession
+	if err := rep.Where("token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		return optional.None[*AccountSession]()
+	}
+	return optional.Some(&session)
+}
+
+// FindByAccountID returns the sessions of given account in order of the last activity, the latest first.
+func (s *AccountSession) FindByAccountID(rep repository.Repository, accountID uint) (*[]AccountSession, error) {
+	var sessions []AccountSession
+	if err := rep.Where("account_id = ?", accountID).Order("last_active_at desc").Order("id desc").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return &sessions, nil
+}
+
+// Create persists this session.
+func (s *AccountSession) Create(rep repository.Repository) (*AccountSession, error) {
+	if err := rep.Create(s).Error; err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Touch records the last activity of this session and the IP address it came from.
+func (s *AccountSession) Touch(rep repository.Repository, now time.Time, remoteIP string) error {
+	s.LastActiveAt = now
+	s.RemoteIP = remoteIP
+	return rep.Model(&AccountSession{}).Where("id = ?", s.ID).
+		Updates(map[string]interface{}{"last_active_at": now, "remote_ip": remoteIP}).Error
+}
+
+// DeleteByID deletes the session of given ID if it belongs to given account.
+// It returns false if there is no such session.
+func (s *AccountSession) DeleteByID(rep repository.Repository, accountID uint, id uint) (bool, error) {
+	result := rep.Where("id = ? and account_id = ?", id, accountID).Delete(&AccountSession{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByTokenHash deletes the session of given token hash.
+func (s *AccountSession) DeleteByTokenHash(rep repository.Repository, tokenHash string) error {
+	return rep.Where("token_hash = ?", tokenHash).Delete(&AccountSession{}).Error
+}
+
+// DeleteOthers deletes the sessions of given account except the session of given ID.
+func (s *AccountSession) DeleteOthers(rep repository.Repository, accountID uint, exceptID uint) (int64, error) {
+	result := rep.Where("account_id = ? and id <> ?", accountID, exceptID).Delete(&AccountSession{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByAccountID deletes all sessions of given account.
+func (s *AccountSession) DeleteByAccountID(rep repository.Repository, accountID uint) (int64, error) {
+	result := rep.Where("account_id = ?", accountID).Delete(&AccountSession{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteInactiveBefore deletes the sessions whose last activity is before given time.
+func (s *AccountSession) DeleteInactiveBefore(rep repository.Repository, t time.Time) error {
+	return rep.Where("last_active_at < ?", t).Delete(&AccountSession{}).Error
+}
+
+// ToString is return string of object
+func (s *AccountSession) ToString() string {
+	return toString(s)
+}
+
+// CSRFToken defines struct of the CSRF token of the session and the header to send it back.
+type CSRFToken struct {
+	Token      string `json:"token"`
+	HeaderName string `json:"headerName"`
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// APIKey defines struct of the personal key to access the API without the password.
+// Only the hash of the key is persisted, and the key is shown only once when it is created.
+// The key has the scopes, which are the permissions it can use among the permissions of the account.
+type APIKey struct {
+	ID         uint       `gorm:"primary_key" json:"id"`
+	AccountID  uint       `gorm:"index" json:"accountId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `gorm:"uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"-" json:"scopes"`
+	ScopeNames string     `gorm:"column:scopes" json:"-"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Key        string     `gorm:"-" json:"key,omitempty"`
+}
+
+// scopeSeparator separates the scopes stored in a column.
+const scopeSeparator = ","
+
+// TableName returns the table name of API key struct, and it is used by gorm.
+func (k *APIKey) TableName() string {
+	return "api_key"
+}
+
+// NewAPIKey is constructor.
+func NewAPIKey(accountID uint, name string, prefix string, keyHash string, scopes []string,
+	expiresAt *time.Time) *APIKey {
+	return &APIKey{AccountID: accountID, Name: name, Prefix: prefix, KeyHash: keyHash, Scopes: scopes,
+		ExpiresAt: expiresAt}
+}
+
+// BeforeSave joins the scopes into a column before saving, and it is used by gorm.
+func (k *APIKey) BeforeSave(_ *gorm.DB) error {
+	k.ScopeNames = strings.Join(k.Scopes, scopeSeparator)
+	return nil
+}
+
+// AfterFind splits the column into the scopes after fetching, and it is used by gorm.
+func (k *APIKey) AfterFind(_ *gorm.DB) error {
+	k.Scopes = []string{}
+	if k.ScopeNames != "" {
+		k.Scopes = strings.Split(k.ScopeNames, scopeSeparator)
+	}
+	return nil
+}
+
+// FindByID returns an API key full matched given key's ID, including the revoked and expired ones.
+func (k *APIKey) FindByID(rep repository.Repository, id uint) optional.Option[*APIKey] {
+	var key APIKey
+	if err := rep.Where("id = ?", id).First(&key).Error; err != nil {
+		return optional.None[*APIKey]()
+	}
+	return optional.Some(&key)
+}
+
+// FindByKeyHash returns the API key which has given hash, including the revoked and expired ones.
+func (k *APIKey) FindByKeyHash(rep repository.Repository, keyHash string) optional.Option[*APIKey] {
+	var key APIKey
+	if err := rep.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return optional.None[*APIKey]()
+	}
+	return optional.Some(&key)
+}
+
+// FindByAccountID returns the API keys of given account. All keys are returned if the account ID is zero.
+func (k *APIKey) FindByAccountID(rep repository.Repository, accountID uint) (*[]APIKey, error) {
+	var keys []APIKey
+	tx := rep.Order("id")
+	if accountID != 0 {
+		tx = tx.Where("account_id = ?", accountID)
+	}
+	if err := tx.Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+// Create persists this API key data.
+func (k *APIKey) Create(rep repository.Repository) (*APIKey, error) {
+	if err := rep.Create(k).Error; err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Revoke marks this API key as revoked. It does nothing if the key has already been revoked.
+func (k *APIKey) Revoke(rep repository.Repository, now time.Time) error {
+	if k.RevokedAt != nil {
+		return nil
+	}
+	if err := rep.Model(&APIKey{}).Where("id = ? and revoked_at is null", k.ID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	k.RevokedAt = &now
+	return nil
+}
+
+// UpdateLastUsedAt records the time when this API key was used.
+func (k *APIKey) UpdateLastUsedAt(rep repository.Repository, now time.Time) error {
+	if err := rep.Model(&APIKey{}).Where("id = ?", k.ID).Update("last_used_at", now).Error; err != nil {
+		return err
+	}
+	k.LastUsedAt = &now
+	return nil
+}
+
+// DeleteByAccountID deletes all API keys of given account.
+func (k *APIKey) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&APIKey{}).Error
+}
+
+// IsActive returns true if this API key hasn't been revoked or expired yet.
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
+
+// ToString is return string of object
+func (k *APIKey) ToString() string {
+	return toString(k)
+}
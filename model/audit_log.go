@@ -0,0 +1,301 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"github.com/moznion/go-optional"
+	"gorm.io/gorm"
+	"math"
+	"time"
+)
+
+const (
+	// AuditLogin is the event of the login by the password, the second factor, the identity provider or the token.
+	AuditLogin = "login"
+	// AuditLogout is the event of the logout.
+	AuditLogout = "logout"
+	// AuditUnauthorized is the event of the request denied because it isn't authenticated.
+	AuditUnauthorized = "access.unauthorized"
+	// AuditForbidden is the event of the request denied because it isn't permitted.
+	AuditForbidden = "access.forbidden"
+	// AuditPasswordChange is the event of the change of the password by the account itself.
+	AuditPasswordChange = "password.change"
+	// AuditPasswordReset is the event of the reset of the password by the reset token.
+	AuditPasswordReset = "password.reset"
+	// AuditAccountCreate is the event of the creation of an account by an administrator.
+	AuditAccountCreate = "account.create"
+	// AuditAccountUpdate is the event of the update of an account by an administrator.
+	AuditAccountUpdate = "account.update"
+	// AuditAccountDelete is the event of the deletion of an account by an administrator.
+	AuditAccountDelete = "account.delete"
+	// AuditAccountLogout is the event of the forced logout of an account by an administrator.
+	AuditAccountLogout = "account.logout"
+	// AuditIdentityLink is the event of the link of the identity of the provider by the account itself.
+	AuditIdentityLink = "identity.link"
+	// AuditLockoutUnlock is the event of the release of a lockout by an administrator.
+	AuditLockoutUnlock = "lockout.unlock"
+	// AuditPurge is the event of the deletion of the records older than the retention.
+	// Its target is the hash of the last deleted record, which the oldest remaining record refers to.
+	AuditPurge = "audit.purge"
+)
+
+const (
+	// AuditSuccess is the outcome of the event which has succeeded.
+	AuditSuccess = "success"
+	// AuditFailure is the outcome of the event which has failed.
+	AuditFailure = "failure"
+	// AuditDenied is the outcome of the request which has been denied.
+	AuditDenied = "denied"
+)
+
+// AuditLog defines struct of a record of the security audit log. Each record has the hash of the previous record
+// and its own hash over both, so the records form a chain and the deletion or the modification of a record breaks it.
+type AuditLog struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	Event     string    `gorm:"index" json:"event"`
+	Outcome   string    `json:"outcome"`
+	ActorID   *uint     `gorm:"index" json:"actorId"`
+	ActorName string    `json:"actorName"`
+	Target    string    `json:"target"`
+	RemoteIP  string    `json:"remoteIp"`
+	UserAgent string    `json:"userAgent"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	PrevHash  string    `gorm:"uniqueIndex" json:"prevHash"`
+	Hash      string    `json:"hash"`
+}
+
+// TableName returns the table name of audit log struct, and it is used by gorm.
+func (a *AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// NewAuditLog is constructor.
+func NewAuditLog(event string, outcome string, remoteIP string, userAgent string) *AuditLog {
+	return &AuditLog{Event: event, Outcome: outcome, RemoteIP: remoteIP, UserAgent: userAgent}
+}
+
+// WithActor sets the account which has caused the event, and returns this record.
+func (a *AuditLog) WithActor(account *Account) *AuditLog {
+	if account != nil {
+		id := account.ID
+		a.ActorID = &id
+		a.ActorName = account.Name
+	}
+	return a
+}
+
+// WithActorName sets the name of the actor which isn't an account yet, such as the username of a failed login.
+func (a *AuditLog) WithActorName(name string) *AuditLog {
+	a.ActorName = name
+	return a
+}
+
+// WithTarget sets the target of the event, such as the ID of the account operated by an administrator.
+func (a *AuditLog) WithTarget(target string) *AuditLog {
+	a.Target = target
+	return a
+}
+
+// WithDetails sets the details of the event, such as the reason of the failure.
+func (a *AuditLog) WithDetails(details string) *AuditLog {
+	a.Details = details
+	return a
+}
+
+// Chain links this record to the previous record at given time, and computes its hash.
+// The time is truncated to milliseconds in UTC so that the hash is computed again from the persisted record.
+func (a *AuditLog) Chain(prevHash string, now time.Time) {
+	a.PrevHash = prevHash
+	a.CreatedAt = now.UTC().Truncate(time.Millisecond)
+	a.Hash = a.ComputeHash()
+}
+
+// ComputeHash returns the SHA-256 hash of the previous hash and the fields of this record except for the ID.
+func (a *AuditLog) ComputeHash() string {
+	var actorID uint
+	if a.ActorID != nil {
+		actorID = *a.ActorID
+	}
+	b, _ := json.Marshal([]interface{}{a.PrevHash, a.Event, a.Outcome, actorID, a.ActorName, a.Target, a.RemoteIP,
+		a.UserAgent, a.Details, a.CreatedAt.UTC().Format(time.RFC3339Nano)})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLogCondition defines the conditions to find the records of the audit log.
+// The empty strings and the nil times aren't used as conditions.
+type AuditLogCondition struct {
+	Event     string
+	Outcome   string
+	ActorName string
+	Target    string
+	RemoteIP  string
+	// From is the inclusive lower bound of the time of the records.
+	From *time.Time
+	// Until is the exclusive upper bound of the time of the records.
+	Until *time.Time
+}
+
+// where returns the query of the records narrowed by the conditions.
+func (cond *AuditLogCondition) where(rep repository.Repository) *gorm.DB {
+	query := rep.Model(&AuditLog{})
+	if cond.Event != "" {
+		query = query.Where("event = ?", cond.Event)
+	}
+	if cond.Outcome != "" {
+		query = query.Where("outcome = ?", cond.Outcome)
+	}
+	if cond.ActorName != "" {
+		query = query.Where("actor_name = ?", cond.ActorName)
+	}
+	if cond.Target != "" {
+		query = query.Where("target = ?", cond.Target)
+	}
+	if cond.RemoteIP != "" {
+		query = query.Where("remote_ip = ?", cond.RemoteIP)
+	}
+	if cond.From != nil {
+		query = query.Where("created_at >= ?", cond.From.UTC())
+	}
+	if cond.Until != nil {
+		query = query.Where("created_at < ?", cond.Until.UTC())
+	}
+	return query
+}
+
+// FindByCondition returns the page object of the records matched given conditions in order of the newest.
+func (a *AuditLog) FindByCondition(rep repository.Repository, cond *AuditLogCondition, page string,
+	size string) (*AuditLogPage, error) {
+	var total int64
+	if err := cond.where(rep).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	var logs []AuditLog
+	query := cond.where(rep).Order("id desc")
+	if util.IsNumeric(page) && util.IsNumeric(size) {
+		query = query.Limit(util.ConvertToInt(size)).Offset(util.ConvertToInt(page) * util.ConvertToInt(size))
+	}
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return createAuditLogPage(&logs, total, page, size), nil
+}
+
+// FindAllByCondition returns the records matched given conditions in order of the oldest, at most given limit.
+func (a *AuditLog) FindAllByCondition(rep repository.Repository, cond *AuditLogCondition,
+	limit int) (*[]AuditLog, error) {
+	var logs []AuditLog
+	if err := cond.where(rep).Order("id").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
+
+// FindLatestByCondition returns the newest records matched given conditions at most given limit, in order of the oldest.
+func (a *AuditLog) FindLatestByCondition(rep repository.Repository, cond *AuditLogCondition,
+	limit int) (*[]AuditLog, error) {
+	var logs []AuditLog
+	if err := cond.where(rep).Order("id desc").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+	return &logs, nil
+}
+
+// FindAfter returns the records after given ID in order of the chain, at most given limit.
+func (a *AuditLog) FindAfter(rep repository.Repository, afterID uint, limit int) (*[]AuditLog, error) {
+	var logs []AuditLog
+	if err := rep.Where("id > ?", afterID).Order("id").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
+
+// FindLast returns the last record of the chain.
+func (a *AuditLog) FindLast(rep repository.Repository) optional.Option[*AuditLog] {
+	var log AuditLog
+	if err := rep.Order("id desc").First(&log).Error; err != nil {
+		return optional.None[*AuditLog]()
+	}
+	return optional.Some(&log)
+}
+
+// FindFirstFrom returns the first record of the chain created at or after given time.
+func (a *AuditLog) FindFirstFrom(rep repository.Repository, t time.Time) optional.Option[*AuditLog] {
+	var log AuditLog
+	if err := rep.Where("created_at >= ?", t.UTC()).Order("id").First(&log).Error; err != nil {
+		return optional.None[*AuditLog]()
+	}
+	return optional.Some(&log)
+}
+
+// Create persists this record.
+func (a *AuditLog) Create(rep repository.Repository) (*AuditLog, error) {
+	if err := rep.Create(a).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// DeleteBeforeID deletes the records of the chain before the record of given ID.
+func (a *AuditLog) DeleteBeforeID(rep repository.Repository, id uint) (int64, error) {
+	result := rep.Where("id < ?", id).Delete(&AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// ToString is return string of object
+func (a *AuditLog) ToString() string {
+	return toString(a)
+}
+
+// AuditLogPage defines struct of pagination data of the audit log.
+type AuditLogPage struct {
+	Content          *[]AuditLog `json:"content"`
+	Last             bool        `json:"last"`
+	TotalElements    int         `json:"totalElements"`
+	TotalPages       int         `json:"totalPages"`
+	Size             int         `json:"size"`
+	Page             int         `json:"page"`
+	NumberOfElements int         `json:"numberOfElements"`
+}
+
+func createAuditLogPage(logs *[]AuditLog, total int64, page string, size string) *AuditLogPage {
+	p := &AuditLogPage{}
+	if util.IsNumeric(page) && util.IsNumeric(size) {
+		p.Page = util.ConvertToInt(page)
+		p.Size = util.ConvertToInt(size)
+	}
+	if logs == nil || *logs == nil {
+		logs = &[]AuditLog{}
+	}
+	p.Content = logs
+	p.NumberOfElements = len(*logs)
+	p.TotalElements = int(total)
+	if p.Size > 0 {
+		p.TotalPages = int(math.Ceil(float64(p.TotalElements) / float64(p.Size)))
+		p.Last = p.Page+1 >= p.TotalPages
+	} else {
+		p.TotalPages = 1
+		p.Last = true
+	}
+	return p
+}
+
+// AuditLogVerification defines struct of the result of the verification of the audit log chain.
+// The last hash should be kept outside of the application, because the deletion of the latest records
+// can be detected only by comparing it.
+type AuditLogVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	FirstID  uint   `json:"firstId"`
+	LastID   uint   `json:"lastId"`
+	LastHash string `json:"lastHash"`
+	BrokenID uint   `json:"brokenId,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -1,11 +1,22 @@
 package model
 
-import "github.com/lyh-demo/go-webapp-demo/repository"
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+)
+
+const (
+	// AuthorityAdmin is the name of the authority which can access all the API.
+	AuthorityAdmin = "Admin"
+	// AuthorityUser is the name of the authority which can access the API except for the administration.
+	AuthorityUser = "User"
+)
 
 // Authority defines struct of authority data.
 type Authority struct {
-	ID   uint   `gorm:"primary_key" json:"id"`
-	Name string `json:"name"`
+	ID          uint     `gorm:"primary_key" json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `gorm:"-" json:"permissions,omitempty"`
 }
 
 // TableName returns the table name of authority struct and it is used by gorm.
@@ -18,6 +29,33 @@ func NewAuthority(name string) *Authority {
 	return &Authority{Name: name}
 }
 
+// FindByID returns an authority full matched given authority's ID.
+func (a *Authority) FindByID(rep repository.Repository, id uint) optional.Option[*Authority] {
+	var authority Authority
+	if err := rep.Where("id = ?", id).First(&authority).Error; err != nil {
+		return optional.None[*Authority]()
+	}
+	return optional.Some(&authority)
+}
+
+// FindAll returns all authorities of the authority table.
+func (a *Authority) FindAll(rep repository.Repository) (*[]Authority, error) {
+	var authorities []Authority
+	if err := rep.Find(&authorities).Error; err != nil {
+		return nil, err
+	}
+	return &authorities, nil
+}
+
+// Exist returns true if an authority having given name exists.
+func (a *Authority) Exist(rep repository.Repository, name string) (bool, error) {
+	var count int64
+	if err := rep.Model(&Authority{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // Create persists this authority data.
 func (a *Authority) Create(rep repository.Repository) (*Authority, error) {
 	if err := rep.Create(a).Error; err != nil {
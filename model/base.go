@@ -4,7 +4,8 @@ import "encoding/json"
 
 // DomainObject defines the common interface for domain models.
 type DomainObject interface {
-	Account | Authority | Book | Category | Format
+	APIKey | Account | AccountAuthority | AccountIdentity | AccountSession | AuditLog | Authority | AuthorityPermission | Book | Category | DeletedBook | Format |
+		LoginAttempt | PasswordResetToken | RecoveryCode | RefreshToken | Summary | TwoFactor | Webhook | WebhookDelivery
 }
 
 // toString returns the JSON data of the domain models.
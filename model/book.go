@@ -8,6 +8,8 @@ import (
 	"github.com/moznion/go-optional"
 	"gorm.io/gorm"
 	"math"
+	"strings"
+	"time"
 )
 
 // Book defines struct of book data.
@@ -19,6 +21,8 @@ type Book struct {
 	Category   *Category `json:"category"`
 	FormatID   uint      `json:"formatId"`
 	Format     *Format   `json:"format"`
+	CreatedAt  time.Time `gorm:"not null" json:"createdAt"`
+	UpdatedAt  time.Time `gorm:"not null" json:"updatedAt"`
 }
 
 // RecordBook defines struct represents the record of the database.
@@ -30,14 +34,22 @@ type RecordBook struct {
 	CategoryName string
 	FormatID     uint
 	FormatName   string
+	FormatType   string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 const (
 	selectBook = "select b.id as id, b.title as title, b.isbn as isbn, " +
-		"c.id as category_id, c.name as category_name, f.id as format_id, f.name as format_name " +
+		"c.id as category_id, c.name as category_name, f.id as format_id, f.name as format_name, " +
+		"f.media_type as format_type, " +
+		"b.created_at as created_at, b.updated_at as updated_at " +
 		"from book b inner join category_master c on c.id = b.category_id inner join format_master f on f.id = b.format_id "
-	findByID    = " where b.id = ?"
-	findByTitle = " where title like ? "
+	findByID         = " where b.id = ?"
+	findByTitle      = " where title like ? "
+	findByCategoryID = " where b.category_id = ? "
+	findByFormatID   = " where b.format_id = ? "
+	orderByNewest    = " order by b.created_at desc, b.id desc "
 )
 
 // TableName returns the table name of book struct, and it is used by gorm.
@@ -95,6 +107,159 @@ func (b *Book) FindByTitle(rep repository.Repository, title string, page string,
 	return p, nil
 }
 
+// FindByCategoryID returns the page object of books belonging to given category.
+func (b *Book) FindByCategoryID(rep repository.Repository, categoryID uint, page string, size string) (*Page, error) {
+	var books []Book
+	var err error
+	args := []interface{}{categoryID}
+
+	if books, err = findRows(rep, selectBook+findByCategoryID, page, size, args); err != nil {
+		return nil, err
+	}
+	p := createPage(&books, page, size)
+	return p, nil
+}
+
+// FindByFormatID returns the page object of books published in given format.
+func (b *Book) FindByFormatID(rep repository.Repository, formatID uint, page string, size string) (*Page, error) {
+	var books []Book
+	var err error
+	args := []interface{}{formatID}
+
+	if books, err = findRows(rep, selectBook+findByFormatID, page, size, args); err != nil {
+		return nil, err
+	}
+	p := createPage(&books, page, size)
+	return p, nil
+}
+
+// FindByFilter returns the page object of books matched all given conditions.
+// The empty title and the zero IDs aren't used as conditions.
+func (b *Book) FindByFilter(rep repository.Repository, title string, categoryID uint, formatID uint,
+	page string, size string) (*Page, error) {
+	return findByFilter(rep, selectBook+" where 1 = 1 ", title, categoryID, formatID, page, size)
+}
+
+// FindEBooksByFilter returns the page object of e-books matched all given conditions, which are the books
+// published in the formats having the media type. The empty title and the zero IDs aren't used as conditions.
+func (b *Book) FindEBooksByFilter(rep repository.Repository, title string, categoryID uint, formatID uint,
+	page string, size string) (*Page, error) {
+	return findByFilter(rep, selectBook+" where f.media_type <> '' ", title, categoryID, formatID, page, size)
+}
+
+// findByFilter adds the conditions to given query, and returns the page object of books matched them.
+func findByFilter(rep repository.Repository, sqlQuery string, title string, categoryID uint, formatID uint,
+	page string, size string) (*Page, error) {
+	var books []Book
+	var err error
+	var args []interface{}
+
+	if title != "" {
+		sqlQuery += " and b.title like ? "
+		args = append(args, "%"+title+"%")
+	}
+	if categoryID != 0 {
+		sqlQuery += " and b.category_id = ? "
+		args = append(args, categoryID)
+	}
+	if formatID != 0 {
+		sqlQuery += " and b.format_id = ? "
+		args = append(args, formatID)
+	}
+
+	if books, err = findRows(rep, sqlQuery, page, size, args); err != nil {
+		return nil, err
+	}
+	p := createPage(&books, page, size)
+	return p, nil
+}
+
+// FindByCategoryIDs returns books belonging to given categories, and it pages the books of each category separately.
+func (b *Book) FindByCategoryIDs(rep repository.Repository, categoryIDs []uint, page int, size int) (*[]Book, error) {
+	return findPartitionedRows(rep, "b.category_id", categoryIDs, page, size)
+}
+
+// FindByFormatIDs returns books published in given formats, and it pages the books of each format separately.
+func (b *Book) FindByFormatIDs(rep repository.Repository, formatIDs []uint, page int, size int) (*[]Book, error) {
+	return findPartitionedRows(rep, "b.format_id", formatIDs, page, size)
+}
+
+// findPartitionedRows fetches a page of books per value of the given column by one query.
+func findPartitionedRows(rep repository.Repository, column string, ids []uint, page int, size int) (*[]Book, error) {
+	var books []Book
+	var err error
+	rowNumber := ", row_number() over (partition by " + column + " order by b.id) as row_num from book b "
+	sqlQuery := "select * from (" + strings.Replace(selectBook, " from book b ", rowNumber, 1) +
+		" where " + column + " in ? ) t where t.row_num > ? and t.row_num <= ? order by t.id "
+	args := []interface{}{ids, page * size, (page + 1) * size}
+
+	if books, err = findRows(rep, sqlQuery, "", "", args); err != nil {
+		return nil, err
+	}
+	return &books, nil
+}
+
+// FindNewArrivals returns the most recently added books.
+// If categoryID or formatID isn't zero, the books are limited to the given category or format.
+func (b *Book) FindNewArrivals(rep repository.Repository, categoryID uint, formatID uint, limit int) (*[]Book, error) {
+	var books []Book
+	var err error
+	sqlQuery := selectBook + " where 1 = 1 "
+	var args []interface{}
+
+	if categoryID != 0 {
+		sqlQuery += " and b.category_id = ? "
+		args = append(args, categoryID)
+	}
+	if formatID != 0 {
+		sqlQuery += " and b.format_id = ? "
+		args = append(args, formatID)
+	}
+	sqlQuery += orderByNewest + " limit ? "
+	args = append(args, limit)
+
+	if books, err = findRows(rep, sqlQuery, "", "", args); err != nil {
+		return nil, err
+	}
+	return &books, nil
+}
+
+// FindChanged returns the books matched given condition in order of modification.
+func (b *Book) FindChanged(rep repository.Repository, cond *ChangeCondition) (*[]Book, error) {
+	var books []Book
+	var err error
+	where, args := cond.where("b.updated_at", "b.id", "b.category_id", true)
+	sqlQuery := selectBook + " where " + where + " order by b.updated_at, b.id "
+	if cond.Limit > 0 {
+		sqlQuery += " limit ? "
+		args = append(args, cond.Limit)
+	}
+
+	if books, err = findRows(rep, sqlQuery, "", "", args); err != nil {
+		return nil, err
+	}
+	return &books, nil
+}
+
+// CountChanged returns the number of books matched given condition.
+func (b *Book) CountChanged(rep repository.Repository, cond *ChangeCondition) (int64, error) {
+	var count int64
+	where, args := cond.where("updated_at", "id", "category_id", false)
+	if err := rep.Model(&Book{}).Where(where, args...).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindEarliest returns the oldest modification timestamp of books.
+func (b *Book) FindEarliest(rep repository.Repository) optional.Option[time.Time] {
+	var book Book
+	if err := rep.Order("updated_at").First(&book).Error; err != nil {
+		return optional.None[time.Time]()
+	}
+	return optional.Some(book.UpdatedAt)
+}
+
 func findRows(rep repository.Repository, sqlQuery string, page string,
 	size string, args []interface{}) ([]Book, error) {
 	var books []Book
@@ -159,7 +324,7 @@ func (b *Book) Save(rep repository.Repository) (*Book, error) {
 
 // Update updates this book data.
 func (b *Book) Update(rep repository.Repository) (*Book, error) {
-	if err := rep.Model(Book{}).Where("id = ?", b.ID).
+	if err := rep.Model(b).Where("id = ?", b.ID).
 		Select("title", "isbn", "category_id", "format_id").Updates(b).Error; err != nil {
 		return nil, err
 	}
@@ -187,10 +352,19 @@ func convertToBook(rec *RecordBook) optional.Option[*Book] {
 		return optional.None[*Book]()
 	}
 	c := &Category{ID: rec.CategoryID, Name: rec.CategoryName}
-	f := &Format{ID: rec.FormatID, Name: rec.FormatName}
+	f := &Format{ID: rec.FormatID, Name: rec.FormatName, MediaType: rec.FormatType}
 	return optional.Some(
 		&Book{ID: rec.ID, Title: rec.Title, Isbn: rec.Isbn,
-			CategoryID: rec.CategoryID, Category: c, FormatID: rec.FormatID, Format: f})
+			CategoryID: rec.CategoryID, Category: c, FormatID: rec.FormatID, Format: f,
+			CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt})
+}
+
+// FillTimestamps sets given time to the created and updated times of the books which don't have them,
+// and returns the number of the books. The updated time is the created time if the book has it.
+func (b *Book) FillTimestamps(rep repository.Repository, now time.Time) (int64, error) {
+	result := rep.Exec("update book set created_at = coalesce(created_at, ?), "+
+		"updated_at = coalesce(updated_at, created_at, ?) where created_at is null or updated_at is null", now, now)
+	return result.RowsAffected, result.Error
 }
 
 // ToString is return string of object
@@ -24,7 +24,7 @@ func NewCategory(name string) *Category {
 // Exist returns true if a given category exits.
 func (c *Category) Exist(rep repository.Repository, id uint) (bool, error) {
 	var count int64
-	if err := rep.Where("id = ?", id).Count(&count).Error; err != nil {
+	if err := rep.Model(&Category{}).Where("id = ?", id).Count(&count).Error; err != nil {
 		return false, err
 	}
 	if count > 0 {
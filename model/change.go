@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// ChangeCondition defines the conditions to find records by their modification timestamps.
+type ChangeCondition struct {
+	// From is the inclusive lower bound of the timestamp.
+	From *time.Time
+	// Until is the exclusive upper bound of the timestamp.
+	Until *time.Time
+	// CategoryID limits the records to the given category if it isn't zero.
+	CategoryID uint
+	// AfterTime and AfterID resume the search from the next record of the given one.
+	AfterTime *time.Time
+	AfterID   uint
+	// Limit is the max number of records. If it is zero, all records are returned.
+	Limit int
+}
+
+// NewChangeCondition is constructor.
+func NewChangeCondition() *ChangeCondition {
+	return &ChangeCondition{}
+}
+
+// where returns the where clause and its arguments for the given columns.
+func (cond *ChangeCondition) where(timeColumn string, idColumn string, categoryColumn string,
+	withCursor bool) (string, []interface{}) {
+	clauses := []string{"1 = 1"}
+	var args []interface{}
+
+	if cond.From != nil {
+		clauses = append(clauses, timeColumn+" >= ?")
+		args = append(args, *cond.From)
+	}
+	if cond.Until != nil {
+		clauses = append(clauses, timeColumn+" < ?")
+		args = append(args, *cond.Until)
+	}
+	if cond.CategoryID != 0 {
+		clauses = append(clauses, categoryColumn+" = ?")
+		args = append(args, cond.CategoryID)
+	}
+	if withCursor && cond.AfterTime != nil {
+		clauses = append(clauses, "("+timeColumn+" > ? or ("+timeColumn+" = ? and "+idColumn+" > ?))")
+		args = append(args, *cond.AfterTime, *cond.AfterTime, cond.AfterID)
+	}
+	return strings.Join(clauses, " and "), args
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// DeletedBook defines struct of the record which remains after deleting a book.
+type DeletedBook struct {
+	ID         uint      `gorm:"primary_key;autoIncrement:false" json:"id"`
+	CategoryID uint      `json:"categoryId"`
+	DeletedAt  time.Time `gorm:"autoCreateTime" json:"deletedAt"`
+}
+
+// TableName returns the table name of deleted book struct, and it is used by gorm.
+func (d *DeletedBook) TableName() string {
+	return "deleted_book"
+}
+
+// NewDeletedBook is constructor.
+func NewDeletedBook(id uint, categoryID uint) *DeletedBook {
+	return &DeletedBook{ID: id, CategoryID: categoryID}
+}
+
+// FindByID returns a deleted book full matched given book's ID.
+func (d *DeletedBook) FindByID(rep repository.Repository, id uint) optional.Option[*DeletedBook] {
+	var deleted DeletedBook
+	if err := rep.Where("id = ?", id).First(&deleted).Error; err != nil {
+		return optional.None[*DeletedBook]()
+	}
+	return optional.Some(&deleted)
+}
+
+// FindChanged returns the deleted books matched given condition in order of deletion.
+func (d *DeletedBook) FindChanged(rep repository.Repository, cond *ChangeCondition) (*[]DeletedBook, error) {
+	var deleted []DeletedBook
+	where, args := cond.where("deleted_at", "id", "category_id", true)
+	query := rep.Where(where, args...).Order("deleted_at, id")
+	if cond.Limit > 0 {
+		query = query.Limit(cond.Limit)
+	}
+	if err := query.Find(&deleted).Error; err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// CountChanged returns the number of deleted books matched given condition.
+func (d *DeletedBook) CountChanged(rep repository.Repository, cond *ChangeCondition) (int64, error) {
+	var count int64
+	where, args := cond.where("deleted_at", "id", "category_id", false)
+	if err := rep.Model(&DeletedBook{}).Where(where, args...).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindEarliest returns the oldest deletion timestamp.
+func (d *DeletedBook) FindEarliest(rep repository.Repository) optional.Option[time.Time] {
+	var deleted DeletedBook
+	if err := rep.Order("deleted_at").First(&deleted).Error; err != nil {
+		return optional.None[time.Time]()
+	}
+	return optional.Some(deleted.DeletedAt)
+}
+
+// Create persists this deleted book data.
+func (d *DeletedBook) Create(rep repository.Repository) (*DeletedBook, error) {
+	if err := rep.Create(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// DeleteByID removes the record of the given book ID, and it is used when the ID is reused.
+func (d *DeletedBook) DeleteByID(rep repository.Repository, id uint) error {
+	return rep.Where("id = ?", id).Delete(&DeletedBook{}).Error
+}
+
+// ToString is return string of object
+func (d *DeletedBook) ToString() string {
+	return toString(d)
+}
@@ -1,11 +1,19 @@
 package dto
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"gopkg.in/go-playground/validator.v9"
+)
 
-// LoginDto defines a data transfer object for login.
+// LoginDto defines a data transfer object for login. The code of the two-factor authentication is used
+// only to issue the access token, because the session login asks for it in the second step.
 type LoginDto struct {
 	UserName string `json:"username"`
 	Password string `json:"password"`
+	Code     string `json:"code,omitempty"`
 }
 
 // NewLoginDto is constructor.
@@ -18,3 +26,282 @@ func (l *LoginDto) ToString() (string, error) {
 	bytes, err := json.Marshal(l)
 	return string(bytes), err
 }
+
+// LocaleDto defines a data transfer object for the preferred locale of the account.
+type LocaleDto struct {
+	Locale   string `json:"locale"`
+	messages map[string]string
+}
+
+// NewLocaleDto is constructor.
+func NewLocaleDto(messages map[string]string) *LocaleDto {
+	return &LocaleDto{messages: messages}
+}
+
+// Validate performs validation check for the locale, which must be one of the given supported locales.
+func (l *LocaleDto) Validate(locales []string) map[string]string {
+	for _, locale := range locales {
+		if l.Locale == locale {
+			return nil
+		}
+	}
+	return map[string]string{"locale": l.messages["ValidationErrMessageAccountLocale"]}
+}
+
+// ToString is return string of object
+func (l *LocaleDto) ToString() (string, error) {
+	bytes, err := json.Marshal(l)
+	return string(bytes), err
+}
+
+// AccountDto defines a data transfer object for the account managed by administrators.
+type AccountDto struct {
+	Name         string `validate:"required,min=3,max=32" json:"name"`
+	Password     string `validate:"omitempty,min=8,max=72" json:"password"`
+	Email        string `validate:"omitempty,email,max=255" json:"email"`
+	AuthorityID  uint   `validate:"required" json:"authorityId"`
+	AuthorityIDs []uint `json:"authorityIds"`
+	Disabled     bool   `json:"disabled"`
+	messages     map[string]string
+}
+
+// NewAccountDto is constructor.
+func NewAccountDto(messages map[string]string) *AccountDto {
+	return &AccountDto{messages: messages}
+}
+
+// Create creates an account model from this DTO, and the password is hashed by using bcrypt.
+func (a *AccountDto) Create() *model.Account {
+	account := model.NewAccountWithPlainPassword(a.Name, a.Password, a.AuthorityID)
+	account.Email = a.Email
+	account.Disabled = a.Disabled
+	return account
+}
+
+// Validate performs validation check for the item.
+// The password can be omitted to keep the current one when updating.
+func (a *AccountDto) Validate() map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(a)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			switch validationErrors[i].StructField() {
+			case "Name":
+				result["name"] = a.messages["ValidationErrMessageAccountName"]
+			case "Password":
+				result["password"] = a.messages["ValidationErrMessageAccountPassword"]
+			case "Email":
+				result["email"] = a.messages["ValidationErrMessageAccountEmail"]
+			case "AuthorityID":
+				result["authorityId"] = a.messages["ValidationErrMessageAccountAuthority"]
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ValidateForCreate performs validation check for the item, and the password is required.
+func (a *AccountDto) ValidateForCreate() map[string]string {
+	result := a.Validate()
+	if a.Password == "" {
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result["password"] = a.messages["ValidationErrMessageAccountPassword"]
+	}
+	return result
+}
+
+// ReferenceErrors returns the message of the authority which doesn't exist.
+func (a *AccountDto) ReferenceErrors(authorityExists bool) map[string]string {
+	result := make(map[string]string)
+	if !authorityExists {
+		result["authorityId"] = fmt.Sprintf(a.messages["ValidationErrMessageAccountAuthorityNotFound"], a.AuthorityID)
+	}
+	return result
+}
+
+// AdditionalAuthorityIDs returns the IDs of the additional authorities without duplicates and the primary one.
+func (a *AccountDto) AdditionalAuthorityIDs() []uint {
+	var ids []uint
+	seen := map[uint]bool{a.AuthorityID: true}
+	for _, id := range a.AuthorityIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// AdditionalReferenceErrors returns the message of the additional authority which doesn't exist.
+func (a *AccountDto) AdditionalReferenceErrors(authorityID uint) map[string]string {
+	return map[string]string{
+		"authorityIds": fmt.Sprintf(a.messages["ValidationErrMessageAccountAuthorityNotFound"], authorityID),
+	}
+}
+
+// ToString is return string of object
+func (a *AccountDto) ToString() (string, error) {
+	bytes, err := json.Marshal(a)
+	return string(bytes), err
+}
+
+// PasswordDto defines a data transfer object for changing the password of the logged-in account.
+type PasswordDto struct {
+	CurrentPassword string `validate:"required" json:"currentPassword"`
+	NewPassword     string `validate:"required,min=8,max=72" json:"newPassword"`
+	messages        map[string]string
+}
+
+// NewPasswordDto is constructor.
+func NewPasswordDto(messages map[string]string) *PasswordDto {
+	return &PasswordDto{messages: messages}
+}
+
+// Validate performs validation check for the item.
+func (p *PasswordDto) Validate() map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(p)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			switch validationErrors[i].StructField() {
+			case "CurrentPassword":
+				result["currentPassword"] = p.messages["ValidationErrMessageAccountCurrentPassword"]
+			case "NewPassword":
+				result["newPassword"] = p.messages["ValidationErrMessageAccountPassword"]
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// CurrentPasswordErrors returns the message of the current password which is incorrect.
+func (p *PasswordDto) CurrentPasswordErrors() map[string]string {
+	return map[string]string{"currentPassword": p.messages["ValidationErrMessageAccountCurrentPassword"]}
+}
+
+// ToString is return string of object
+func (p *PasswordDto) ToString() (string, error) {
+	bytes, err := json.Marshal(p)
+	return string(bytes), err
+}
+
+// PasswordResetRequestDto defines a data transfer object for requesting the password reset by the username or the email.
+type PasswordResetRequestDto struct {
+	UserName string `json:"username"`
+	Email    string `json:"email"`
+	messages map[string]string
+}
+
+// NewPasswordResetRequestDto is constructor.
+func NewPasswordResetRequestDto(messages map[string]string) *PasswordResetRequestDto {
+	return &PasswordResetRequestDto{messages: messages}
+}
+
+// Validate performs validation check for the item, and either the username or the email is required.
+func (p *PasswordResetRequestDto) Validate() map[string]string {
+	if p.UserName == "" && p.Email == "" {
+		return map[string]string{"username": p.messages["ValidationErrMessageAccountResetRequest"]}
+	}
+	return nil
+}
+
+// ToString is return string of object
+func (p *PasswordResetRequestDto) ToString() (string, error) {
+	bytes, err := json.Marshal(p)
+	return string(bytes), err
+}
+
+// PasswordResetDto defines a data transfer object for setting a new password by the reset token.
+type PasswordResetDto struct {
+	Token       string `validate:"required" json:"token"`
+	NewPassword string `validate:"required,min=8,max=72" json:"newPassword"`
+	messages    map[string]string
+}
+
+// NewPasswordResetDto is constructor.
+func NewPasswordResetDto(messages map[string]string) *PasswordResetDto {
+	return &PasswordResetDto{messages: messages}
+}
+
+// Validate performs validation check for the item.
+func (p *PasswordResetDto) Validate() map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(p)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			switch validationErrors[i].StructField() {
+			case "Token":
+				result["token"] = p.messages["ValidationErrMessageAccountResetToken"]
+			case "NewPassword":
+				result["newPassword"] = p.messages["ValidationErrMessageAccountPassword"]
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// TokenErrors returns the message of the token which is invalid, expired or already used.
+func (p *PasswordResetDto) TokenErrors() map[string]string {
+	return map[string]string{"token": p.messages["ValidationErrMessageAccountResetToken"]}
+}
+
+// ToString is return string of object
+func (p *PasswordResetDto) ToString() (string, error) {
+	bytes, err := json.Marshal(p)
+	return string(bytes), err
+}
+
+// RefreshTokenDto defines a data transfer object for refreshing and revoking the refresh token.
+type RefreshTokenDto struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewRefreshTokenDto is constructor.
+func NewRefreshTokenDto() *RefreshTokenDto {
+	return &RefreshTokenDto{}
+}
+
+// ToString is return string of object
+func (r *RefreshTokenDto) ToString() (string, error) {
+	bytes, err := json.Marshal(r)
+	return string(bytes), err
+}
+
+// OIDCCallbackDto defines a data transfer object for the callback of the OpenID Connect provider.
+type OIDCCallbackDto struct {
+	Code             string `query:"code" json:"code"`
+	State            string `query:"state" json:"state"`
+	Error            string `query:"error" json:"error"`
+	ErrorDescription string `query:"error_description" json:"error_description"`
+}
+
+// NewOIDCCallbackDto is constructor.
+func NewOIDCCallbackDto() *OIDCCallbackDto {
+	return &OIDCCallbackDto{}
+}
+
+// ToString is return string of object
+func (o *OIDCCallbackDto) ToString() (string, error) {
+	bytes, err := json.Marshal(o)
+	return string(bytes), err
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"gopkg.in/go-playground/validator.v9"
+	"time"
+)
+
+// APIKeyDto defines a data transfer object for creating the API key.
+type APIKeyDto struct {
+	Name      string     `validate:"required,min=1,max=64" json:"name"`
+	Scopes    []string   `validate:"required,min=1" json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	messages  map[string]string
+}
+
+// NewAPIKeyDto is constructor.
+func NewAPIKeyDto(messages map[string]string) *APIKeyDto {
+	return &APIKeyDto{messages: messages}
+}
+
+// Create creates an API key model of the account from this DTO.
+func (k *APIKeyDto) Create(accountID uint, prefix string, keyHash string) *model.APIKey {
+	return model.NewAPIKey(accountID, k.Name, prefix, keyHash, k.UniqueScopes(), k.ExpiresAt)
+}
+
+// Validate performs validation check for the item. The expiration date can be omitted,
+// and the key doesn't expire then.
+func (k *APIKeyDto) Validate(now time.Time) map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(k)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			switch validationErrors[i].StructField() {
+			case "Name":
+				result["name"] = k.messages["ValidationErrMessageAPIKeyName"]
+			case "Scopes":
+				result["scopes"] = k.messages["ValidationErrMessageAPIKeyScopes"]
+			}
+		}
+	}
+	for _, scope := range k.Scopes {
+		if !model.IsPermission(scope) {
+			result["scopes"] = k.messages["ValidationErrMessageAPIKeyScopes"]
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		result["expiresAt"] = k.messages["ValidationErrMessageAPIKeyExpiresAt"]
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ScopeErrors returns the message of the scopes which the account doesn't have.
+func (k *APIKeyDto) ScopeErrors() map[string]string {
+	return map[string]string{"scopes": k.messages["ValidationErrMessageAPIKeyScopes"]}
+}
+
+// UniqueScopes returns the scopes without duplicates.
+func (k *APIKeyDto) UniqueScopes() []string {
+	scopes := []string{}
+	seen := make(map[string]bool)
+	for _, scope := range k.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// ToString is return string of object
+func (k *APIKeyDto) ToString() (string, error) {
+	bytes, err := json.Marshal(k)
+	return string(bytes), err
+}
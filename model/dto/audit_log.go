@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"time"
+)
+
+// auditLogDateFormat is the format of the date accepted instead of RFC 3339 by the filters of the audit log.
+const auditLogDateFormat = "2006-01-02"
+
+// AuditLogFilterDto defines a data transfer object for filtering the records of the audit log.
+type AuditLogFilterDto struct {
+	Event     string `query:"event"`
+	Outcome   string `query:"outcome"`
+	ActorName string `query:"actor"`
+	Target    string `query:"target"`
+	RemoteIP  string `query:"ip"`
+	From      string `query:"from"`
+	Until     string `query:"until"`
+	messages  map[string]string
+}
+
+// NewAuditLogFilterDto is constructor.
+func NewAuditLogFilterDto(messages map[string]string) *AuditLogFilterDto {
+	return &AuditLogFilterDto{messages: messages}
+}
+
+// Create returns the conditions of the filters, or the messages of the invalid fields.
+// The times are given in RFC 3339 or as the dates in UTC.
+func (a *AuditLogFilterDto) Create() (*model.AuditLogCondition, map[string]string) {
+	cond := &model.AuditLogCondition{Event: a.Event, Outcome: a.Outcome, ActorName: a.ActorName, Target: a.Target,
+		RemoteIP: a.RemoteIP}
+	fields := map[string]string{}
+	var ok bool
+	if cond.From, ok = parseAuditLogTime(a.From); !ok {
+		fields["from"] = a.messages["ValidationErrMessageAuditLogTime"]
+	}
+	if cond.Until, ok = parseAuditLogTime(a.Until); !ok {
+		fields["until"] = a.messages["ValidationErrMessageAuditLogTime"]
+	}
+	if len(fields) > 0 {
+		return nil, fields
+	}
+	return cond, nil
+}
+
+// ToString is return string of object
+func (a *AuditLogFilterDto) ToString() (string, error) {
+	bytes, err := json.Marshal(a)
+	return string(bytes), err
+}
+
+func parseAuditLogTime(value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+	if t, err := time.Parse(auditLogDateFormat, value); err == nil {
+		return &t, true
+	}
+	return nil, false
+}
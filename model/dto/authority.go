@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// AuthorityDto defines a data transfer object for the authority and its permissions.
+type AuthorityDto struct {
+	Name        string   `validate:"required,min=2,max=32" json:"name"`
+	Permissions []string `json:"permissions"`
+	messages    map[string]string
+}
+
+// NewAuthorityDto is constructor.
+func NewAuthorityDto(messages map[string]string) *AuthorityDto {
+	return &AuthorityDto{messages: messages}
+}
+
+// Create creates an authority model from this DTO.
+func (a *AuthorityDto) Create() *model.Authority {
+	return model.NewAuthority(a.Name)
+}
+
+// Validate performs validation check for the name and the permissions.
+func (a *AuthorityDto) Validate() map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(a)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			if validationErrors[i].StructField() == "Name" {
+				result["name"] = a.messages["ValidationErrMessageAuthorityName"]
+			}
+		}
+	}
+	for key, message := range a.ValidatePermissions() {
+		result[key] = message
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ValidatePermissions performs validation check for the permissions, which must be the permissions of this application.
+func (a *AuthorityDto) ValidatePermissions() map[string]string {
+	for _, permission := range a.Permissions {
+		if !model.IsPermission(permission) {
+			return map[string]string{"permissions": a.messages["ValidationErrMessageAuthorityPermissions"]}
+		}
+	}
+	return nil
+}
+
+// UniquePermissions returns the permissions without duplicates.
+func (a *AuthorityDto) UniquePermissions() []string {
+	permissions := []string{}
+	seen := make(map[string]bool)
+	for _, permission := range a.Permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// ToString is return string of object
+func (a *AuthorityDto) ToString() (string, error) {
+	bytes, err := json.Marshal(a)
+	return string(bytes), err
+}
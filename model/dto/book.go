@@ -3,6 +3,7 @@ package dto
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/lyh-demo/go-webapp-demo/model"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -71,6 +72,18 @@ func createErrorMessages(b *BookDto, errors validator.ValidationErrors) map[stri
 	return result
 }
 
+// ReferenceErrors returns the messages of the category and the format which don't exist.
+func (b *BookDto) ReferenceErrors(categoryExists bool, formatExists bool) map[string]string {
+	result := make(map[string]string)
+	if !categoryExists {
+		result["categoryId"] = fmt.Sprintf(b.messages["ValidationErrMessageBookCategoryNotFound"], b.CategoryID)
+	}
+	if !formatExists {
+		result["formatId"] = fmt.Sprintf(b.messages["ValidationErrMessageBookFormatNotFound"], b.FormatID)
+	}
+	return result
+}
+
 // ToString is return string of object
 func (b *BookDto) ToString() (string, error) {
 	bytes, err := json.Marshal(b)
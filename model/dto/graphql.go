@@ -0,0 +1,21 @@
+package dto
+
+import "encoding/json"
+
+// GraphQLDto defines a data transfer object for GraphQL requests.
+type GraphQLDto struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// NewGraphQLDto is constructor.
+func NewGraphQLDto() *GraphQLDto {
+	return &GraphQLDto{}
+}
+
+// ToString is return string of object
+func (g *GraphQLDto) ToString() (string, error) {
+	bytes, err := json.Marshal(g)
+	return string(bytes), err
+}
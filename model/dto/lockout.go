@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"encoding/json"
+)
+
+// UnlockDto defines a data transfer object for releasing the lockout of the login.
+type UnlockDto struct {
+	UserName string `json:"username"`
+	RemoteIP string `json:"remoteIp"`
+	messages map[string]string
+}
+
+// NewUnlockDto is constructor.
+func NewUnlockDto(messages map[string]string) *UnlockDto {
+	return &UnlockDto{messages: messages}
+}
+
+// Validate performs validation check for the item. Either the username or the remote IP address is required.
+func (u *UnlockDto) Validate() map[string]string {
+	if u.UserName == "" && u.RemoteIP == "" {
+		return map[string]string{"username": u.messages["ValidationErrMessageLockoutUnlock"]}
+	}
+	return nil
+}
+
+// ToString is return string of object
+func (u *UnlockDto) ToString() (string, error) {
+	bytes, err := json.Marshal(u)
+	return string(bytes), err
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// TwoFactorCodeDto defines a data transfer object for the TOTP code or the recovery code.
+type TwoFactorCodeDto struct {
+	Code     string `validate:"required" json:"code"`
+	messages map[string]string
+}
+
+// NewTwoFactorCodeDto is constructor.
+func NewTwoFactorCodeDto(messages map[string]string) *TwoFactorCodeDto {
+	return &TwoFactorCodeDto{messages: messages}
+}
+
+// Validate performs validation check for the item.
+func (t *TwoFactorCodeDto) Validate() map[string]string {
+	if err := validator.New().Struct(t); err != nil {
+		return t.CodeErrors()
+	}
+	return nil
+}
+
+// CodeErrors returns the message of the code which is incorrect.
+func (t *TwoFactorCodeDto) CodeErrors() map[string]string {
+	return map[string]string{"code": t.messages["ValidationErrMessageTwoFactorCode"]}
+}
+
+// ToString is return string of object
+func (t *TwoFactorCodeDto) ToString() (string, error) {
+	bytes, err := json.Marshal(t)
+	return string(bytes), err
+}
+
+// TwoFactorDisableDto defines a data transfer object for disabling the two-factor authentication.
+type TwoFactorDisableDto struct {
+	Password string `validate:"required" json:"password"`
+	Code     string `validate:"required" json:"code"`
+	messages map[string]string
+}
+
+// NewTwoFactorDisableDto is constructor.
+func NewTwoFactorDisableDto(messages map[string]string) *TwoFactorDisableDto {
+	return &TwoFactorDisableDto{messages: messages}
+}
+
+// Validate performs validation check for the item.
+func (t *TwoFactorDisableDto) Validate() map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(t)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			switch validationErrors[i].StructField() {
+			case "Password":
+				result["password"] = t.messages["ValidationErrMessageAccountCurrentPassword"]
+			case "Code":
+				result["code"] = t.messages["ValidationErrMessageTwoFactorCode"]
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// PasswordErrors returns the message of the password which is incorrect.
+func (t *TwoFactorDisableDto) PasswordErrors() map[string]string {
+	return map[string]string{"password": t.messages["ValidationErrMessageAccountCurrentPassword"]}
+}
+
+// CodeErrors returns the message of the code which is incorrect.
+func (t *TwoFactorDisableDto) CodeErrors() map[string]string {
+	return map[string]string{"code": t.messages["ValidationErrMessageTwoFactorCode"]}
+}
+
+// ToString is return string of object
+func (t *TwoFactorDisableDto) ToString() (string, error) {
+	bytes, err := json.Marshal(t)
+	return string(bytes), err
+}
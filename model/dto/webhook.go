@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/webhook"
+	"gopkg.in/go-playground/validator.v9"
+	"strings"
+)
+
+// WebhookDto defines a data transfer object for webhook.
+type WebhookDto struct {
+	URL      string   `validate:"required,url,max=255" json:"url"`
+	Events   []string `validate:"required,min=1" json:"events"`
+	Secret   string   `validate:"omitempty,min=16,max=255" json:"secret"`
+	Active   *bool    `json:"active"`
+	messages map[string]string
+}
+
+// NewWebhookDto is constructor.
+func NewWebhookDto(messages map[string]string) *WebhookDto {
+	return &WebhookDto{messages: messages}
+}
+
+// Create creates a webhook model from this DTO. The webhook is active unless it is specified.
+func (w *WebhookDto) Create() *model.Webhook {
+	active := true
+	if w.Active != nil {
+		active = *w.Active
+	}
+	return model.NewWebhook(w.URL, w.Events, w.Secret, active)
+}
+
+// Validate performs validation check for the item.
+// The secret can be omitted to keep the current one when updating.
+func (w *WebhookDto) Validate() map[string]string {
+	result := make(map[string]string)
+	err := validator.New().Struct(w)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for i := range validationErrors {
+			switch validationErrors[i].StructField() {
+			case "URL":
+				result["url"] = w.messages["ValidationErrMessageWebhookURL"]
+			case "Events":
+				result["events"] = w.messages["ValidationErrMessageWebhookEvents"]
+			case "Secret":
+				result["secret"] = w.messages["ValidationErrMessageWebhookSecret"]
+			}
+		}
+	}
+
+	if _, ok := result["url"]; !ok && !strings.HasPrefix(w.URL, "http://") && !strings.HasPrefix(w.URL, "https://") {
+		result["url"] = w.messages["ValidationErrMessageWebhookURL"]
+	}
+	for _, event := range w.Events {
+		if !webhook.IsEventType(event) {
+			result["events"] = w.messages["ValidationErrMessageWebhookEvents"]
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ValidateForCreate performs validation check for the item, and the secret is required.
+func (w *WebhookDto) ValidateForCreate() map[string]string {
+	result := w.Validate()
+	if w.Secret == "" {
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result["secret"] = w.messages["ValidationErrMessageWebhookSecret"]
+	}
+	return result
+}
+
+// ToString is return string of object
+func (w *WebhookDto) ToString() (string, error) {
+	bytes, err := json.Marshal(w)
+	return string(bytes), err
+}
@@ -5,10 +5,14 @@ import (
 	"github.com/moznion/go-optional"
 )
 
+// MediaTypeEPUB is the media type of the EPUB files.
+const MediaTypeEPUB = "application/epub+zip"
+
 // Format defines struct of format data.
 type Format struct {
-	ID   uint   `gorm:"primary_key" json:"id"`
-	Name string `validate:"required" json:"name"`
+	ID        uint   `gorm:"primary_key" json:"id"`
+	Name      string `validate:"required" json:"name"`
+	MediaType string `json:"mediaType"`
 }
 
 // TableName returns the table name of format struct and it is used by gorm.
@@ -16,9 +20,27 @@ func (f *Format) TableName() string {
 	return "format_master"
 }
 
-// NewFormat is constructor
-func NewFormat(name string) *Format {
-	return &Format{Name: name}
+// NewFormat is constructor. The media type is the type of the files of an e-book, and it is empty for
+// the formats other than e-books.
+func NewFormat(name string, mediaType string) *Format {
+	return &Format{Name: name, MediaType: mediaType}
+}
+
+// IsEBook returns true if this format is an e-book format, which has the media type of its files.
+func (f *Format) IsEBook() bool {
+	return f.MediaType != ""
+}
+
+// Exist returns true if a given format exits.
+func (f *Format) Exist(rep repository.Repository, id uint) (bool, error) {
+	var count int64
+	if err := rep.Model(&Format{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	return false, nil
 }
 
 // FindByID returns a format full matched given format's ID.
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+const (
+	// LoginAttemptUsername is the kind of the failed login attempts counted by the username.
+	LoginAttemptUsername = "username"
+	// LoginAttemptRemoteIP is the kind of the failed login attempts counted by the remote IP address.
+	LoginAttemptRemoteIP = "ip"
+	// LoginAttemptReset is the kind of the password reset requests counted by the username or the email.
+	LoginAttemptReset = "reset"
+	// LoginAttemptResetIP is the kind of the password reset requests counted by the remote IP address.
+	LoginAttemptResetIP = "reset-ip"
+)
+
+// LoginAttempt defines struct of the failed login attempts of a username or a remote IP address.
+// The attempts are locked out until the time if the failures reach the limit.
+type LoginAttempt struct {
+	ID           uint       `gorm:"primary_key" json:"id"`
+	Kind         string     `gorm:"uniqueIndex:idx_login_attempt_kind_value" json:"kind"`
+	Value        string     `gorm:"uniqueIndex:idx_login_attempt_kind_value" json:"value"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"lastFailedAt"`
+	LockedUntil  *time.Time `json:"lockedUntil"`
+}
+
+// TableName returns the table name of login attempt struct, and it is used by gorm.
+func (l *LoginAttempt) TableName() string {
+	return "login_attempt"
+}
+
+// NewLoginAttempt is constructor.
+func NewLoginAttempt(kind string, value string) *LoginAttempt {
+	return &LoginAttempt{Kind: kind, Value: value}
+}
+
+// IsLocked returns true if the attempts are locked out at given time.
+func (l *LoginAttempt) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(now)
+}
+
+// FindByKindAndValue returns the failed login attempts of given username or remote IP address.
+func (l *LoginAttempt) FindByKindAndValue(rep repository.Repository, kind string,
+	value string) optional.Option[*LoginAttempt] {
+	var attempt LoginAttempt
+	if err := rep.Where("kind = ? and value = ?", kind, value).First(&attempt).Error; err != nil {
+		return optional.None[*LoginAttempt]()
+	}
+	return optional.Some(&attempt)
+}
+
+// FindLocked returns the failed login attempts which are locked out at given time, in order of the lock time.
+func (l *LoginAttempt) FindLocked(rep repository.Repository, now time.Time) (*[]LoginAttempt, error) {
+	var attempts []LoginAttempt
+	if err := rep.Where("locked_until > ?", now).Order("locked_until").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+// Save persists this login attempts, and it creates them if they haven't been persisted yet.
+func (l *LoginAttempt) Save(rep repository.Repository) (*LoginAttempt, error) {
+	if err := rep.Save(l).Error; err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// DeleteByKindAndValue deletes the failed login attempts of given username or remote IP address.
+func (l *LoginAttempt) DeleteByKindAndValue(rep repository.Repository, kind string, value string) error {
+	return rep.Where("kind = ? and value = ?", kind, value).Delete(&LoginAttempt{}).Error
+}
+
+// DeleteBefore deletes the failed login attempts which aren't locked out and failed last before given time.
+func (l *LoginAttempt) DeleteBefore(rep repository.Repository, t time.Time) error {
+	return rep.Where("last_failed_at < ? and (locked_until is null or locked_until < ?)", t, t).
+		Delete(&LoginAttempt{}).Error
+}
+
+// ToString is return string of object
+func (l *LoginAttempt) ToString() string {
+	return toString(l)
+}
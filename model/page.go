@@ -15,3 +15,19 @@ type Page struct {
 func NewPage() *Page {
 	return &Page{}
 }
+
+// AccountPage defines struct of pagination data of accounts.
+type AccountPage struct {
+	Content          *[]Account `json:"content"`
+	Last             bool       `json:"last"`
+	TotalElements    int        `json:"totalElements"`
+	TotalPages       int        `json:"totalPages"`
+	Size             int        `json:"size"`
+	Page             int        `json:"page"`
+	NumberOfElements int        `json:"numberOfElements"`
+}
+
+// NewAccountPage is constructor
+func NewAccountPage() *AccountPage {
+	return &AccountPage{}
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// PasswordResetToken defines struct of the token to reset the password of an account.
+// Only the hash of the token is persisted, and the token can be used once before it expires.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primary_key" json:"id"`
+	AccountID uint       `gorm:"index" json:"accountId"`
+	TokenHash string     `gorm:"uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName returns the table name of password reset token struct, and it is used by gorm.
+func (p *PasswordResetToken) TableName() string {
+	return "password_reset_token"
+}
+
+// NewPasswordResetToken is constructor.
+func NewPasswordResetToken(accountID uint, tokenHash string, expiresAt time.Time) *PasswordResetToken {
+	return &PasswordResetToken{AccountID: accountID, TokenHash: tokenHash, ExpiresAt: expiresAt}
+}
+
+// FindByTokenHash returns the token which has given hash and hasn't been used or expired yet.
+func (p *PasswordResetToken) FindByTokenHash(rep repository.Repository, tokenHash string,
+	now time.Time) optional.Option[*PasswordResetToken] {
+	var token PasswordResetToken
+	if err := rep.Where("token_hash = ? and used_at is null and expires_at > ?", tokenHash, now).
+		First(&token).Error; err != nil {
+		return optional.None[*PasswordResetToken]()
+	}
+	return optional.Some(&token)
+}
+
+// Create persists this token data.
+func (p *PasswordResetToken) Create(rep repository.Repository) (*PasswordResetToken, error) {
+	if err := rep.Create(p).Error; err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Use marks this token as used. It returns false if the token has already been used by another request.
+func (p *PasswordResetToken) Use(rep repository.Repository, now time.Time) (bool, error) {
+	result := rep.Model(&PasswordResetToken{}).Where("id = ? and used_at is null", p.ID).Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	p.UsedAt = &now
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByAccountID deletes the tokens of given account, so that only the latest token can be used.
+func (p *PasswordResetToken) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&PasswordResetToken{}).Error
+}
+
+// ToString is return string of object
+func (p *PasswordResetToken) ToString() string {
+	return toString(p)
+}
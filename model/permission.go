@@ -0,0 +1,149 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+)
+
+const (
+	// PermissionBookRead allows to read the books and their labels, feeds and events.
+	PermissionBookRead = "book:read"
+	// PermissionBookWrite allows to create, update and delete the books.
+	PermissionBookWrite = "book:write"
+	// PermissionCategoryRead allows to read the categories.
+	PermissionCategoryRead = "category:read"
+	// PermissionFormatRead allows to read the formats.
+	PermissionFormatRead = "format:read"
+	// PermissionReportRead allows to read the reports of the catalog.
+	PermissionReportRead = "report:read"
+	// PermissionWebhookManage allows to manage the webhooks and their deliveries.
+	PermissionWebhookManage = "webhook:manage"
+	// PermissionBackupManage allows to download and restore the backup archive.
+	PermissionBackupManage = "backup:manage"
+	// PermissionAccountManage allows to manage the accounts.
+	PermissionAccountManage = "account:manage"
+	// PermissionRoleManage allows to manage the authorities and their permissions.
+	PermissionRoleManage = "role:manage"
+)
+
+// Permission defines struct of a permission which can be assigned to authorities.
+// The permissions are defined by the application, and only their assignments are persisted.
+type Permission struct {
+	Name        string `json:"name" example:"book:read"`
+	Description string `json:"description" example:"Read the books and their labels, feeds and events."`
+}
+
+// Permissions is the list of all permissions of this application.
+var Permissions = []Permission{
+	{Name: PermissionBookRead, Description: "Read the books and their labels, feeds and events."},
+	{Name: PermissionBookWrite, Description: "Create, update and delete the books."},
+	{Name: PermissionCategoryRead, Description: "Read the categories."},
+	{Name: PermissionFormatRead, Description: "Read the formats."},
+	{Name: PermissionReportRead, Description: "Read the reports of the catalog."},
+	{Name: PermissionWebhookManage, Description: "Manage the webhooks and their deliveries."},
+	{Name: PermissionBackupManage, Description: "Download and restore the backup archive."},
+	{Name: PermissionAccountManage, Description: "Manage the accounts."},
+	{Name: PermissionRoleManage, Description: "Manage the authorities and their permissions."},
+}
+
+// defaultPermissions are the permissions assigned to the built-in authorities when they have none.
+// The admin authority always has all permissions, so it isn't listed.
+var defaultPermissions = map[string][]string{
+	AuthorityUser: {PermissionBookRead, PermissionCategoryRead, PermissionFormatRead},
+}
+
+// IsPermission returns true if a given name is one of the permissions of this application.
+func IsPermission(name string) bool {
+	for _, p := range Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AllPermissionNames returns the names of all permissions.
+func AllPermissionNames() []string {
+	names := make([]string, 0, len(Permissions))
+	for _, p := range Permissions {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// AuthorityPermission defines struct of the assignment of a permission to an authority.
+type AuthorityPermission struct {
+	ID          uint   `gorm:"primary_key" json:"id"`
+	AuthorityID uint   `gorm:"index" json:"authorityId"`
+	Permission  string `json:"permission"`
+}
+
+// TableName returns the table name of authority permission struct, and it is used by gorm.
+func (p *AuthorityPermission) TableName() string {
+	return "authority_permission"
+}
+
+// FindByAuthorityID returns the names of the permissions assigned to given authority.
+func (p *AuthorityPermission) FindByAuthorityID(rep repository.Repository, authorityID uint) ([]string, error) {
+	permissions := []string{}
+	if err := rep.Model(&AuthorityPermission{}).Where("authority_id = ?", authorityID).Order("id").
+		Pluck("permission", &permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// FindByAccountID returns the names of the permissions of all authorities which given account holds.
+func (p *AuthorityPermission) FindByAccountID(rep repository.Repository, accountID uint) ([]string, error) {
+	permissions := []string{}
+	if err := rep.Raw("select distinct p.permission from authority_permission p where p.authority_id in "+
+		"(select a.authority_id from account_master a where a.id = ? "+
+		"union select r.authority_id from account_authority r where r.account_id = ?) order by p.permission",
+		accountID, accountID).Scan(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// ReplaceByAuthorityID replaces the permissions assigned to given authority.
+func (p *AuthorityPermission) ReplaceByAuthorityID(rep repository.Repository, authorityID uint,
+	permissions []string) error {
+	if err := rep.Where("authority_id = ?", authorityID).Delete(&AuthorityPermission{}).Error; err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if err := rep.Create(&AuthorityPermission{AuthorityID: authorityID, Permission: permission}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitDefaults assigns the default permissions to the built-in authorities which don't have any permissions,
+// such as the authorities restored from the archives of the first version of the backup.
+func (p *AuthorityPermission) InitDefaults(rep repository.Repository) error {
+	for name, permissions := range defaultPermissions {
+		var authority Authority
+		if err := rep.Where("name = ?", name).Limit(1).Find(&authority).Error; err != nil {
+			return err
+		}
+		if authority.ID == 0 {
+			continue
+		}
+		var count int64
+		if err := rep.Model(&AuthorityPermission{}).Where("authority_id = ?", authority.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			if err := p.ReplaceByAuthorityID(rep, authority.ID, permissions); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ToString is return string of object
+func (p *AuthorityPermission) ToString() string {
+	return toString(p)
+}
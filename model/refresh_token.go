@@ -0,0 +1,86 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// RefreshToken defines struct of the token to issue a new access token without the password.
+// Only the hash of the token is persisted, and the token is revoked when it is used, so that a new one is issued.
+type RefreshToken struct {
+	ID             uint       `gorm:"primary_key" json:"id"`
+	AccountID      uint       `gorm:"index" json:"accountId"`
+	TokenHash      string     `gorm:"uniqueIndex" json:"-"`
+	SessionVersion uint       `json:"-"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	RevokedAt      *time.Time `json:"revokedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// TokenResponse defines struct of the response issuing the access token, which is defined by RFC 6749.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TableName returns the table name of refresh token struct, and it is used by gorm.
+func (r *RefreshToken) TableName() string {
+	return "refresh_token"
+}
+
+// NewRefreshToken is constructor.
+func NewRefreshToken(account *Account, tokenHash string, expiresAt time.Time) *RefreshToken {
+	return &RefreshToken{AccountID: account.ID, TokenHash: tokenHash, SessionVersion: account.SessionVersion,
+		ExpiresAt: expiresAt}
+}
+
+// FindByTokenHash returns the token which has given hash, including the revoked and expired ones.
+func (r *RefreshToken) FindByTokenHash(rep repository.Repository, tokenHash string) optional.Option[*RefreshToken] {
+	var token RefreshToken
+	if err := rep.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return optional.None[*RefreshToken]()
+	}
+	return optional.Some(&token)
+}
+
+// Create persists this token data.
+func (r *RefreshToken) Create(rep repository.Repository) (*RefreshToken, error) {
+	if err := rep.Create(r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Revoke marks this token as revoked. It returns false if the token has already been revoked by another request.
+func (r *RefreshToken) Revoke(rep repository.Repository, now time.Time) (bool, error) {
+	result := rep.Model(&RefreshToken{}).Where("id = ? and revoked_at is null", r.ID).Update("revoked_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	r.RevokedAt = &now
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeByAccountID revokes all tokens of given account.
+func (r *RefreshToken) RevokeByAccountID(rep repository.Repository, accountID uint, now time.Time) error {
+	return rep.Model(&RefreshToken{}).Where("account_id = ? and revoked_at is null", accountID).
+		Update("revoked_at", now).Error
+}
+
+// DeleteByAccountID deletes all tokens of given account.
+func (r *RefreshToken) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&RefreshToken{}).Error
+}
+
+// IsActive returns true if this token hasn't been revoked or expired yet.
+func (r *RefreshToken) IsActive(now time.Time) bool {
+	return r.RevokedAt == nil && r.ExpiresAt.After(now)
+}
+
+// ToString is return string of object
+func (r *RefreshToken) ToString() string {
+	return toString(r)
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"time"
+)
+
+// Summary defines struct of the report which summarizes the catalog.
+type Summary struct {
+	TotalBooks  int64          `json:"totalBooks"`
+	ByCategory  []GroupCount   `json:"byCategory"`
+	ByFormat    []GroupCount   `json:"byFormat"`
+	ByMonth     []MonthlyCount `json:"byMonth"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+}
+
+// GroupCount defines struct of the number of books belonging to a category or a format.
+type GroupCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// MonthlyCount defines struct of the number of books added in a month.
+type MonthlyCount struct {
+	Month string `json:"month"`
+	Count int64  `json:"count"`
+}
+
+const (
+	countByCategory = "select c.id as id, c.name as name, count(b.id) as count " +
+		"from category_master c left join book b on b.category_id = c.id group by c.id, c.name order by c.id"
+	countByFormat = "select f.id as id, f.name as name, count(b.id) as count " +
+		"from format_master f left join book b on b.format_id = f.id group by f.id, f.name order by f.id"
+	countByMonth = "select %s as month, count(b.id) as count from book b group by month order by month"
+)
+
+// NewSummary is constructor.
+func NewSummary() *Summary {
+	return &Summary{
+		ByCategory:  []GroupCount{},
+		ByFormat:    []GroupCount{},
+		ByMonth:     []MonthlyCount{},
+		GeneratedAt: time.Now().UTC(),
+	}
+}
+
+// Aggregate fills this summary by the aggregate queries of the database.
+// The dialect is needed because the functions which format a date differ among the databases.
+func (s *Summary) Aggregate(rep repository.Repository, dialect string) (*Summary, error) {
+	if err := rep.Model(&Book{}).Count(&s.TotalBooks).Error; err != nil {
+		return nil, err
+	}
+	if err := rep.Raw(countByCategory).Scan(&s.ByCategory).Error; err != nil {
+		return nil, err
+	}
+	if err := rep.Raw(countByFormat).Scan(&s.ByFormat).Error; err != nil {
+		return nil, err
+	}
+	if err := rep.Raw(fmt.Sprintf(countByMonth, monthExpression(dialect, "b.created_at"))).
+		Scan(&s.ByMonth).Error; err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// monthExpression returns the SQL expression which formats the given column as "YYYY-MM".
+func monthExpression(dialect string, column string) string {
+	switch dialect {
+	case repository.POSTGRES:
+		return "to_char(" + column + ", 'YYYY-MM')"
+	case repository.MYSQL:
+		return "date_format(" + column + ", '%Y-%m')"
+	default:
+		return "strftime('%Y-%m', " + column + ")"
+	}
+}
+
+// ToString is return string of object
+func (s *Summary) ToString() string {
+	return toString(s)
+}
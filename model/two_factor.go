@@ -0,0 +1,169 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// TwoFactor defines struct of the TOTP secret of an account. The secret is enabled after the account
+// confirms a code of it, and the last used time step is kept so that a code can't be used twice.
+type TwoFactor struct {
+	ID          uint      `gorm:"primary_key" json:"id"`
+	AccountID   uint      `gorm:"uniqueIndex" json:"accountId"`
+	Secret      string    `json:"-"`
+	Enabled     bool      `json:"enabled"`
+	LastCounter int64     `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// RecoveryCode defines struct of the one-time code to login instead of the TOTP code.
+// Only the hash of the code is persisted.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primary_key" json:"id"`
+	AccountID uint       `gorm:"index" json:"accountId"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// PendingLogin represents the state of the login which has passed the password and waits for the second factor.
+// It is kept in the session until the code is verified.
+type PendingLogin struct {
+	AccountID          uint      `json:"accountId"`
+	SessionVersion     uint      `json:"sessionVersion"`
+	EnrollmentRequired bool      `json:"enrollmentRequired"`
+	Attempts           int       `json:"attempts"`
+	ExpiresAt          time.Time `json:"expiresAt"`
+}
+
+// LoginChallenge defines struct of the response of the login which requires the second factor.
+type LoginChallenge struct {
+	TwoFactorRequired  bool `json:"twoFactorRequired"`
+	EnrollmentRequired bool `json:"enrollmentRequired"`
+}
+
+// TwoFactorStatus defines struct of the status of the two-factor authentication of an account.
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// TwoFactorEnrollment defines struct of the secret to register to the authenticator app.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+	QRCode          string `json:"qrCode"`
+}
+
+// RecoveryCodes defines struct of the recovery codes, which are shown only when they are generated.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TableName returns the table name of two factor struct, and it is used by gorm.
+func (t *TwoFactor) TableName() string {
+	return "two_factor"
+}
+
+// FindByAccountID returns the TOTP secret of given account, including the one which hasn't been enabled yet.
+func (t *TwoFactor) FindByAccountID(rep repository.Repository, accountID uint) optional.Option[*TwoFactor] {
+	var twoFactor TwoFactor
+	if err := rep.Where("account_id = ?", accountID).First(&twoFactor).Error; err != nil {
+		return optional.None[*TwoFactor]()
+	}
+	return optional.Some(&twoFactor)
+}
+
+// Save persists this TOTP secret, and it replaces the current one of the account.
+func (t *TwoFactor) Save(rep repository.Repository) (*TwoFactor, error) {
+	if err := t.DeleteByAccountID(rep, t.AccountID); err != nil {
+		return nil, err
+	}
+	if err := rep.Create(t).Error; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Enable enables this TOTP secret with the time step of the confirmed code.
+func (t *TwoFactor) Enable(rep repository.Repository, counter int64) (*TwoFactor, error) {
+	t.Enabled = true
+	t.LastCounter = counter
+	if err := rep.Model(t).Where("id = ?", t.ID).Select("enabled", "last_counter").Updates(t).Error; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UseCounter records the time step of the used code. It returns false if the time step or a later one
+// has already been used, including by another request.
+func (t *TwoFactor) UseCounter(rep repository.Repository, counter int64) (bool, error) {
+	result := rep.Model(&TwoFactor{}).Where("id = ? and last_counter < ?", t.ID, counter).
+		Update("last_counter", counter)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	t.LastCounter = counter
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByAccountID deletes the TOTP secret of given account.
+func (t *TwoFactor) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&TwoFactor{}).Error
+}
+
+// ToString is return string of object
+func (t *TwoFactor) ToString() string {
+	return toString(t)
+}
+
+// TableName returns the table name of recovery code struct, and it is used by gorm.
+func (r *RecoveryCode) TableName() string {
+	return "recovery_code"
+}
+
+// ReplaceByAccountID replaces the recovery codes of given account with the codes of given hashes.
+func (r *RecoveryCode) ReplaceByAccountID(rep repository.Repository, accountID uint, codeHashes []string) error {
+	if err := r.DeleteByAccountID(rep, accountID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if err := rep.Create(&RecoveryCode{AccountID: accountID, CodeHash: hash}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Use marks the unused recovery code of given hash as used. It returns false if there is no such code.
+func (r *RecoveryCode) Use(rep repository.Repository, accountID uint, codeHash string, now time.Time) (bool, error) {
+	result := rep.Model(&RecoveryCode{}).Where("account_id = ? and code_hash = ? and used_at is null",
+		accountID, codeHash).Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnused returns the number of the recovery codes of given account which haven't been used yet.
+func (r *RecoveryCode) CountUnused(rep repository.Repository, accountID uint) (int64, error) {
+	var count int64
+	if err := rep.Model(&RecoveryCode{}).Where("account_id = ? and used_at is null", accountID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteByAccountID deletes the recovery codes of given account.
+func (r *RecoveryCode) DeleteByAccountID(rep repository.Repository, accountID uint) error {
+	return rep.Where("account_id = ?", accountID).Delete(&RecoveryCode{}).Error
+}
+
+// ToString is return string of object
+func (r *RecoveryCode) ToString() string {
+	return toString(r)
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Webhook defines struct of the subscription which receives the events of this application.
+type Webhook struct {
+	ID         uint      `gorm:"primary_key" json:"id"`
+	URL        string    `json:"url"`
+	Events     []string  `gorm:"-" json:"events"`
+	EventTypes string    `gorm:"column:events" json:"-"`
+	Secret     string    `json:"-"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// eventSeparator separates the event types stored in a column.
+const eventSeparator = ","
+
+// TableName returns the table name of webhook struct, and it is used by gorm.
+func (w *Webhook) TableName() string {
+	return "webhook"
+}
+
+// NewWebhook is constructor.
+func NewWebhook(url string, events []string, secret string, active bool) *Webhook {
+	return &Webhook{URL: url, Events: events, Secret: secret, Active: active}
+}
+
+// BeforeSave joins the event types into a column before saving, and it is used by gorm.
+func (w *Webhook) BeforeSave(_ *gorm.DB) error {
+	w.EventTypes = strings.Join(w.Events, eventSeparator)
+	return nil
+}
+
+// AfterFind splits the column into the event types after fetching, and it is used by gorm.
+func (w *Webhook) AfterFind(_ *gorm.DB) error {
+	w.Events = []string{}
+	if w.EventTypes != "" {
+		w.Events = strings.Split(w.EventTypes, eventSeparator)
+	}
+	return nil
+}
+
+// Subscribes returns true if this webhook is active and subscribes the given event type.
+func (w *Webhook) Subscribes(event string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// FindByID returns a webhook full matched given webhook's ID.
+func (w *Webhook) FindByID(rep repository.Repository, id uint) optional.Option[*Webhook] {
+	var webhook Webhook
+	if err := rep.Where("id = ?", id).First(&webhook).Error; err != nil {
+		return optional.None[*Webhook]()
+	}
+	return optional.Some(&webhook)
+}
+
+// FindAll returns all webhooks of the webhook table.
+func (w *Webhook) FindAll(rep repository.Repository) (*[]Webhook, error) {
+	var webhooks []Webhook
+	if err := rep.Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return &webhooks, nil
+}
+
+// FindByEvent returns the active webhooks which subscribe the given event type.
+func (w *Webhook) FindByEvent(rep repository.Repository, event string) (*[]Webhook, error) {
+	var webhooks []Webhook
+	if err := rep.Where("active = ?", true).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	subscribers := []Webhook{}
+	for i := range webhooks {
+		if webhooks[i].Subscribes(event) {
+			subscribers = append(subscribers, webhooks[i])
+		}
+	}
+	return &subscribers, nil
+}
+
+// Create persists this webhook data.
+func (w *Webhook) Create(rep repository.Repository) (*Webhook, error) {
+	if err := rep.Create(w).Error; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Update updates this webhook data.
+func (w *Webhook) Update(rep repository.Repository) (*Webhook, error) {
+	if err := rep.Save(w).Error; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Delete deletes this webhook data.
+func (w *Webhook) Delete(rep repository.Repository) (*Webhook, error) {
+	if err := rep.Delete(w).Error; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// ToString is return string of object
+func (w *Webhook) ToString() string {
+	return toString(w)
+}
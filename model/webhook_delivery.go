@@ -0,0 +1,105 @@
+package model
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/moznion/go-optional"
+	"time"
+)
+
+// Statuses of a webhook delivery.
+const (
+	// DeliveryPending represents the delivery which waits for the first attempt or a retry.
+	DeliveryPending = "pending"
+	// DeliverySucceeded represents the delivery which the receiver accepted.
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead represents the delivery which gave up retrying.
+	DeliveryDead = "dead"
+)
+
+// WebhookDelivery defines struct of an event to be delivered to a webhook.
+// The deliveries are created in the same transaction as the change of data, so this table works as the outbox.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primary_key" json:"id"`
+	WebhookID      uint       `gorm:"index" json:"webhookId"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `gorm:"index" json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	ResponseStatus int        `json:"responseStatus"`
+	LastError      string     `json:"lastError"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// TableName returns the table name of webhook delivery struct, and it is used by gorm.
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// NewWebhookDelivery is constructor. The delivery is attempted as soon as possible.
+func NewWebhookDelivery(webhookID uint, event string, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		WebhookID:     webhookID,
+		Event:         event,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: time.Now().UTC(),
+	}
+}
+
+// FindByID returns a delivery full matched given delivery's ID.
+func (d *WebhookDelivery) FindByID(rep repository.Repository, id uint) optional.Option[*WebhookDelivery] {
+	var delivery WebhookDelivery
+	if err := rep.Where("id = ?", id).First(&delivery).Error; err != nil {
+		return optional.None[*WebhookDelivery]()
+	}
+	return optional.Some(&delivery)
+}
+
+// FindByWebhookID returns the deliveries of the given webhook in order of the newest.
+func (d *WebhookDelivery) FindByWebhookID(rep repository.Repository, webhookID uint, page int, size int) (*[]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	if err := rep.Where("webhook_id = ?", webhookID).Order("id desc").
+		Limit(size).Offset(page * size).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return &deliveries, nil
+}
+
+// FindDue returns the pending deliveries whose next attempt has come, in order of creation.
+func (d *WebhookDelivery) FindDue(rep repository.Repository, now time.Time, limit int) (*[]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	if err := rep.Where("status = ? and next_attempt_at <= ?", DeliveryPending, now).Order("id").
+		Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return &deliveries, nil
+}
+
+// Create persists this delivery data.
+func (d *WebhookDelivery) Create(rep repository.Repository) (*WebhookDelivery, error) {
+	if err := rep.Create(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Update updates this delivery data.
+func (d *WebhookDelivery) Update(rep repository.Repository) (*WebhookDelivery, error) {
+	if err := rep.Save(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// DeleteByWebhookID removes the deliveries of the given webhook.
+func (d *WebhookDelivery) DeleteByWebhookID(rep repository.Repository, webhookID uint) error {
+	return rep.Where("webhook_id = ?", webhookID).Delete(&WebhookDelivery{}).Error
+}
+
+// ToString is return string of object
+func (d *WebhookDelivery) ToString() string {
+	return toString(d)
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/logger"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// TypeLog is the notifier which writes the messages to the application log.
+	TypeLog = "log"
+	// TypeFile is the notifier which appends the messages to a file.
+	TypeFile = "file"
+)
+
+const defaultFilePath = "notifications.log"
+
+// Message represents a notification to the user, such as the mail of the password reset.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier represents an interface for delivering the notifications to the users.
+// The implementations other than log and file, such as SMTP, can be plugged in by the configuration.
+type Notifier interface {
+	Notify(message *Message) error
+}
+
+// NewNotifier is constructor. It returns the notifier of the configured type, and the log notifier by default.
+func NewNotifier(logger logger.Logger, conf *config.Config) Notifier {
+	switch conf.Notifier.Type {
+	case TypeFile:
+		path := conf.Notifier.FilePath
+		if path == "" {
+			path = defaultFilePath
+		}
+		logger.GetZapLogger().Infof("use the file notifier, %s", path)
+		return &fileNotifier{path: path}
+	case "", TypeLog:
+		logger.GetZapLogger().Infof("use the log notifier")
+		return &logNotifier{logger: logger}
+	default:
+		logger.GetZapLogger().Warnf("unknown notifier type %s, use the log notifier", conf.Notifier.Type)
+		return &logNotifier{logger: logger}
+	}
+}
+
+// logNotifier writes the messages to the application log, and it is for the development.
+type logNotifier struct {
+	logger logger.Logger
+}
+
+// Notify writes the message to the log.
+func (n *logNotifier) Notify(message *Message) error {
+	n.logger.GetZapLogger().Infof("notification to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// fileNotifier appends the messages to a file, and it is for the development and the tests.
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Notify appends the message to the file.
+func (n *fileNotifier) Notify(message *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC1123Z), message.To, message.Subject, message.Body)
+	return err
+}
@@ -0,0 +1,233 @@
+package oai
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// XML namespaces and schemas used by OAI-PMH 2.0.
+// ref: https://www.openarchives.org/OAI/openarchivesprotocol.html
+const (
+	// Namespace is the XML namespace of OAI-PMH responses.
+	Namespace = "http://www.openarchives.org/OAI/2.0/"
+	// SchemaLocation is the location of the XML schema of OAI-PMH responses.
+	SchemaLocation = Namespace + " http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	// XSINamespace is the XML namespace of XML schema instances.
+	XSINamespace = "http://www.w3.org/2001/XMLSchema-instance"
+	// DCPrefix is the metadata prefix of unqualified Dublin Core.
+	DCPrefix = "oai_dc"
+	// DCNamespace is the XML namespace of the oai_dc metadata format.
+	DCNamespace = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	// DCSchema is the location of the XML schema of the oai_dc metadata format.
+	DCSchema = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	// DCElementsNamespace is the XML namespace of Dublin Core elements.
+	DCElementsNamespace = "http://purl.org/dc/elements/1.1/"
+	// ContentType is the media type of OAI-PMH responses.
+	ContentType = "text/xml; charset=utf-8"
+)
+
+// Verbs of OAI-PMH requests.
+const (
+	Identify            = "Identify"
+	ListMetadataFormats = "ListMetadataFormats"
+	ListSets            = "ListSets"
+	ListIdentifiers     = "ListIdentifiers"
+	ListRecords         = "ListRecords"
+	GetRecord           = "GetRecord"
+)
+
+// Error codes of OAI-PMH responses.
+const (
+	BadArgument             = "badArgument"
+	BadResumptionToken      = "badResumptionToken"
+	BadVerb                 = "badVerb"
+	CannotDisseminateFormat = "cannotDisseminateFormat"
+	IDDoesNotExist          = "idDoesNotExist"
+	NoRecordsMatch          = "noRecordsMatch"
+	NoMetadataFormats       = "noMetadataFormats"
+	NoSetHierarchy          = "noSetHierarchy"
+)
+
+const (
+	// DayGranularity is the layout of datestamps with the granularity of a day.
+	DayGranularity = "2006-01-02"
+	// SecondGranularity is the layout of datestamps with the granularity of a second.
+	SecondGranularity = "2006-01-02T15:04:05Z"
+	// StatusDeleted is the status of the header of a deleted record.
+	StatusDeleted = "deleted"
+)
+
+// Response represents the root element of an OAI-PMH response.
+type Response struct {
+	XMLName             xml.Name                     `xml:"OAI-PMH"`
+	Xmlns               string                       `xml:"xmlns,attr"`
+	XmlnsXSI            string                       `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string                       `xml:"xsi:schemaLocation,attr"`
+	ResponseDate        string                       `xml:"responseDate"`
+	Request             Request                      `xml:"request"`
+	Errors              []Error                      `xml:"error,omitempty"`
+	Identify            *IdentifyResponse            `xml:"Identify,omitempty"`
+	ListMetadataFormats *ListMetadataFormatsResponse `xml:"ListMetadataFormats,omitempty"`
+	ListSets            *ListSetsResponse            `xml:"ListSets,omitempty"`
+	ListIdentifiers     *ListIdentifiersResponse     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *ListRecordsResponse         `xml:"ListRecords,omitempty"`
+	GetRecord           *GetRecordResponse           `xml:"GetRecord,omitempty"`
+}
+
+// Request represents the request element which echoes the arguments of the request.
+type Request struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	BaseURL         string `xml:",chardata"`
+}
+
+// Error represents an error or an exception condition.
+type Error struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+// IdentifyResponse represents the response of Identify verb.
+type IdentifyResponse struct {
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmails       []string `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+// MetadataFormat represents a metadata format available from the repository.
+type MetadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+// ListMetadataFormatsResponse represents the response of ListMetadataFormats verb.
+type ListMetadataFormatsResponse struct {
+	MetadataFormats []MetadataFormat `xml:"metadataFormat"`
+}
+
+// Set represents a set of the repository.
+type Set struct {
+	SetSpec string `xml:"setSpec"`
+	SetName string `xml:"setName"`
+}
+
+// ListSetsResponse represents the response of ListSets verb.
+type ListSetsResponse struct {
+	Sets []Set `xml:"set"`
+}
+
+// Header represents the header of a record.
+type Header struct {
+	Status     string   `xml:"status,attr,omitempty"`
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpecs   []string `xml:"setSpec"`
+}
+
+// Record represents a record which has a header and metadata.
+type Record struct {
+	Header   Header    `xml:"header"`
+	Metadata *Metadata `xml:"metadata,omitempty"`
+}
+
+// Metadata represents the metadata element of a record.
+type Metadata struct {
+	DC *DublinCore `xml:"oai_dc:dc"`
+}
+
+// DublinCore represents the metadata of oai_dc format.
+type DublinCore struct {
+	XmlnsDC        string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsElements  string   `xml:"xmlns:dc,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Titles         []string `xml:"dc:title"`
+	Subjects       []string `xml:"dc:subject"`
+	Types          []string `xml:"dc:type"`
+	Formats        []string `xml:"dc:format"`
+	Identifiers    []string `xml:"dc:identifier"`
+	Dates          []string `xml:"dc:date"`
+}
+
+// ResumptionToken represents the token to continue an incomplete list.
+type ResumptionToken struct {
+	CompleteListSize int64  `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+	Token            string `xml:",chardata"`
+}
+
+// ListIdentifiersResponse represents the response of ListIdentifiers verb.
+type ListIdentifiersResponse struct {
+	Headers         []Header         `xml:"header"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// ListRecordsResponse represents the response of ListRecords verb.
+type ListRecordsResponse struct {
+	Records         []Record         `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordResponse represents the response of GetRecord verb.
+type GetRecordResponse struct {
+	Record Record `xml:"record"`
+}
+
+// NewResponse is constructor.
+func NewResponse(baseURL string, now time.Time) *Response {
+	return &Response{
+		Xmlns:          Namespace,
+		XmlnsXSI:       XSINamespace,
+		SchemaLocation: SchemaLocation,
+		ResponseDate:   FormatDatestamp(now),
+		Request:        Request{BaseURL: baseURL},
+	}
+}
+
+// NewDublinCore is constructor.
+func NewDublinCore() *DublinCore {
+	return &DublinCore{
+		XmlnsDC:        DCNamespace,
+		XmlnsElements:  DCElementsNamespace,
+		XmlnsXSI:       XSINamespace,
+		SchemaLocation: DCNamespace + " " + DCSchema,
+	}
+}
+
+// AddError appends an error to this response.
+// The arguments of the request aren't echoed for badVerb and badArgument errors as the protocol requires.
+func (r *Response) AddError(code string, message string) {
+	if code == BadVerb || code == BadArgument {
+		r.Request = Request{BaseURL: r.Request.BaseURL}
+	}
+	r.Errors = append(r.Errors, Error{Code: code, Message: message})
+}
+
+// HasErrors returns true if this response has any errors.
+func (r *Response) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// FormatDatestamp formats the given time with the granularity of a second.
+func FormatDatestamp(t time.Time) string {
+	return t.UTC().Format(SecondGranularity)
+}
+
+// Marshal returns the XML document of the given response including the XML header.
+func Marshal(r *Response) ([]byte, error) {
+	bytes, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), bytes...), nil
+}
@@ -0,0 +1,38 @@
+package oai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Token represents the state of an incomplete list request, and it is sent to harvesters as a resumption token.
+// The position of the list is kept as the datestamp and the ID of the last returned record,
+// so that records modified during the harvest don't shift the following pages.
+type Token struct {
+	MetadataPrefix string `json:"m"`
+	Set            string `json:"s,omitempty"`
+	From           string `json:"f,omitempty"`
+	Until          string `json:"u,omitempty"`
+	AfterTime      int64  `json:"t"`
+	AfterID        uint   `json:"i"`
+	Cursor         int    `json:"c"`
+}
+
+// Encode returns the string representation of this token.
+func (t *Token) Encode() string {
+	bytes, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// DecodeToken parses the given string as a resumption token.
+func DecodeToken(value string) (*Token, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	token := &Token{}
+	if err := json.Unmarshal(bytes, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
@@ -0,0 +1,351 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/token"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryPath       = "/.well-known/openid-configuration"
+	codeChallengeMethod = "S256"
+	randomBytes         = 32
+	defaultTimeout      = 10
+	// maxResponseSize limits the size of the responses of the provider.
+	maxResponseSize = 1 << 20
+)
+
+var (
+	// ErrInvalidIDToken is returned when the ID token is malformed, expired or not issued for this application.
+	ErrInvalidIDToken = errors.New("the ID token is invalid")
+	// ErrProvider is returned when the provider can't be reached or responds with an error.
+	ErrProvider = errors.New("the identity provider failed")
+)
+
+// supportedAlgorithms are the algorithms of the ID tokens which this application can verify.
+var supportedAlgorithms = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodRS384.Alg(),
+	jwt.SigningMethodRS512.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// AuthRequest represents the authorization request sent to the provider. The state, the nonce and the code
+// verifier are kept in the session until the callback, and only the challenge of the verifier is sent.
+type AuthRequest struct {
+	URL           string `json:"-"`
+	State         string `json:"state"`
+	Nonce         string `json:"nonce"`
+	CodeVerifier  string `json:"codeVerifier"`
+	LinkAccountID uint   `json:"linkAccountId,omitempty"`
+}
+
+// Claims represents the claims of the ID token used to provision the account.
+// The groups are nil if the token doesn't have the groups claim.
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Name              string
+	PreferredUsername string
+	Email             string
+	EmailVerified     bool
+	Groups            []string
+}
+
+// Provider represents an interface for the authorization code flow with PKCE of an OpenID Connect provider.
+type Provider interface {
+	NewAuthRequest() (*AuthRequest, error)
+	Exchange(code string, request *AuthRequest) (*Claims, error)
+}
+
+type provider struct {
+	conf      config.OIDCConfig
+	client    *http.Client
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]*token.JSONWebKey
+}
+
+// discovery represents the provider metadata defined by OpenID Connect Discovery 1.0.
+type discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgorithms     []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// tokenResponse represents the response of the token endpoint.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewProvider is constructor. The metadata and the keys of the provider are fetched when they are needed first,
+// so the application starts even if the provider isn't available.
+func NewProvider(conf *config.Config) Provider {
+	timeout := conf.OIDC.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &provider{conf: conf.OIDC, client: &http.Client{Timeout: time.Duration(timeout) * time.Second}}
+}
+
+// NewAuthRequest returns the authorization request with a new state, nonce and code verifier.
+func (p *provider) NewAuthRequest() (*AuthRequest, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	request := &AuthRequest{}
+	for _, v := range []*string{&request.State, &request.Nonce, &request.CodeVerifier} {
+		if *v, err = newRandomString(); err != nil {
+			return nil, err
+		}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.conf.ClientID)
+	query.Set("redirect_uri", p.conf.RedirectURL)
+	query.Set("scope", strings.Join(p.scopes(), " "))
+	query.Set("state", request.State)
+	query.Set("nonce", request.Nonce)
+	query.Set("code_challenge", codeChallenge(request.CodeVerifier))
+	query.Set("code_challenge_method", codeChallengeMethod)
+
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	request.URL = d.AuthorizationEndpoint + separator + query.Encode()
+	return request, nil
+}
+
+// Exchange exchanges the authorization code for the ID token with the code verifier of the request,
+// and returns the claims of the ID token after validating it.
+func (p *provider) Exchange(code string, request *AuthRequest) (*Claims, error) {
+	d, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("code_verifier", request.CodeVerifier)
+	if p.conf.ClientSecret == "" {
+		form.Set("client_id", p.conf.ClientID)
+	}
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.conf.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+
+	res := &tokenResponse{}
+	status, err := p.do(req, res)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || res.IDToken == "" {
+		return nil, fmt.Errorf("%w: the token endpoint responded %d %s %s", ErrProvider, status, res.Error,
+			res.ErrorDescription)
+	}
+	return p.verify(d, res.IDToken, request.Nonce)
+}
+
+// verify validates the signature and the claims of the ID token defined by OpenID Connect Core 1.0.
+func (p *provider) verify(d *discovery, idToken string, nonce string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	parsed, err := jwt.ParseWithClaims(idToken, mapClaims, func(t *jwt.Token) (interface{}, error) {
+		if !contains(supportedAlgorithms, t.Method.Alg()) ||
+			(len(d.SigningAlgorithms) > 0 && !contains(d.SigningAlgorithms, t.Method.Alg())) {
+			return nil, ErrInvalidIDToken
+		}
+		id, _ := t.Header["kid"].(string)
+		return p.findKey(d, id)
+	})
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidIDToken
+	}
+
+	if !mapClaims.VerifyIssuer(d.Issuer, true) || !mapClaims.VerifyAudience(p.conf.ClientID, true) ||
+		!mapClaims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, ErrInvalidIDToken
+	}
+	if azp, ok := mapClaims["azp"].(string); ok && azp != p.conf.ClientID {
+		return nil, ErrInvalidIDToken
+	}
+	if n, _ := mapClaims["nonce"].(string); n == "" || n != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	claims := &Claims{Issuer: d.Issuer}
+	claims.Subject, _ = mapClaims["sub"].(string)
+	claims.Name, _ = mapClaims["name"].(string)
+	claims.PreferredUsername, _ = mapClaims["preferred_username"].(string)
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.EmailVerified, _ = mapClaims["email_verified"].(bool)
+	if claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+	groupsClaim := p.conf.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	if groups, ok := mapClaims[groupsClaim].([]interface{}); ok {
+		claims.Groups = []string{}
+		for _, g := range groups {
+			if group, ok := g.(string); ok {
+				claims.Groups = append(claims.Groups, group)
+			}
+		}
+	}
+	return claims, nil
+}
+
+// getDiscovery returns the provider metadata, and fetches it if it hasn't been fetched yet.
+// The issuer of the metadata must be the configured issuer.
+func (p *provider) getDiscovery() (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	issuer := strings.TrimSuffix(p.conf.Issuer, "/")
+	req, err := http.NewRequest(http.MethodGet, issuer+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	d := &discovery{}
+	status, err := p.do(req, d)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: the discovery responded %d", ErrProvider, status)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != issuer || d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" ||
+		d.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: the metadata of %s is invalid", ErrProvider, issuer)
+	}
+	p.discovery = d
+	return d, nil
+}
+
+// findKey returns the public key of given ID. The keys are fetched again if the ID is unknown,
+// because the provider may have rotated the keys. The ID can be omitted if the provider has only one key.
+func (p *provider) findKey(d *discovery, id string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key, ok := p.lookupKey(id)
+	if !ok {
+		if err := p.fetchKeys(d); err != nil {
+			return nil, err
+		}
+		if key, ok = p.lookupKey(id); !ok {
+			return nil, ErrInvalidIDToken
+		}
+	}
+	return key.PublicKey()
+}
+
+func (p *provider) lookupKey(id string) (*token.JSONWebKey, bool) {
+	if id == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[id]
+	return key, ok
+}
+
+func (p *provider) fetchKeys(d *discovery) error {
+	req, err := http.NewRequest(http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+	set := &token.JSONWebKeySet{}
+	status, err := p.do(req, set)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%w: the JWKS responded %d", ErrProvider, status)
+	}
+	p.keys = make(map[string]*token.JSONWebKey)
+	for i := range set.Keys {
+		if set.Keys[i].Use == "" || set.Keys[i].Use == "sig" {
+			p.keys[set.Keys[i].Kid] = &set.Keys[i]
+		}
+	}
+	return nil
+}
+
+// do sends the request, and decodes the JSON response into v. It returns the status code of the response.
+func (p *provider) do(req *http.Request, v interface{}) (int, error) {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrProvider, err.Error())
+	}
+	defer res.Body.Close()
+	if err = json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(v); err != nil &&
+		res.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("%w: %s", ErrProvider, err.Error())
+	}
+	return res.StatusCode, nil
+}
+
+func (p *provider) scopes() []string {
+	if len(p.conf.Scopes) == 0 {
+		return []string{"openid", "profile", "email"}
+	}
+	if !contains(p.conf.Scopes, "openid") {
+		return append([]string{"openid"}, p.conf.Scopes...)
+	}
+	return p.conf.Scopes
+}
+
+// codeChallenge returns the S256 challenge of the code verifier defined by RFC 7636.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func newRandomString() (string, error) {
+	b := make([]byte, randomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
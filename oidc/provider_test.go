@@ -0,0 +1,91 @@
+package oidc_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/oidc"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProvider(mock *test.OIDCProvider) oidc.Provider {
+	conf := &config.Config{}
+	conf.OIDC = config.OIDCConfig{Enabled: true, Issuer: mock.URL(), ClientID: mock.ClientID,
+		ClientSecret: mock.ClientSecret, RedirectURL: "http://localhost/api/auth/oidc/callback"}
+	return oidc.NewProvider(conf)
+}
+
+func TestNewAuthRequest_PKCE(t *testing.T) {
+	mock := test.NewOIDCProvider("client", "secret")
+	defer mock.Close()
+
+	request, err := newProvider(mock).NewAuthRequest()
+	assert.NoError(t, err)
+
+	u, _ := url.Parse(request.URL)
+	query := u.Query()
+	sum := sha256.Sum256([]byte(request.CodeVerifier))
+	assert.Equal(t, mock.URL()+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), query.Get("code_challenge"))
+	assert.Equal(t, request.State, query.Get("state"))
+	assert.Equal(t, request.Nonce, query.Get("nonce"))
+	assert.NotEqual(t, request.State, request.Nonce)
+}
+
+func TestExchange_Success(t *testing.T) {
+	mock := test.NewOIDCProvider("client", "secret")
+	defer mock.Close()
+	provider := newProvider(mock)
+
+	request, _ := provider.NewAuthRequest()
+	code, _, _ := mock.Authorize(request.URL, "sub-1", map[string]interface{}{"email": "alice@example.com",
+		"email_verified": true, "groups": []string{"admins"}})
+	claims, err := provider.Exchange(code, request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &oidc.Claims{Issuer: mock.URL(), Subject: "sub-1", Email: "alice@example.com",
+		EmailVerified: true, Groups: []string{"admins"}}, claims)
+}
+
+func TestExchange_InvalidCodeVerifier(t *testing.T) {
+	mock := test.NewOIDCProvider("client", "secret")
+	defer mock.Close()
+	provider := newProvider(mock)
+
+	request, _ := provider.NewAuthRequest()
+	code, _, _ := mock.Authorize(request.URL, "sub-1", nil)
+	request.CodeVerifier = "another verifier"
+	_, err := provider.Exchange(code, request)
+
+	assert.True(t, errors.Is(err, oidc.ErrProvider))
+}
+
+func TestExchange_InvalidIDToken(t *testing.T) {
+	mock := test.NewOIDCProvider("client", "secret")
+	defer mock.Close()
+	provider := newProvider(mock)
+
+	cases := map[string]map[string]interface{}{
+		"issuer":   {"iss": "http://evil.example.com"},
+		"audience": {"aud": "another client"},
+		"nonce":    {"nonce": "replayed nonce"},
+		"expired":  {"exp": time.Now().Add(-time.Minute).Unix()},
+		"subject":  {"sub": ""},
+		"azp":      {"azp": "another client"},
+	}
+	for name, claims := range cases {
+		request, _ := provider.NewAuthRequest()
+		code, _, _ := mock.Authorize(request.URL, "sub-1", claims)
+		_, err := provider.Exchange(code, request)
+
+		assert.True(t, errors.Is(err, oidc.ErrInvalidIDToken), name)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"os"
+	"time"
 )
 
 // Repository defines an interface for access the database.
@@ -25,6 +26,7 @@ type Repository interface {
 	Updates(value interface{}) *gorm.DB
 	Delete(value interface{}) *gorm.DB
 	Where(query interface{}, args ...interface{}) *gorm.DB
+	Order(value interface{}) *gorm.DB
 	Preload(column string, conditions ...interface{}) *gorm.DB
 	Scopes(f ...func(*gorm.DB) *gorm.DB) *gorm.DB
 	ScanRows(rows *sql.Rows, result interface{}) error
@@ -32,6 +34,8 @@ type Repository interface {
 	Close() error
 	DropTableIfExists(value interface{}) error
 	AutoMigrate(value interface{}) error
+	HasTable(value interface{}) bool
+	AlterColumn(value interface{}, field string) error
 }
 
 // repository defines a repository for access the database.
@@ -67,7 +71,7 @@ const (
 
 func connectDatabase(logger logger.Logger, config *config.Config) (*gorm.DB, error) {
 	var dsn string
-	gormConfig := &gorm.Config{Logger: logger}
+	gormConfig := &gorm.Config{Logger: logger, NowFunc: func() time.Time { return time.Now().UTC() }}
 
 	if config.Database.Dialect == POSTGRES {
 		dsn = fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
@@ -138,6 +142,11 @@ func (rep *repository) Where(query interface{}, args ...interface{}) *gorm.DB {
 	return rep.db.Where(query, args...)
 }
 
+// Order specify order when retrieve records from database.
+func (rep *repository) Order(value interface{}) *gorm.DB {
+	return rep.db.Order(value)
+}
+
 // Preload associations with given conditions.
 func (rep *repository) Preload(column string, conditions ...interface{}) *gorm.DB {
 	return rep.db.Preload(column, conditions...)
@@ -170,6 +179,16 @@ func (rep *repository) AutoMigrate(value interface{}) error {
 	return rep.db.AutoMigrate(value)
 }
 
+// HasTable returns true if the table of given model exists
+func (rep *repository) HasTable(value interface{}) bool {
+	return rep.db.Migrator().HasTable(value)
+}
+
+// AlterColumn changes the type and the constraints of the column to the definition of the field of given model
+func (rep *repository) AlterColumn(value interface{}, field string) error {
+	return rep.db.Migrator().AlterColumn(value, field)
+}
+
 // Transaction start a transaction as a block.
 // If it is failed, will roll back and return error.
 // If it is successful, will commit.
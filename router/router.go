@@ -7,6 +7,8 @@ import (
 	"github.com/lyh-demo/go-webapp-demo/container"
 	"github.com/lyh-demo/go-webapp-demo/controller"
 	_ "github.com/lyh-demo/go-webapp-demo/docs" // for using echo-swagger
+	appMiddleware "github.com/lyh-demo/go-webapp-demo/middleware"
+	"github.com/lyh-demo/go-webapp-demo/model"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"net/http"
 )
@@ -20,22 +22,50 @@ func Init(e *echo.Echo, container container.Container) {
 	setCategoryController(e, container)
 	setFormatController(e, container)
 	setAccountController(e, container)
+	setAuthorityController(e, container)
+	setTokenController(e, container)
+	setAPIKeyController(e, container)
+	setTwoFactorController(e, container)
+	setLockoutController(e, container)
+	setSessionController(e, container)
+	setAuditController(e, container)
 	setHealthController(e, container)
+	setGraphQLController(e, container)
+	setWebhookController(e, container)
+	setEventController(e, container)
+	setReportController(e, container)
+	setBackupController(e, container)
+	setLabelController(e, container)
+	setOpdsController(e, container)
+	setOaiController(e, container)
+	setFeedController(e, container)
 
 	setSwagger(container, e)
+
+	checkSafeRoutes(e, container)
 }
 
 func setCORSConfig(e *echo.Echo, container container.Container) {
-	if container.GetConfig().Extension.CorsEnabled {
+	conf := container.GetConfig().Extension
+	if conf.CorsEnabled {
+		// The credentials are allowed only for the configured origins. If they were allowed for any origin,
+		// any page could read the responses authenticated by the session, such as the CSRF token.
+		origins := conf.CorsAllowOrigins
+		credentials := len(origins) > 0
+		if !credentials {
+			origins = []string{"*"}
+		}
 		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowCredentials:                         true,
-			UnsafeWildcardOriginWithAllowCredentials: true,
-			AllowOrigins:                             []string{"*"},
+			AllowCredentials: credentials,
+			AllowOrigins:     origins,
 			AllowHeaders: []string{
 				echo.HeaderAccessControlAllowHeaders,
 				echo.HeaderContentType,
 				echo.HeaderContentLength,
 				echo.HeaderAcceptEncoding,
+				echo.HeaderAuthorization,
+				appMiddleware.HeaderAPIKey,
+				appMiddleware.HeaderCSRFToken,
 			},
 			AllowMethods: []string{
 				http.MethodGet,
@@ -56,41 +86,287 @@ func setErrorController(e *echo.Echo, container container.Container) {
 
 func setBookController(e *echo.Echo, container container.Container) {
 	book := controller.NewBookController(container)
-	e.GET(config.APIBooksID, func(c echo.Context) error { return book.GetBook(c) })
-	e.GET(config.APIBooks, func(c echo.Context) error { return book.GetBookList(c) })
-	e.POST(config.APIBooks, func(c echo.Context) error { return book.CreateBook(c) })
-	e.PUT(config.APIBooksID, func(c echo.Context) error { return book.UpdateBook(c) })
-	e.DELETE(config.APIBooksID, func(c echo.Context) error { return book.DeleteBook(c) })
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionBookRead)
+	write := appMiddleware.PermissionMiddleware(container, model.PermissionBookWrite)
+	e.GET(config.APIBooksID, func(c echo.Context) error { return book.GetBook(c) }, read)
+	e.GET(config.APIBooks, func(c echo.Context) error { return book.GetBookList(c) }, read)
+	e.POST(config.APIBooks, func(c echo.Context) error { return book.CreateBook(c) }, write)
+	e.PUT(config.APIBooksID, func(c echo.Context) error { return book.UpdateBook(c) }, write)
+	e.DELETE(config.APIBooksID, func(c echo.Context) error { return book.DeleteBook(c) }, write)
 }
 
 func setCategoryController(e *echo.Echo, container container.Container) {
 	category := controller.NewCategoryController(container)
-	e.GET(config.APICategories, func(c echo.Context) error { return category.GetCategoryList(c) })
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionCategoryRead)
+	e.GET(config.APICategories, func(c echo.Context) error { return category.GetCategoryList(c) }, read)
 }
 
 func setFormatController(e *echo.Echo, container container.Container) {
 	format := controller.NewFormatController(container)
-	e.GET(config.APIFormats, func(c echo.Context) error { return format.GetFormatList(c) })
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionFormatRead)
+	e.GET(config.APIFormats, func(c echo.Context) error { return format.GetFormatList(c) }, read)
 }
 
 func setAccountController(e *echo.Echo, container container.Container) {
 	account := controller.NewAccountController(container)
 	e.GET(config.APIAccountLoginStatus, func(c echo.Context) error { return account.GetLoginStatus(c) })
 	e.GET(config.APIAccountLoginAccount, func(c echo.Context) error { return account.GetLoginAccount(c) })
+	e.GET(config.APIAccountCSRFToken, func(c echo.Context) error { return account.GetCSRFToken(c) })
 
 	if container.GetConfig().Extension.SecurityEnabled {
 		e.POST(config.APIAccountLogin, func(c echo.Context) error { return account.Login(c) })
+		e.POST(config.APIAccountLoginTwoFactor, func(c echo.Context) error { return account.LoginTwoFactor(c) })
+		e.POST(config.APIAccountLoginTwoFactorSetup, func(c echo.Context) error { return account.LoginTwoFactorSetup(c) })
+		e.POST(config.APIAccountLoginTwoFactorConfirm, func(c echo.Context) error { return account.LoginTwoFactorConfirm(c) })
 		e.POST(config.APIAccountLogout, func(c echo.Context) error { return account.Logout(c) })
+		if container.GetConfig().OIDC.Enabled {
+			e.GET(config.APIAccountOIDCLogin, func(c echo.Context) error { return account.OIDCLogin(c) })
+			e.GET(config.APIAccountOIDCCallback, func(c echo.Context) error { return account.OIDCCallback(c) })
+			e.POST(config.APIAccountOIDCLink, func(c echo.Context) error { return account.OIDCLink(c) })
+		}
+		e.PUT(config.APIAccountLocale, func(c echo.Context) error { return account.UpdateLocale(c) })
+		e.POST(config.APIAccountPassword, func(c echo.Context) error { return account.ChangePassword(c) },
+			appMiddleware.SessionOnlyMiddleware(container))
+		e.POST(config.APIAccountPasswordReset, func(c echo.Context) error { return account.RequestPasswordReset(c) })
+		e.POST(config.APIAccountPasswordResetConfirm, func(c echo.Context) error { return account.ResetPassword(c) })
+	}
+
+	manage := appMiddleware.PermissionMiddleware(container, model.PermissionAccountManage)
+	e.GET(config.APIAdminAccountsID, func(c echo.Context) error { return account.GetAccount(c) }, manage)
+	e.GET(config.APIAdminAccounts, func(c echo.Context) error { return account.GetAccountList(c) }, manage)
+	e.POST(config.APIAdminAccounts, func(c echo.Context) error { return account.CreateAccount(c) }, manage)
+	e.PUT(config.APIAdminAccountsID, func(c echo.Context) error { return account.UpdateAccount(c) }, manage)
+	e.DELETE(config.APIAdminAccountsID, func(c echo.Context) error { return account.DeleteAccount(c) }, manage)
+}
+
+func setTokenController(e *echo.Echo, container container.Container) {
+	conf := container.GetConfig()
+	if conf.Extension.SecurityEnabled && conf.JWT.Enabled {
+		token := controller.NewTokenController(container)
+		e.POST(config.APIAccountToken, func(c echo.Context) error { return token.IssueToken(c) })
+		e.POST(config.APIAccountTokenRefresh, func(c echo.Context) error { return token.RefreshToken(c) })
+		e.POST(config.APIAccountTokenRevoke, func(c echo.Context) error { return token.RevokeToken(c) })
+		e.GET(config.WellKnownJWKS, func(c echo.Context) error { return token.GetJWKS(c) })
+	}
+}
+
+func setAPIKeyController(e *echo.Echo, container container.Container) {
+	if container.GetConfig().Extension.SecurityEnabled {
+		apiKey := controller.NewAPIKeyController(container)
+		e.GET(config.APIAccountAPIKeys, func(c echo.Context) error { return apiKey.GetAPIKeyList(c) })
+		e.POST(config.APIAccountAPIKeys, func(c echo.Context) error { return apiKey.CreateAPIKey(c) })
+		e.DELETE(config.APIAccountAPIKeysID, func(c echo.Context) error { return apiKey.RevokeAPIKey(c) })
+
+		manage := appMiddleware.PermissionMiddleware(container, model.PermissionAccountManage)
+		e.GET(config.APIAdminAPIKeys, func(c echo.Context) error { return apiKey.GetAllAPIKeyList(c) }, manage)
+		e.DELETE(config.APIAdminAPIKeysID, func(c echo.Context) error { return apiKey.RevokeAnyAPIKey(c) }, manage)
+	}
+}
+
+func setTwoFactorController(e *echo.Echo, container container.Container) {
+	if container.GetConfig().Extension.SecurityEnabled {
+		twoFactor := controller.NewTwoFactorController(container)
+		self := appMiddleware.SessionOnlyMiddleware(container)
+		e.GET(config.APIAccountTwoFactor, func(c echo.Context) error { return twoFactor.GetTwoFactorStatus(c) }, self)
+		e.POST(config.APIAccountTwoFactorSetup, func(c echo.Context) error { return twoFactor.SetupTwoFactor(c) }, self)
+		e.POST(config.APIAccountTwoFactorConfirm,
+			func(c echo.Context) error { return twoFactor.ConfirmTwoFactor(c) }, self)
+		e.POST(config.APIAccountTwoFactorRecoveryCodes,
+			func(c echo.Context) error { return twoFactor.RegenerateRecoveryCodes(c) }, self)
+		e.POST(config.APIAccountTwoFactorDisable,
+			func(c echo.Context) error { return twoFactor.DisableTwoFactor(c) }, self)
+	}
+}
+
+func setLockoutController(e *echo.Echo, container container.Container) {
+	if container.GetConfig().Extension.SecurityEnabled {
+		lockout := controller.NewLockoutController(container)
+		manage := appMiddleware.PermissionMiddleware(container, model.PermissionAccountManage)
+		e.GET(config.APIAdminLockouts, func(c echo.Context) error { return lockout.GetLockoutList(c) }, manage)
+		e.POST(config.APIAdminLockoutsUnlock, func(c echo.Context) error { return lockout.Unlock(c) }, manage)
+	}
+}
+
+func setSessionController(e *echo.Echo, container container.Container) {
+	if container.GetConfig().Extension.SecurityEnabled {
+		sessions := controller.NewSessionController(container)
+		self := appMiddleware.SessionOnlyMiddleware(container)
+		e.GET(config.APIAccountSessions, func(c echo.Context) error { return sessions.GetSessionList(c) }, self)
+		e.DELETE(config.APIAccountSessions,
+			func(c echo.Context) error { return sessions.RevokeOtherSessions(c) }, self)
+		e.DELETE(config.APIAccountSessionsID, func(c echo.Context) error { return sessions.RevokeSession(c) }, self)
+
+		manage := appMiddleware.PermissionMiddleware(container, model.PermissionAccountManage)
+		e.GET(config.APIAdminAccountsIDSessions,
+			func(c echo.Context) error { return sessions.GetAccountSessionList(c) }, manage)
+		e.DELETE(config.APIAdminAccountsIDSessions, func(c echo.Context) error { return sessions.ForceLogout(c) }, manage)
+	}
+}
+
+func setAuditController(e *echo.Echo, container container.Container) {
+	if container.GetConfig().Extension.SecurityEnabled {
+		audit := controller.NewAuditController(container)
+		manage := appMiddleware.PermissionMiddleware(container, model.PermissionAccountManage)
+		e.GET(config.APIAdminAuditLogs, func(c echo.Context) error { return audit.GetAuditLogList(c) }, manage)
+		e.GET(config.APIAdminAuditLogsCSV, func(c echo.Context) error { return audit.GetAuditLogCSV(c) }, manage)
+		e.GET(config.APIAdminAuditLogsVerify, func(c echo.Context) error { return audit.VerifyAuditLog(c) }, manage)
 	}
 }
 
+func setAuthorityController(e *echo.Echo, container container.Container) {
+	authority := controller.NewAuthorityController(container)
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionAccountManage, model.PermissionRoleManage)
+	manage := appMiddleware.PermissionMiddleware(container, model.PermissionRoleManage)
+	e.GET(config.APIAdminAuthorities, func(c echo.Context) error { return authority.GetAuthorityList(c) }, read)
+	e.POST(config.APIAdminAuthorities, func(c echo.Context) error { return authority.CreateAuthority(c) }, manage)
+	e.PUT(config.APIAdminAuthoritiesIDPermissions,
+		func(c echo.Context) error { return authority.UpdateAuthorityPermissions(c) }, manage)
+	e.GET(config.APIAdminPermissions, func(c echo.Context) error { return authority.GetPermissionList(c) }, read)
+}
+
 func setHealthController(e *echo.Echo, container container.Container) {
 	health := controller.NewHealthController(container)
 	e.GET(config.APIHealth, func(c echo.Context) error { return health.GetHealthCheck(c) })
 }
 
+func setGraphQLController(e *echo.Echo, container container.Container) {
+	graphql := controller.NewGraphQLController(container)
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionBookRead)
+	e.GET(config.APIGraphQL, func(c echo.Context) error { return graphql.Query(c) }, read)
+	e.POST(config.APIGraphQL, func(c echo.Context) error { return graphql.Query(c) }, read)
+}
+
+func setWebhookController(e *echo.Echo, container container.Container) {
+	webhook := controller.NewWebhookController(container)
+	manage := appMiddleware.PermissionMiddleware(container, model.PermissionWebhookManage)
+	e.GET(config.APIWebhooksID, func(c echo.Context) error { return webhook.GetWebhook(c) }, manage)
+	e.GET(config.APIWebhooks, func(c echo.Context) error { return webhook.GetWebhookList(c) }, manage)
+	e.POST(config.APIWebhooks, func(c echo.Context) error { return webhook.CreateWebhook(c) }, manage)
+	e.PUT(config.APIWebhooksID, func(c echo.Context) error { return webhook.UpdateWebhook(c) }, manage)
+	e.DELETE(config.APIWebhooksID, func(c echo.Context) error { return webhook.DeleteWebhook(c) }, manage)
+	e.GET(config.APIWebhooksIDDeliveries, func(c echo.Context) error { return webhook.GetDeliveryList(c) }, manage)
+	e.POST(config.APIWebhooksDeliveriesRedeliver, func(c echo.Context) error { return webhook.Redeliver(c) }, manage)
+}
+
+func setEventController(e *echo.Echo, container container.Container) {
+	event := controller.NewEventController(container)
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionBookRead)
+	e.GET(config.APIEvents, func(c echo.Context) error { return event.StreamEvents(c) }, read)
+	e.GET(config.APIEventsWebSocket, func(c echo.Context) error { return event.StreamEventsOverWebSocket(c) }, read)
+}
+
+func setReportController(e *echo.Echo, container container.Container) {
+	report := controller.NewReportController(container)
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionReportRead)
+	e.GET(config.APIReportsSummary, func(c echo.Context) error { return report.GetSummary(c) }, read)
+	e.GET(config.APIReportsSummaryCSV, func(c echo.Context) error { return report.GetSummaryCSV(c) }, read)
+}
+
+func setBackupController(e *echo.Echo, container container.Container) {
+	backup := controller.NewBackupController(container)
+	manage := appMiddleware.PermissionMiddleware(container, model.PermissionBackupManage)
+	e.GET(config.APIAdminBackup, func(c echo.Context) error { return backup.GetBackup(c) }, manage)
+	e.POST(config.APIAdminRestore, func(c echo.Context) error { return backup.Restore(c) }, manage)
+}
+
+func setLabelController(e *echo.Echo, container container.Container) {
+	label := controller.NewLabelController(container)
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionBookRead)
+	e.GET(config.APIBooksIDLabel, func(c echo.Context) error { return label.GetLabel(c) }, read)
+	e.GET(config.APIBooksLabels, func(c echo.Context) error { return label.GetLabelSheet(c) }, read)
+}
+
+func setOpdsController(e *echo.Echo, container container.Container) {
+	opds := controller.NewOpdsController(container)
+	read := appMiddleware.PermissionMiddleware(container, model.PermissionBookRead)
+	e.GET(config.OPDS, func(c echo.Context) error { return opds.GetRootFeed(c) }, read)
+	e.GET(config.OPDSBooks, func(c echo.Context) error { return opds.GetAllBooksFeed(c) }, read)
+	e.GET(config.OPDSCategories, func(c echo.Context) error { return opds.GetCategoryFeed(c) }, read)
+	e.GET(config.OPDSCategoriesID, func(c echo.Context) error { return opds.GetCategoryBooksFeed(c) }, read)
+	e.GET(config.OPDSFormats, func(c echo.Context) error { return opds.GetFormatFeed(c) }, read)
+	e.GET(config.OPDSFormatsID, func(c echo.Context) error { return opds.GetFormatBooksFeed(c) }, read)
+	e.GET(config.OPDSSearch, func(c echo.Context) error { return opds.GetSearchFeed(c) }, read)
+	e.GET(config.OPDSOpenSearch, func(c echo.Context) error { return opds.GetOpenSearchDescription(c) }, read)
+}
+
+func setOaiController(e *echo.Echo, container container.Container) {
+	oai := controller.NewOaiController(container)
+	e.GET(config.OAI, func(c echo.Context) error { return oai.HandleRequest(c) })
+	e.POST(config.OAI, func(c echo.Context) error { return oai.HandleRequest(c) })
+}
+
+func setFeedController(e *echo.Echo, container container.Container) {
+	feed := controller.NewFeedController(container)
+	e.GET(config.FeedsNewAtom, func(c echo.Context) error { return feed.GetNewArrivalsAtom(c) })
+	e.GET(config.FeedsNewRSS, func(c echo.Context) error { return feed.GetNewArrivalsRSS(c) })
+}
+
 func setSwagger(container container.Container, e *echo.Echo) {
 	if container.GetConfig().Swagger.Enabled {
 		e.GET("/swagger/*", echoSwagger.WrapHandler)
 	}
 }
+
+// safeRoutes are the routes of http get which don't change the state. CSRFMiddleware doesn't check the token
+// of http get, so a new route of http get has to be added here after making sure that it doesn't change the state.
+// The login through the OpenID Connect provider is protected by the state parameter instead.
+var safeRoutes = map[string]bool{
+	config.APIBooksID:                 true,
+	config.APIBooks:                   true,
+	config.APICategories:              true,
+	config.APIFormats:                 true,
+	config.APIAccountLoginStatus:      true,
+	config.APIAccountLoginAccount:     true,
+	config.APIAccountCSRFToken:        true,
+	config.APIAccountOIDCLogin:        true,
+	config.APIAccountOIDCCallback:     true,
+	config.APIAdminAccountsID:         true,
+	config.APIAdminAccounts:           true,
+	config.WellKnownJWKS:              true,
+	config.APIAccountAPIKeys:          true,
+	config.APIAdminAPIKeys:            true,
+	config.APIAccountTwoFactor:        true,
+	config.APIAdminLockouts:           true,
+	config.APIAccountSessions:         true,
+	config.APIAdminAccountsIDSessions: true,
+	config.APIAdminAuditLogs:          true,
+	config.APIAdminAuditLogsCSV:       true,
+	config.APIAdminAuditLogsVerify:    true,
+	config.APIAdminAuthorities:        true,
+	config.APIAdminPermissions:        true,
+	config.APIHealth:                  true,
+	config.APIGraphQL:                 true, // the mutations are accepted only by http post
+	config.APIWebhooksID:              true,
+	config.APIWebhooks:                true,
+	config.APIWebhooksIDDeliveries:    true,
+	config.APIEvents:                  true,
+	config.APIEventsWebSocket:         true,
+	config.APIReportsSummary:          true,
+	config.APIReportsSummaryCSV:       true,
+	config.APIAdminBackup:             true,
+	config.APIBooksIDLabel:            true,
+	config.APIBooksLabels:             true,
+	config.OPDS:                       true,
+	config.OPDSBooks:                  true,
+	config.OPDSCategories:             true,
+	config.OPDSCategoriesID:           true,
+	config.OPDSFormats:                true,
+	config.OPDSFormatsID:              true,
+	config.OPDSSearch:                 true,
+	config.OPDSOpenSearch:             true,
+	config.OAI:                        true,
+	config.FeedsNewAtom:               true,
+	config.FeedsNewRSS:                true,
+	"/swagger/*":                      true,
+}
+
+// checkSafeRoutes panics if a route of http get isn't declared in safeRoutes, so that a route which changes
+// the state can't be added without the protection against the cross-site request forgery.
+func checkSafeRoutes(e *echo.Echo, container container.Container) {
+	for _, r := range e.Routes() {
+		if r.Method == http.MethodGet && !safeRoutes[r.Path] {
+			container.GetLogger().GetZapLogger().Panicf("The route of http get %s isn't declared in safeRoutes. "+
+				"Make sure that it doesn't change the state", r.Path)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/rpc/pb"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	accountKey    contextKey = "account"
+	authorization            = "authorization"
+	basicPrefix              = "Basic "
+)
+
+// methodPermissions are the permissions required by the methods of the services.
+var methodPermissions = map[string]string{
+	pb.BookService_GetBook_FullMethodName:           model.PermissionBookRead,
+	pb.BookService_ListBooks_FullMethodName:         model.PermissionBookRead,
+	pb.BookService_CreateBook_FullMethodName:        model.PermissionBookWrite,
+	pb.BookService_UpdateBook_FullMethodName:        model.PermissionBookWrite,
+	pb.BookService_DeleteBook_FullMethodName:        model.PermissionBookWrite,
+	pb.CatalogService_ListCategories_FullMethodName: model.PermissionCategoryRead,
+	pb.CatalogService_ListFormats_FullMethodName:    model.PermissionFormatRead,
+}
+
+// AuthenticationInterceptor authenticates the account by the username and password in the authorization metadata.
+// It uses the same accounts and permissions as the session authentication of the HTTP API,
+// and the methods which don't have the permission are denied. The accounts which have enabled or are required
+// to enable the two-factor authentication can't be authenticated, because the metadata has no code.
+func AuthenticationInterceptor(container container.Container) grpc.UnaryServerInterceptor {
+	accountService := service.NewAccountService(container)
+	twoFactorService := service.NewTwoFactorService(container)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		if !container.GetConfig().Extension.SecurityEnabled {
+			return handler(ctx, req)
+		}
+
+		username, password, ok := basicCredentials(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "the credentials are required")
+		}
+		account, err := accountService.AuthenticateByUsernameAndPassword(username, password, remoteIP(ctx))
+		if err != nil {
+			return nil, errorStatus(err)
+		}
+		if err := twoFactorService.Authenticate(account, "", remoteIP(ctx)); err != nil {
+			return nil, errorStatus(err)
+		}
+		permission, ok := methodPermissions[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, "the method doesn't have the permission")
+		}
+		if err := accountService.Authorize(account, permission); err != nil {
+			return nil, errorStatus(err)
+		}
+		return handler(context.WithValue(ctx, accountKey, account), req)
+	}
+}
+
+// GetAccount returns the account authenticated by the interceptor.
+func GetAccount(ctx context.Context) *model.Account {
+	account, _ := ctx.Value(accountKey).(*model.Account)
+	return account
+}
+
+// remoteIP returns the IP address of the client, or the empty string if it is unknown.
+func remoteIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// basicCredentials returns the username and password of the basic authorization metadata.
+func basicCredentials(ctx context.Context) (string, string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", "", false
+	}
+	values := md.Get(authorization)
+	if len(values) == 0 || !strings.HasPrefix(values[0], basicPrefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(values[0], basicPrefix))
+	if err != nil {
+		return "", "", false
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	return username, password, ok
+}
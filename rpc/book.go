@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/rpc/pb"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+)
+
+type bookServer struct {
+	pb.UnimplementedBookServiceServer
+	container container.Container
+	service   service.BookService
+}
+
+// NewBookServer is constructor.
+func NewBookServer(container container.Container) pb.BookServiceServer {
+	return &bookServer{container: container, service: service.NewBookService(container)}
+}
+
+// GetBook returns one book matched the id.
+func (s *bookServer) GetBook(ctx context.Context, req *pb.GetBookRequest) (*pb.Book, error) {
+	book, err := s.service.FindByID(formatID(req.GetId()))
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toBook(book), nil
+}
+
+// ListBooks returns the page of books matched the conditions.
+// The zero values of the conditions aren't used for filtering.
+func (s *bookServer) ListBooks(ctx context.Context, req *pb.ListBooksRequest) (*pb.ListBooksResponse, error) {
+	var categoryID, formatID string
+	if req.GetCategoryId() != 0 {
+		categoryID = strconv.FormatUint(uint64(req.GetCategoryId()), 10)
+	}
+	if req.GetFormatId() != 0 {
+		formatID = strconv.FormatUint(uint64(req.GetFormatId()), 10)
+	}
+
+	result, err := s.service.FindBooks(req.GetQuery(), categoryID, formatID,
+		strconv.Itoa(int(req.GetPage())), strconv.Itoa(int(req.GetSize())))
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	res := &pb.ListBooksResponse{Page: int32(result.Page), Size: int32(result.Size)}
+	if result.Content != nil {
+		for i := range *result.Content {
+			res.Books = append(res.Books, toBook(&(*result.Content)[i]))
+		}
+	}
+	return res, nil
+}
+
+// CreateBook registers a new book.
+func (s *bookServer) CreateBook(ctx context.Context, req *pb.CreateBookRequest) (*pb.Book, error) {
+	book, err := s.service.CreateBook(s.newBookDto(ctx, req.GetBook()))
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toBook(book), nil
+}
+
+// UpdateBook updates the existing book.
+func (s *bookServer) UpdateBook(ctx context.Context, req *pb.UpdateBookRequest) (*pb.Book, error) {
+	book, err := s.service.UpdateBook(s.newBookDto(ctx, req.GetBook()), formatID(req.GetId()))
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toBook(book), nil
+}
+
+// DeleteBook deletes the existing book.
+func (s *bookServer) DeleteBook(ctx context.Context, req *pb.DeleteBookRequest) (*pb.Book, error) {
+	book, err := s.service.DeleteBook(formatID(req.GetId()))
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toBook(book), nil
+}
+
+func (s *bookServer) newBookDto(ctx context.Context, input *pb.BookInput) *dto.BookDto {
+	bookDto := dto.NewBookDto(s.container.GetMessages(getLocale(ctx, s.container)))
+	bookDto.Title = input.GetTitle()
+	bookDto.Isbn = input.GetIsbn()
+	bookDto.CategoryID = uint(input.GetCategoryId())
+	bookDto.FormatID = uint(input.GetFormatId())
+	return bookDto
+}
+
+func toBook(book *model.Book) *pb.Book {
+	b := &pb.Book{
+		Id:        uint32(book.ID),
+		Title:     book.Title,
+		Isbn:      book.Isbn,
+		CreatedAt: timestamppb.New(book.CreatedAt),
+		UpdatedAt: timestamppb.New(book.UpdatedAt),
+	}
+	if book.Category != nil {
+		b.Category = toCategory(book.Category)
+	}
+	if book.Format != nil {
+		b.Format = toFormat(book.Format)
+	}
+	return b
+}
+
+func formatID(id uint32) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
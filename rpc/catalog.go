@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/rpc/pb"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type catalogServer struct {
+	pb.UnimplementedCatalogServiceServer
+	container       container.Container
+	categoryService service.CategoryService
+	formatService   service.FormatService
+}
+
+// NewCatalogServer is constructor.
+func NewCatalogServer(container container.Container) pb.CatalogServiceServer {
+	return &catalogServer{
+		container:       container,
+		categoryService: service.NewCategoryService(container),
+		formatService:   service.NewFormatService(container),
+	}
+}
+
+// ListCategories returns all categories.
+func (s *catalogServer) ListCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	categories := s.categoryService.FindAllCategories()
+	if categories == nil {
+		return nil, status.Error(codes.Internal, "failed to fetch data")
+	}
+
+	res := &pb.ListCategoriesResponse{}
+	for i := range *categories {
+		res.Categories = append(res.Categories, toCategory(&(*categories)[i]))
+	}
+	return res, nil
+}
+
+// ListFormats returns all formats.
+func (s *catalogServer) ListFormats(ctx context.Context, req *pb.ListFormatsRequest) (*pb.ListFormatsResponse, error) {
+	formats := s.formatService.FindAllFormats()
+	if formats == nil {
+		return nil, status.Error(codes.Internal, "failed to fetch data")
+	}
+
+	res := &pb.ListFormatsResponse{}
+	for i := range *formats {
+		res.Formats = append(res.Formats, toFormat(&(*formats)[i]))
+	}
+	return res, nil
+}
+
+func toCategory(category *model.Category) *pb.Category {
+	return &pb.Category{Id: uint32(category.ID), Name: category.Name}
+}
+
+func toFormat(format *model.Format) *pb.Format {
+	return &pb.Format{Id: uint32(format.ID), Name: format.Name}
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+)
+
+// errorCodes are the status codes of gRPC for the kinds of the errors of services.
+var errorCodes = map[service.ErrorKind]codes.Code{
+	service.KindValidation:       codes.InvalidArgument,
+	service.KindInvalidReference: codes.InvalidArgument,
+	service.KindNotFound:         codes.NotFound,
+	service.KindConflict:         codes.FailedPrecondition,
+	service.KindUnauthorized:     codes.Unauthenticated,
+	service.KindForbidden:        codes.PermissionDenied,
+	service.KindTooManyRequests:  codes.ResourceExhausted,
+	service.KindInternal:         codes.Internal,
+}
+
+// errorStatus converts the error returned by the services to the status of gRPC.
+// The messages of fields are returned as the field violations of the BadRequest details.
+func errorStatus(err error) error {
+	e := service.AsError(err)
+	code, ok := errorCodes[e.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+	st := status.New(code, e.Detail)
+	if len(e.Fields) == 0 {
+		return st.Err()
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations,
+			&errdetails.BadRequest_FieldViolation{Field: field, Description: e.Fields[field]})
+	}
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,23 @@
+package rpc
+
+import (
+	"context"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"google.golang.org/grpc/metadata"
+)
+
+// acceptLanguage is the metadata key which has the preferred languages same as the HTTP header.
+const acceptLanguage = "accept-language"
+
+// getLocale returns the locale of the call chosen from the preference of the account and the accept-language metadata.
+func getLocale(ctx context.Context, container container.Container) string {
+	var preferences []string
+	if account := GetAccount(ctx); account != nil {
+		preferences = append(preferences, account.Locale)
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		preferences = append(preferences, md.Get(acceptLanguage)...)
+	}
+	locale, _ := container.GetBundles().Match(preferences...)
+	return locale
+}
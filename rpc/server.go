@@ -0,0 +1,59 @@
+package rpc
+
+//go:generate protoc -I ../proto --go_out=pb --go_opt=paths=source_relative --go-grpc_out=pb --go-grpc_opt=paths=source_relative bookstore.proto
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/rpc/pb"
+	"google.golang.org/grpc"
+	"net"
+)
+
+const defaultPort = "9090"
+
+// Server represents the gRPC server which runs alongside the HTTP API.
+type Server interface {
+	Start() error
+	Stop()
+	GetServer() *grpc.Server
+}
+
+type server struct {
+	container container.Container
+	server    *grpc.Server
+}
+
+// NewServer is constructor. The services share the container with the HTTP API.
+func NewServer(container container.Container) Server {
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(AuthenticationInterceptor(container)),
+	)
+	pb.RegisterBookServiceServer(s, NewBookServer(container))
+	pb.RegisterCatalogServiceServer(s, NewCatalogServer(container))
+	return &server{container: container, server: s}
+}
+
+// Start listens on the configured port and serves gRPC requests until the server is stopped.
+func (s *server) Start() error {
+	port := s.container.GetConfig().Grpc.Port
+	if port == "" {
+		port = defaultPort
+	}
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	s.container.GetLogger().GetZapLogger().Infof(fmt.Sprintf("Started gRPC server, :%s", port))
+	return s.server.Serve(listener)
+}
+
+// Stop stops the server gracefully.
+func (s *server) Stop() {
+	s.server.GracefulStop()
+}
+
+// GetServer returns the object of grpc server.
+func (s *server) GetServer() *grpc.Server {
+	return s.server
+}
@@ -1,40 +1,430 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/config"
 	"github.com/lyh-demo/go-webapp-demo/container"
 	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/util"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // AccountService is a service for managing user account.
 type AccountService interface {
-	AuthenticateByUsernameAndPassword(username string, password string) (bool, *model.Account)
+	AuthenticateByUsernameAndPassword(username string, password string, remoteIP string) (*model.Account, error)
+	UpdateLocale(account *model.Account, dto *dto.LocaleDto) (*model.Account, error)
+	ValidateSession(account *model.Account) error
+	FindPermissions(account *model.Account) ([]string, error)
+	Authorize(account *model.Account, permissions ...string) error
+	FindAccounts(name string, authorityID string, page string, size string) (*model.AccountPage, error)
+	FindAccountByID(id string) (*model.Account, error)
+	CreateAccount(dto *dto.AccountDto) (*model.Account, error)
+	UpdateAccount(dto *dto.AccountDto, id string) (*model.Account, error)
+	DeleteAccount(id string) (*model.Account, error)
 }
 
+// dummyPasswordHash is compared with the password of an unknown username, so that the login takes
+// as long as the login with an incorrect password and doesn't reveal which usernames exist.
+var dummyPasswordHash = []byte("$2a$10$Xkox8gwwWbo4z3BkHr7NDuXHhMwjXm9dkkBuMnLkHSt9On4.X8FgC")
+
 type accountService struct {
 	container container.Container
+	lockout   LockoutService
 }
 
 // NewAccountService is constructor.
 func NewAccountService(container container.Container) AccountService {
-	return &accountService{container: container}
+	return &accountService{container: container, lockout: NewLockoutService(container)}
 }
 
 // AuthenticateByUsernameAndPassword authenticates by using username and plain text password.
-func (a *accountService) AuthenticateByUsernameAndPassword(username string, password string) (bool, *model.Account) {
+// The failed logins are counted by the username and the remote IP address, and the login is refused
+// with the too many requests error while they are locked out. The failures aren't forgotten until
+// TwoFactorService passes the second step, because the incorrect codes are counted by the username too.
+func (a *accountService) AuthenticateByUsernameAndPassword(username string, password string,
+	remoteIP string) (*model.Account, error) {
+	if err := a.lockout.Check(username, remoteIP); err != nil {
+		return nil, err
+	}
+
 	rep := a.container.GetRepository()
 	logger := a.container.GetLogger()
 	account := model.Account{}
 	result, err := account.FindByName(rep, username)
 	if err != nil {
-		logger.GetZapLogger().Errorf(err.Error())
-		return false, nil
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		logger.GetZapLogger().Errorf("failed to login as %s from %s, %s", username, remoteIP, err.Error())
+		a.lockout.RecordFailure(username, remoteIP)
+		return nil, NewUnauthorizedError("The username or the password is incorrect")
 	}
 
+	if result.Disabled {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		logger.GetZapLogger().Errorf("failed to login as %s from %s, the account is disabled", username, remoteIP)
+		a.lockout.RecordFailure(username, remoteIP)
+		return nil, NewUnauthorizedError("The username or the password is incorrect")
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(result.Password), []byte(password)); err != nil {
-		logger.GetZapLogger().Errorf(err.Error())
-		return false, nil
+		logger.GetZapLogger().Errorf("failed to login as %s from %s, %s", username, remoteIP, err.Error())
+		a.lockout.RecordFailure(username, remoteIP)
+		return nil, NewUnauthorizedError("The username or the password is incorrect")
+	}
+	return result, nil
+}
+
+// ValidateSession checks that the account of the session still exists and is enabled, and that the session
+// was created after the last change of the password.
+func (a *accountService) ValidateSession(account *model.Account) error {
+	rep := a.container.GetRepository()
+	ac := model.Account{}
+	current, err := ac.FindByID(rep, account.ID).Take()
+	if err != nil || current.Disabled || current.SessionVersion != account.SessionVersion {
+		return NewUnauthorizedError("The session is no longer valid")
+	}
+	return nil
+}
+
+// FindPermissions returns the permissions of all authorities which the given account currently holds.
+// The admin authority has all permissions. The account authenticated by the access token or the API key has
+// the permissions resolved by the authentication, and they are used as they are in the request.
+func (a *accountService) FindPermissions(account *model.Account) ([]string, error) {
+	if account.Permissions != nil {
+		return account.Permissions, nil
+	}
+
+	rep := a.container.GetRepository()
+	ac := model.Account{}
+	current, err := ac.FindByID(rep, account.ID).Take()
+	if err != nil || current.Disabled || current.SessionVersion != account.SessionVersion {
+		return nil, NewUnauthorizedError("The session is no longer valid")
+	}
+	if current.HasAuthority(model.AuthorityAdmin) {
+		return model.AllPermissionNames(), nil
+	}
+
+	ap := model.AuthorityPermission{}
+	permissions, err := ap.FindByAccountID(rep, current.ID)
+	if err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return permissions, nil
+}
+
+// Authorize returns nil if the given account has one of the given permissions, and the forbidden error otherwise.
+func (a *accountService) Authorize(account *model.Account, permissions ...string) error {
+	held, err := a.FindPermissions(account)
+	if err != nil {
+		return err
+	}
+	for _, h := range held {
+		for _, p := range permissions {
+			if h == p {
+				return nil
+			}
+		}
+	}
+	return NewForbiddenError("The current user doesn't have the permission")
+}
+
+// UpdateLocale saves the preferred locale of the given account, which is used for the messages of responses.
+func (a *accountService) UpdateLocale(account *model.Account, dto *dto.LocaleDto) (*model.Account, error) {
+	if fields := dto.Validate(a.container.GetBundles().Locales()); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	rep := a.container.GetRepository()
+	account.Locale = dto.Locale
+	result, err := account.UpdateLocale(rep)
+	if err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the update", err)
+	}
+	return result, nil
+}
+
+// FindAccounts returns the page object of accounts partially matched given name and having given authority.
+// The empty name and authority aren't used as conditions.
+func (a *accountService) FindAccounts(name string, authorityID string, page string, size string) (*model.AccountPage, error) {
+	if authorityID != "" && !util.IsNumeric(authorityID) {
+		return nil, NewValidationError("The request has invalid fields",
+			map[string]string{"authorityId": fmt.Sprintf("Authority %s does not exist", authorityID)})
+	}
+
+	rep := a.container.GetRepository()
+	account := model.Account{}
+	result, err := account.FindByFilter(rep, name, util.ConvertToUint(authorityID), page, size)
+	if err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindAccountByID returns one record matched account's id.
+func (a *accountService) FindAccountByID(id string) (*model.Account, error) {
+	if !util.IsNumeric(id) {
+		return nil, accountNotFound(id)
+	}
+
+	rep := a.container.GetRepository()
+	account := model.Account{}
+	result, err := account.FindByID(rep, util.ConvertToUint(id)).Take()
+	if err != nil {
+		return nil, accountNotFound(id)
+	}
+	return result, nil
+}
+
+// CreateAccount registers the given account, and the password is hashed by using bcrypt.
+func (a *accountService) CreateAccount(dto *dto.AccountDto) (*model.Account, error) {
+	if fields := dto.ValidateForCreate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	rep := a.container.GetRepository()
+	var result *model.Account
+	var err error
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = txCreateAccount(txRep, dto)
+		return err
+	}); trErr != nil {
+		return nil, a.transactionError(trErr, "Failed to the registration")
+	}
+	return result, nil
+}
+
+func txCreateAccount(txRep repository.Repository, dto *dto.AccountDto) (*model.Account, error) {
+	var err error
+	account := dto.Create()
+
+	if err = txCheckAccount(txRep, account, dto); err != nil {
+		return nil, err
+	}
+	if _, err = account.Create(txRep); err != nil {
+		return nil, err
+	}
+	aa := model.AccountAuthority{}
+	if err = aa.ReplaceByAccountID(txRep, account.ID, account.AdditionalAuthorityIDs()); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdateAccount updates the given account. The password is kept if it is omitted, and the last enabled
+// administrator can't be disabled or lose the authority.
+func (a *accountService) UpdateAccount(dto *dto.AccountDto, id string) (*model.Account, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
 	}
+	if !util.IsNumeric(id) {
+		return nil, accountNotFound(id)
+	}
+
+	rep := a.container.GetRepository()
+	var result *model.Account
+	var err error
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = txUpdateAccount(txRep, dto, id)
+		return err
+	}); trErr != nil {
+		return nil, a.transactionError(trErr, "Failed to the update")
+	}
+	return result, nil
+}
+
+func txUpdateAccount(txRep repository.Repository, dto *dto.AccountDto, id string) (*model.Account, error) {
+	var account *model.Account
+	var err error
+
+	ac := model.Account{}
+	if account, err = ac.FindByID(txRep, util.ConvertToUint(id)).Take(); err != nil {
+		return nil, accountNotFound(id)
+	}
+	wasAdmin := isEnabledAdmin(account)
+
+	account.Name = dto.Name
+	account.Email = dto.Email
+	account.AuthorityID = dto.AuthorityID
+	account.Disabled = dto.Disabled
+	if dto.Password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(dto.Password), config.PasswordHashCost)
+		if err != nil {
+			return nil, err
+		}
+		account.Password = string(hashed)
+	}
+
+	if err = txCheckAccount(txRep, account, dto); err != nil {
+		return nil, err
+	}
+	if wasAdmin && !isEnabledAdmin(account) {
+		if err = txCheckLastAdmin(txRep, "The last administrator can't be disabled or lose the authority"); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = account.Update(txRep); err != nil {
+		return nil, err
+	}
+	if dto.Password != "" {
+		// The new password invalidates the sessions and the refresh tokens as well as the change by the account.
+		if _, err = account.UpdatePassword(txRep); err != nil {
+			return nil, err
+		}
+		as := model.AccountSession{}
+		if _, err = as.DeleteByAccountID(txRep, account.ID); err != nil {
+			return nil, err
+		}
+	}
+	aa := model.AccountAuthority{}
+	if err = aa.ReplaceByAccountID(txRep, account.ID, account.AdditionalAuthorityIDs()); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// DeleteAccount deletes the given account. The last enabled administrator can't be deleted.
+func (a *accountService) DeleteAccount(id string) (*model.Account, error) {
+	if !util.IsNumeric(id) {
+		return nil, accountNotFound(id)
+	}
+
+	rep := a.container.GetRepository()
+	var result *model.Account
+	var err error
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = txDeleteAccount(txRep, id)
+		return err
+	}); trErr != nil {
+		return nil, a.transactionError(trErr, "Failed to delete")
+	}
+	return result, nil
+}
+
+func txDeleteAccount(txRep repository.Repository, id string) (*model.Account, error) {
+	var account *model.Account
+	var err error
+
+	ac := model.Account{}
+	if account, err = ac.FindByID(txRep, util.ConvertToUint(id)).Take(); err != nil {
+		return nil, accountNotFound(id)
+	}
+	if isEnabledAdmin(account) {
+		if err = txCheckLastAdmin(txRep, "The last administrator can't be deleted"); err != nil {
+			return nil, err
+		}
+	}
+
+	aa := model.AccountAuthority{}
+	if err = aa.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	rt := model.RefreshToken{}
+	if err = rt.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	ak := model.APIKey{}
+	if err = ak.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	ai := model.AccountIdentity{}
+	if err = ai.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	tf := model.TwoFactor{}
+	if err = tf.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	rc := model.RecoveryCode{}
+	if err = rc.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	as := model.AccountSession{}
+	if _, err = as.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	prt := model.PasswordResetToken{}
+	if err = prt.DeleteByAccountID(txRep, account.ID); err != nil {
+		return nil, err
+	}
+	if _, err = account.Delete(txRep); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// txCheckAccount sets the authorities of the account, and returns the error if an authority doesn't exist
+// or the name or the email is used by another account.
+func txCheckAccount(txRep repository.Repository, account *model.Account, dto *dto.AccountDto) error {
+	var err error
+	authority := model.Authority{}
+	if account.Authority, err = authority.FindByID(txRep, dto.AuthorityID).Take(); err != nil {
+		return NewInvalidReferenceError("The authority doesn't exist", dto.ReferenceErrors(false))
+	}
+	account.Authorities = []model.Authority{*account.Authority}
+	for _, id := range dto.AdditionalAuthorityIDs() {
+		additional, err := authority.FindByID(txRep, id).Take()
+		if err != nil {
+			return NewInvalidReferenceError("The authority doesn't exist", dto.AdditionalReferenceErrors(id))
+		}
+		account.Authorities = append(account.Authorities, *additional)
+	}
+
+	exists, err := account.Exist(txRep, account.Name, account.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return NewConflictError(fmt.Sprintf("Account %s already exists", account.Name))
+	}
+
+	if account.Email != "" {
+		if exists, err = account.ExistEmail(txRep, account.Email, account.ID); err != nil {
+			return err
+		}
+		if exists {
+			return NewConflictError(fmt.Sprintf("The email %s is already used", account.Email))
+		}
+	}
+	return nil
+}
+
+// txCheckLastAdmin returns the conflict error if there is only one enabled administrator. The administrators
+// stay locked until the end of the transaction, so that the concurrent requests can't remove the last two at once.
+func txCheckLastAdmin(txRep repository.Repository, detail string) error {
+	account := model.Account{}
+	ids, err := account.LockByAuthorityName(txRep, model.AuthorityAdmin)
+	if err != nil {
+		return err
+	}
+	if len(ids) <= 1 {
+		return NewConflictError(detail)
+	}
+	return nil
+}
+
+func isEnabledAdmin(account *model.Account) bool {
+	return !account.Disabled && account.HasAuthority(model.AuthorityAdmin)
+}
+
+// transactionError converts the error of the transaction to the error of services. The errors of services
+// are returned as they are, and the others are logged as the failures of the database.
+func (a *accountService) transactionError(err error, failure string) error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	return NewInternalError(failure, err)
+}
 
-	return true, result
+func accountNotFound(id string) error {
+	return NewNotFoundError(fmt.Sprintf("Account %s does not exist", id))
 }
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareForAccountTest() (container.Container, service.AccountService) {
+	container := test.PrepareForServiceTest()
+	container.GetConfig().Lockout.Enabled = true
+	container.GetConfig().Lockout.MaxFailures = 3
+	container.GetConfig().Lockout.MaxIPFailures = 5
+	return container, service.NewAccountService(container)
+}
+
+func TestAuthenticateByUsernameAndPassword_Success(t *testing.T) {
+	_, accounts := prepareForAccountTest()
+
+	result, err := accounts.AuthenticateByUsernameAndPassword("test", "test", "192.0.2.1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test", result.Name)
+}
+
+func TestAuthenticateByUsernameAndPassword_UnknownUsername(t *testing.T) {
+	container, accounts := prepareForAccountTest()
+
+	result, err := accounts.AuthenticateByUsernameAndPassword("unknown", "test", "192.0.2.1")
+
+	assert.Nil(t, result)
+	assertUnauthorized(t, err)
+	assertTooManyRequests(t, service.NewLockoutService(container).Check("unknown", "192.0.2.2"))
+}
+
+func TestAuthenticateByUsernameAndPassword_DisabledAccount(t *testing.T) {
+	container, accounts := prepareForAccountTest()
+	container.GetRepository().Exec("update account_master set disabled = ? where name = ?", true, "test")
+
+	result, err := accounts.AuthenticateByUsernameAndPassword("test", "test", "192.0.2.1")
+
+	assert.Nil(t, result)
+	assertUnauthorized(t, err)
+	assertTooManyRequests(t, service.NewLockoutService(container).Check("test", "192.0.2.2"))
+}
+
+func TestDeleteAccount_LastAdmin(t *testing.T) {
+	_, accounts := prepareForAccountTest()
+
+	_, err := accounts.DeleteAccount("2")
+	assert.NoError(t, err)
+
+	result, err := accounts.DeleteAccount("1")
+	assert.Nil(t, result)
+	if assert.Error(t, err) {
+		assert.Equal(t, service.KindConflict, service.AsError(err).Kind)
+	}
+}
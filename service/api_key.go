@@ -0,0 +1,181 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"time"
+)
+
+const (
+	// apiKeyPrefix is the prefix of the API keys, which makes the leaked keys easy to find.
+	apiKeyPrefix = "gwd_"
+	// apiKeyPrefixLength is the length of the beginning of the key shown to identify the key.
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+	// apiKeyLastUsedInterval is the interval to record the time when the key was used.
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// APIKeyService is a service for the personal API keys to access the API without the password.
+type APIKeyService interface {
+	FindAPIKeys(account *model.Account) (*[]model.APIKey, error)
+	FindAllAPIKeys(accountID string) (*[]model.APIKey, error)
+	CreateAPIKey(account *model.Account, dto *dto.APIKeyDto) (*model.APIKey, error)
+	RevokeAPIKey(account *model.Account, id string) (*model.APIKey, error)
+	RevokeAnyAPIKey(id string) (*model.APIKey, error)
+	Authenticate(key string) (*model.Account, error)
+}
+
+type apiKeyService struct {
+	container container.Container
+	accounts  AccountService
+}
+
+// NewAPIKeyService is constructor.
+func NewAPIKeyService(container container.Container) APIKeyService {
+	return &apiKeyService{container: container, accounts: NewAccountService(container)}
+}
+
+// FindAPIKeys returns the API keys of the given account, including the revoked and expired ones.
+func (k *apiKeyService) FindAPIKeys(account *model.Account) (*[]model.APIKey, error) {
+	return k.findAPIKeys(account.ID)
+}
+
+// FindAllAPIKeys returns the API keys of all accounts, or the ones of the account if its ID is given.
+func (k *apiKeyService) FindAllAPIKeys(accountID string) (*[]model.APIKey, error) {
+	var id uint
+	if util.IsNumeric(accountID) {
+		id = util.ConvertToUint(accountID)
+	}
+	return k.findAPIKeys(id)
+}
+
+func (k *apiKeyService) findAPIKeys(accountID uint) (*[]model.APIKey, error) {
+	rep := k.container.GetRepository()
+	key := model.APIKey{}
+	result, err := key.FindByAccountID(rep, accountID)
+	if err != nil {
+		k.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// CreateAPIKey creates a new API key of the given account. The scopes must be the permissions which
+// the account has. Only the hash of the key is persisted, and the key is returned only this time.
+func (k *apiKeyService) CreateAPIKey(account *model.Account, dto *dto.APIKeyDto) (*model.APIKey, error) {
+	if fields := dto.Validate(time.Now()); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+	permissions, err := k.accounts.FindPermissions(account)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range dto.Scopes {
+		if !contains(permissions, scope) {
+			return nil, NewValidationError("The account doesn't have the scopes", dto.ScopeErrors())
+		}
+	}
+
+	secret, err := newRandomToken()
+	if err != nil {
+		k.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the registration", err)
+	}
+	plain := apiKeyPrefix + secret
+	result, err := dto.Create(account.ID, plain[:apiKeyPrefixLength], hashToken(plain)).
+		Create(k.container.GetRepository())
+	if err != nil {
+		k.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the registration", err)
+	}
+	result.Key = plain
+	return result, nil
+}
+
+// RevokeAPIKey revokes the API key of the given account. The keys of the other accounts are treated
+// as if they don't exist.
+func (k *apiKeyService) RevokeAPIKey(account *model.Account, id string) (*model.APIKey, error) {
+	key, err := k.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.AccountID != account.ID {
+		return nil, apiKeyNotFound(id)
+	}
+	return k.revoke(key)
+}
+
+// RevokeAnyAPIKey revokes the API key of any account.
+func (k *apiKeyService) RevokeAnyAPIKey(id string) (*model.APIKey, error) {
+	key, err := k.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return k.revoke(key)
+}
+
+func (k *apiKeyService) findByID(id string) (*model.APIKey, error) {
+	if !util.IsNumeric(id) {
+		return nil, apiKeyNotFound(id)
+	}
+	key := model.APIKey{}
+	result, err := key.FindByID(k.container.GetRepository(), util.ConvertToUint(id)).Take()
+	if err != nil {
+		return nil, apiKeyNotFound(id)
+	}
+	return result, nil
+}
+
+func (k *apiKeyService) revoke(key *model.APIKey) (*model.APIKey, error) {
+	if err := key.Revoke(k.container.GetRepository(), time.Now()); err != nil {
+		k.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to revoke the API key", err)
+	}
+	return key, nil
+}
+
+// Authenticate returns the account of the API key. The account has the permissions which are in the scopes
+// of the key and which the account currently has, so the key can't exceed the authorities of the account.
+func (k *apiKeyService) Authenticate(key string) (*model.Account, error) {
+	now := time.Now()
+	rep := k.container.GetRepository()
+	ak := model.APIKey{}
+	current, err := ak.FindByKeyHash(rep, hashToken(key)).Take()
+	if err != nil || !current.IsActive(now) {
+		return nil, invalidAPIKey()
+	}
+
+	a := model.Account{}
+	account, err := a.FindByID(rep, current.AccountID).Take()
+	if err != nil || account.Disabled {
+		return nil, invalidAPIKey()
+	}
+	permissions, err := k.accounts.FindPermissions(account)
+	if err != nil {
+		return nil, err
+	}
+	account.Permissions = []string{}
+	for _, scope := range current.Scopes {
+		if contains(permissions, scope) {
+			account.Permissions = append(account.Permissions, scope)
+		}
+	}
+
+	if current.LastUsedAt == nil || now.Sub(*current.LastUsedAt) >= apiKeyLastUsedInterval {
+		if err = current.UpdateLastUsedAt(rep, now); err != nil {
+			k.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		}
+	}
+	return account, nil
+}
+
+func invalidAPIKey() error {
+	return NewUnauthorizedError("The API key is invalid, revoked or has expired")
+}
+
+func apiKeyNotFound(id string) error {
+	return NewNotFoundError(fmt.Sprintf("API key %s does not exist", id))
+}
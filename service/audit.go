@@ -0,0 +1,309 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAuditPurgeInterval  = 60
+	defaultAuditExportLimit    = 10000
+	defaultAuditDenialInterval = 60
+	auditAppendAttempts        = 3
+	auditVerifyBatchSize       = 500
+)
+
+// auditMutex serializes the appends to the chain of the audit log in this process. The unique index of the
+// previous hash rejects the concurrent appends of the other processes, and they are retried.
+var auditMutex sync.Mutex
+
+// auditDenials holds the time when the denial of each remote IP address was recorded last, and the number of
+// the denials which aren't recorded since then, so that the denied requests don't write a record for each.
+var auditDenials = struct {
+	sync.Mutex
+	last       map[string]*auditDenial
+	lastPurged time.Time
+}{last: map[string]*auditDenial{}}
+
+type auditDenial struct {
+	recordedAt time.Time
+	suppressed int
+}
+
+// AuditService is a service for the security audit log of the authentication and the authorization.
+// The records are hash-chained, so the deletion or the modification of a record is detected by the verification.
+type AuditService interface {
+	Record(log *model.AuditLog)
+	RecordDenial(log *model.AuditLog)
+	FindAuditLogs(dto *dto.AuditLogFilterDto, page string, size string) (*model.AuditLogPage, error)
+	CreateAuditLogCSV(dto *dto.AuditLogFilterDto) ([]byte, bool, error)
+	Verify() (*model.AuditLogVerification, error)
+	Purge() (int64, error)
+	StartPurge() func()
+}
+
+type auditService struct {
+	container container.Container
+}
+
+// NewAuditService is constructor.
+func NewAuditService(container container.Container) AuditService {
+	return &auditService{container: container}
+}
+
+// Record appends the record to the chain of the audit log if the audit log is enabled.
+// The failure is only logged, because the audit log mustn't fail the request.
+func (a *auditService) Record(log *model.AuditLog) {
+	if !a.container.GetConfig().Audit.Enabled {
+		return
+	}
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	rep := a.container.GetRepository()
+	var err error
+	for i := 0; i < auditAppendAttempts; i++ {
+		err = rep.Transaction(func(txRep repository.Repository) error {
+			prevHash := ""
+			al := model.AuditLog{}
+			if last, err := al.FindLast(txRep).Take(); err == nil {
+				prevHash = last.Hash
+			}
+			log.ID = 0
+			log.Chain(prevHash, time.Now())
+			_, err := log.Create(txRep)
+			return err
+		})
+		if err == nil {
+			return
+		}
+	}
+	a.container.GetLogger().GetZapLogger().Errorf("failed to record the audit log of %s, %s", log.Event, err.Error())
+}
+
+// RecordDenial records the denied request at most once per the denial interval of the configuration for each
+// event and remote IP address. The number of the denials which aren't recorded is appended to the details of the next one.
+func (a *auditService) RecordDenial(log *model.AuditLog) {
+	if !a.container.GetConfig().Audit.Enabled {
+		return
+	}
+	interval := time.Duration(positive(a.container.GetConfig().Audit.DenialInterval, defaultAuditDenialInterval)) *
+		time.Second
+	key := log.Event + " " + log.RemoteIP
+	now := time.Now()
+
+	auditDenials.Lock()
+	d, ok := auditDenials.last[key]
+	if ok && now.Sub(d.recordedAt) < interval {
+		d.suppressed++
+		auditDenials.Unlock()
+		return
+	}
+	suppressed := 0
+	if ok {
+		suppressed = d.suppressed
+	}
+	auditDenials.last[key] = &auditDenial{recordedAt: now}
+	// the expired denials are forgotten, not to grow the map by the requests from many addresses.
+	if now.Sub(auditDenials.lastPurged) >= interval {
+		for k, d := range auditDenials.last {
+			if now.Sub(d.recordedAt) >= interval {
+				delete(auditDenials.last, k)
+			}
+		}
+		auditDenials.lastPurged = now
+	}
+	auditDenials.Unlock()
+
+	if suppressed > 0 {
+		log.Details += fmt.Sprintf(" (%d more denials aren't recorded)", suppressed)
+	}
+	a.Record(log)
+}
+
+// FindAuditLogs returns the page of the records matched the filters in order of the newest.
+func (a *auditService) FindAuditLogs(dto *dto.AuditLogFilterDto, page string,
+	size string) (*model.AuditLogPage, error) {
+	cond, fields := dto.Create()
+	if fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+	al := model.AuditLog{}
+	result, err := al.FindByCondition(a.container.GetRepository(), cond, page, size)
+	if err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// CreateAuditLogCSV returns the newest records matched the filters as CSV in order of the oldest, at most the export
+// limit of the configuration, and whether the older records are left out by the limit.
+func (a *auditService) CreateAuditLogCSV(dto *dto.AuditLogFilterDto) ([]byte, bool, error) {
+	cond, fields := dto.Create()
+	if fields != nil {
+		return nil, false, NewValidationError("The request has invalid fields", fields)
+	}
+	al := model.AuditLog{}
+	limit := positive(a.container.GetConfig().Audit.ExportLimit, defaultAuditExportLimit)
+	// one more record is fetched to know whether the records are truncated.
+	logs, err := al.FindLatestByCondition(a.container.GetRepository(), cond, limit+1)
+	if err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, false, NewInternalError("Failed to fetch data", err)
+	}
+	truncated := len(*logs) > limit
+	if truncated {
+		*logs = (*logs)[1:]
+	}
+
+	records := [][]string{{"id", "createdAt", "event", "outcome", "actorId", "actorName", "target", "remoteIp",
+		"userAgent", "details", "prevHash", "hash"}}
+	for _, l := range *logs {
+		actorID := ""
+		if l.ActorID != nil {
+			actorID = strconv.FormatUint(uint64(*l.ActorID), 10)
+		}
+		records = append(records, []string{strconv.FormatUint(uint64(l.ID), 10),
+			l.CreatedAt.UTC().Format(time.RFC3339Nano), l.Event, l.Outcome, actorID, escapeCSVFormula(l.ActorName),
+			escapeCSVFormula(l.Target), escapeCSVFormula(l.RemoteIP), escapeCSVFormula(l.UserAgent),
+			escapeCSVFormula(l.Details), l.PrevHash, l.Hash})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, false, NewInternalError("Failed to write CSV", err)
+	}
+	return buf.Bytes(), truncated, nil
+}
+
+// escapeCSVFormula prefixes the value given by the client with a quote if it starts with a character which
+// the spreadsheets evaluate as a formula.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// Verify walks the chain of the audit log from the oldest record, and reports the first record which breaks it.
+// The oldest record may refer to a record deleted by the purge, which is recorded in the chain.
+func (a *auditService) Verify() (*model.AuditLogVerification, error) {
+	rep := a.container.GetRepository()
+	al := model.AuditLog{}
+	result := &model.AuditLogVerification{Valid: true}
+	var prev *model.AuditLog
+	for {
+		logs, err := al.FindAfter(rep, result.LastID, auditVerifyBatchSize)
+		if err != nil {
+			a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+			return nil, NewInternalError("Failed to fetch data", err)
+		}
+		for i := range *logs {
+			log := &(*logs)[i]
+			reason := ""
+			switch {
+			case prev == nil && log.PrevHash != "" && !a.isPurged(rep, log.PrevHash):
+				reason = "The records before it are missing"
+			case prev != nil && log.PrevHash != prev.Hash:
+				reason = "The previous record is missing or modified"
+			case log.ComputeHash() != log.Hash:
+				reason = "The record is modified"
+			}
+			if reason != "" {
+				result.Valid = false
+				result.BrokenID = log.ID
+				result.Reason = reason
+				return result, nil
+			}
+			if prev == nil {
+				result.FirstID = log.ID
+			}
+			result.Checked++
+			result.LastID = log.ID
+			result.LastHash = log.Hash
+			prev = log
+		}
+		if len(*logs) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// isPurged returns true if the record of given hash has been deleted by the purge.
+func (a *auditService) isPurged(rep repository.Repository, hash string) bool {
+	al := model.AuditLog{}
+	logs, err := al.FindAllByCondition(rep, &model.AuditLogCondition{Event: model.AuditPurge, Target: hash}, 1)
+	return err == nil && len(*logs) > 0
+}
+
+// Purge deletes the records older than the retention, and records the purge with the hash of the last deleted
+// record. The latest record is always kept so that the chain continues.
+func (a *auditService) Purge() (int64, error) {
+	conf := a.container.GetConfig().Audit
+	if !conf.Enabled || conf.RetentionDays <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -conf.RetentionDays)
+
+	var count int64
+	var anchor string
+	auditMutex.Lock()
+	trErr := a.container.GetRepository().Transaction(func(txRep repository.Repository) error {
+		al := model.AuditLog{}
+		keep, err := al.FindFirstFrom(txRep, cutoff).Take()
+		if err != nil {
+			if keep, err = al.FindLast(txRep).Take(); err != nil {
+				return nil
+			}
+		}
+		anchor = keep.PrevHash
+		count, err = al.DeleteBeforeID(txRep, keep.ID)
+		return err
+	})
+	auditMutex.Unlock()
+	if trErr != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(trErr.Error())
+		return 0, NewInternalError("Failed to delete", trErr)
+	}
+
+	if count > 0 {
+		a.Record(model.NewAuditLog(model.AuditPurge, model.AuditSuccess, "", "").WithTarget(anchor).
+			WithDetails(fmt.Sprintf("%d records before %s are deleted", count, cutoff.UTC().Format(time.RFC3339))))
+		a.container.GetLogger().GetZapLogger().Infof("%d records of the audit log are purged", count)
+	}
+	return count, nil
+}
+
+// StartPurge purges the records older than the retention periodically in the background,
+// and returns the function to stop it.
+func (a *auditService) StartPurge() func() {
+	interval := time.Duration(positive(a.container.GetConfig().Audit.PurgeInterval, defaultAuditPurgeInterval)) *
+		time.Minute
+	a.container.GetLogger().GetZapLogger().Infof("Started audit log purge, interval %s", interval)
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			_, _ = a.Purge()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/util"
+)
+
+// AuthorityService is a service for managing the authorities and their permissions.
+type AuthorityService interface {
+	FindAllAuthorities() (*[]model.Authority, error)
+	FindAllPermissions() []model.Permission
+	CreateAuthority(dto *dto.AuthorityDto) (*model.Authority, error)
+	UpdatePermissions(dto *dto.AuthorityDto, id string) (*model.Authority, error)
+}
+
+type authorityService struct {
+	container container.Container
+}
+
+// NewAuthorityService is constructor.
+func NewAuthorityService(container container.Container) AuthorityService {
+	return &authorityService{container: container}
+}
+
+// FindAllAuthorities returns the list of all authorities with their permissions.
+// The admin authority has all permissions.
+func (a *authorityService) FindAllAuthorities() (*[]model.Authority, error) {
+	rep := a.container.GetRepository()
+	authority := model.Authority{}
+	result, err := authority.FindAll(rep)
+	if err != nil {
+		a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+
+	ap := model.AuthorityPermission{}
+	for i := range *result {
+		r := &(*result)[i]
+		if r.Name == model.AuthorityAdmin {
+			r.Permissions = model.AllPermissionNames()
+			continue
+		}
+		if r.Permissions, err = ap.FindByAuthorityID(rep, r.ID); err != nil {
+			a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+			return nil, NewInternalError("Failed to fetch data", err)
+		}
+	}
+	return result, nil
+}
+
+// FindAllPermissions returns the list of all permissions which can be assigned to authorities.
+func (a *authorityService) FindAllPermissions() []model.Permission {
+	return model.Permissions
+}
+
+// CreateAuthority registers the given authority and its permissions.
+func (a *authorityService) CreateAuthority(dto *dto.AuthorityDto) (*model.Authority, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	rep := a.container.GetRepository()
+	var result *model.Authority
+	var err error
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = txCreateAuthority(txRep, dto)
+		return err
+	}); trErr != nil {
+		return nil, a.transactionError(trErr, "Failed to the registration")
+	}
+	return result, nil
+}
+
+func txCreateAuthority(txRep repository.Repository, dto *dto.AuthorityDto) (*model.Authority, error) {
+	authority := dto.Create()
+
+	exists, err := authority.Exist(txRep, authority.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, NewConflictError(fmt.Sprintf("Authority %s already exists", authority.Name))
+	}
+
+	if _, err = authority.Create(txRep); err != nil {
+		return nil, err
+	}
+	authority.Permissions = dto.UniquePermissions()
+	ap := model.AuthorityPermission{}
+	if err = ap.ReplaceByAuthorityID(txRep, authority.ID, authority.Permissions); err != nil {
+		return nil, err
+	}
+	return authority, nil
+}
+
+// UpdatePermissions replaces the permissions of the given authority.
+// The admin authority always has all permissions, so its permissions can't be changed.
+func (a *authorityService) UpdatePermissions(dto *dto.AuthorityDto, id string) (*model.Authority, error) {
+	if fields := dto.ValidatePermissions(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+	if !util.IsNumeric(id) {
+		return nil, authorityNotFound(id)
+	}
+
+	rep := a.container.GetRepository()
+	var result *model.Authority
+	var err error
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = txUpdatePermissions(txRep, dto, id)
+		return err
+	}); trErr != nil {
+		return nil, a.transactionError(trErr, "Failed to the update")
+	}
+	return result, nil
+}
+
+func txUpdatePermissions(txRep repository.Repository, dto *dto.AuthorityDto, id string) (*model.Authority, error) {
+	var authority *model.Authority
+	var err error
+
+	au := model.Authority{}
+	if authority, err = au.FindByID(txRep, util.ConvertToUint(id)).Take(); err != nil {
+		return nil, authorityNotFound(id)
+	}
+	if authority.Name == model.AuthorityAdmin {
+		return nil, NewConflictError("The permissions of the administrator can't be changed")
+	}
+
+	authority.Permissions = dto.UniquePermissions()
+	ap := model.AuthorityPermission{}
+	if err = ap.ReplaceByAuthorityID(txRep, authority.ID, authority.Permissions); err != nil {
+		return nil, err
+	}
+	return authority, nil
+}
+
+// transactionError converts the error of the transaction to the error of services. The errors of services
+// are returned as they are, and the others are logged as the failures of the database.
+func (a *authorityService) transactionError(err error, failure string) error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	a.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	return NewInternalError(failure, err)
+}
+
+func authorityNotFound(id string) error {
+	return NewNotFoundError(fmt.Sprintf("Authority %s does not exist", id))
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"github.com/lyh-demo/go-webapp-demo/backup"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"io"
+)
+
+// BackupService is a service for the logical backup and restore of the whole dataset.
+type BackupService interface {
+	Backup(w io.Writer) (*backup.Manifest, error)
+	BackupToBytes() ([]byte, error)
+	Restore(r io.Reader, replace bool) (*backup.Manifest, error)
+}
+
+type backupService struct {
+	container container.Container
+}
+
+// NewBackupService is constructor.
+func NewBackupService(container container.Container) BackupService {
+	return &backupService{container: container}
+}
+
+// Backup writes every table to the given writer as the tar.gz archive.
+func (b *backupService) Backup(w io.Writer) (*backup.Manifest, error) {
+	rep := b.container.GetRepository()
+	manifest, err := backup.Dump(rep, b.container.GetConfig().Database.Dialect, w)
+	if err != nil {
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the backup", err)
+	}
+	return manifest, nil
+}
+
+// BackupToBytes returns the whole archive, so that a failure can be reported before sending it.
+func (b *backupService) BackupToBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := b.Backup(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Restore inserts the rows of the archive into the empty database, or replaces the current data if replace is true.
+// The archives of the first version don't have the permissions, so the default permissions are assigned.
+func (b *backupService) Restore(r io.Reader, replace bool) (*backup.Manifest, error) {
+	rep := b.container.GetRepository()
+	manifest, err := backup.Restore(rep, b.container.GetConfig().Database.Dialect, r, replace)
+	switch {
+	case errors.Is(err, backup.ErrNotEmpty):
+		return nil, NewConflictError("The database isn't empty")
+	case errors.Is(err, backup.ErrInvalidArchive):
+		return nil, NewValidationError("The archive is invalid", map[string]string{"file": err.Error()})
+	case err != nil:
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the restore", err)
+	}
+
+	if manifest.Version < 2 {
+		ap := model.AuthorityPermission{}
+		if err = ap.InitDefaults(rep); err != nil {
+			b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+			return nil, NewInternalError("Failed to the restore", err)
+		}
+	}
+	return manifest, nil
+}
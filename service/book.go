@@ -2,22 +2,44 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/broker"
 	"github.com/lyh-demo/go-webapp-demo/container"
 	"github.com/lyh-demo/go-webapp-demo/model"
 	"github.com/lyh-demo/go-webapp-demo/model/dto"
 	"github.com/lyh-demo/go-webapp-demo/repository"
 	"github.com/lyh-demo/go-webapp-demo/util"
+	"github.com/lyh-demo/go-webapp-demo/webhook"
 )
 
+// errBookNotFound is returned from the transactions when the book of the given id doesn't exist.
+var errBookNotFound = errors.New("the book doesn't exist")
+
+// referenceError is returned from the transactions when the category or the format of the book doesn't exist.
+type referenceError struct {
+	messages map[string]string
+}
+
+// Error returns the error message.
+func (e *referenceError) Error() string {
+	return "the category or the format of the book doesn't exist"
+}
+
 // BookService is a service for managing books.
 type BookService interface {
 	FindByID(id string) (*model.Book, error)
 	FindAllBooks() (*[]model.Book, error)
 	FindAllBooksByPage(page string, size string) (*model.Page, error)
 	FindBooksByTitle(title string, page string, size string) (*model.Page, error)
-	CreateBook(dto *dto.BookDto) (*model.Book, map[string]string)
-	UpdateBook(dto *dto.BookDto, id string) (*model.Book, map[string]string)
-	DeleteBook(id string) (*model.Book, map[string]string)
+	FindBooksByCategoryID(categoryID string, page string, size string) (*model.Page, error)
+	FindBooksByFormatID(formatID string, page string, size string) (*model.Page, error)
+	FindBooks(title string, categoryID string, formatID string, page string, size string) (*model.Page, error)
+	FindEBooks(title string, categoryID string, formatID string, page string, size string) (*model.Page, error)
+	FindBooksByCategoryIDs(categoryIDs []uint, page int, size int) (*[]model.Book, error)
+	FindBooksByFormatIDs(formatIDs []uint, page int, size int) (*[]model.Book, error)
+	CreateBook(dto *dto.BookDto) (*model.Book, error)
+	UpdateBook(dto *dto.BookDto, id string) (*model.Book, error)
+	DeleteBook(id string) (*model.Book, error)
 }
 
 type bookService struct {
@@ -32,7 +54,7 @@ func NewBookService(container container.Container) BookService {
 // FindByID returns one record matched book's id.
 func (b *bookService) FindByID(id string) (*model.Book, error) {
 	if !util.IsNumeric(id) {
-		return nil, errors.New("failed to fetch data")
+		return nil, bookNotFound(id)
 	}
 
 	rep := b.container.GetRepository()
@@ -40,7 +62,7 @@ func (b *bookService) FindByID(id string) (*model.Book, error) {
 	var result *model.Book
 	var err error
 	if result, err = book.FindByID(rep, util.ConvertToUint(id)).Take(); err != nil {
-		return nil, err
+		return nil, bookNotFound(id)
 	}
 	return result, nil
 }
@@ -52,7 +74,7 @@ func (b *bookService) FindAllBooks() (*[]model.Book, error) {
 	result, err := book.FindAll(rep)
 	if err != nil {
 		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
-		return nil, err
+		return nil, NewInternalError("Failed to fetch data", err)
 	}
 	return result, nil
 }
@@ -64,7 +86,7 @@ func (b *bookService) FindAllBooksByPage(page string, size string) (*model.Page,
 	result, err := book.FindAllByPage(rep, page, size)
 	if err != nil {
 		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
-		return nil, err
+		return nil, NewInternalError("Failed to fetch data", err)
 	}
 	return result, nil
 }
@@ -76,15 +98,112 @@ func (b *bookService) FindBooksByTitle(title string, page string, size string) (
 	result, err := book.FindByTitle(rep, title, page, size)
 	if err != nil {
 		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
-		return nil, err
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindBooksByCategoryID returns the page object of books belonging to given category.
+func (b *bookService) FindBooksByCategoryID(categoryID string, page string, size string) (*model.Page, error) {
+	if !util.IsNumeric(categoryID) {
+		return nil, NewValidationError("The request has invalid fields",
+			map[string]string{"categoryId": "The category must be a number"})
+	}
+
+	rep := b.container.GetRepository()
+	book := model.Book{}
+	result, err := book.FindByCategoryID(rep, util.ConvertToUint(categoryID), page, size)
+	if err != nil {
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindBooksByFormatID returns the page object of books published in given format.
+func (b *bookService) FindBooksByFormatID(formatID string, page string, size string) (*model.Page, error) {
+	if !util.IsNumeric(formatID) {
+		return nil, NewValidationError("The request has invalid fields",
+			map[string]string{"formatId": "The format must be a number"})
+	}
+
+	rep := b.container.GetRepository()
+	book := model.Book{}
+	result, err := book.FindByFormatID(rep, util.ConvertToUint(formatID), page, size)
+	if err != nil {
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindBooks returns the page object of books matched all given conditions.
+// The empty strings aren't used as conditions.
+func (b *bookService) FindBooks(title string, categoryID string, formatID string,
+	page string, size string) (*model.Page, error) {
+	book := model.Book{}
+	return b.findByFilter(book.FindByFilter, title, categoryID, formatID, page, size)
+}
+
+// FindEBooks returns the page object of e-books matched all given conditions, which are the books published
+// in the formats having the media type. The empty strings aren't used as conditions.
+func (b *bookService) FindEBooks(title string, categoryID string, formatID string,
+	page string, size string) (*model.Page, error) {
+	book := model.Book{}
+	return b.findByFilter(book.FindEBooksByFilter, title, categoryID, formatID, page, size)
+}
+
+// findByFilter validates the conditions, and returns the page object of books found by given function.
+func (b *bookService) findByFilter(find func(repository.Repository, string, uint, uint, string, string) (*model.Page,
+	error), title string, categoryID string, formatID string, page string, size string) (*model.Page, error) {
+	fields := make(map[string]string)
+	if categoryID != "" && !util.IsNumeric(categoryID) {
+		fields["categoryId"] = "The category must be a number"
+	}
+	if formatID != "" && !util.IsNumeric(formatID) {
+		fields["formatId"] = "The format must be a number"
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	result, err := find(b.container.GetRepository(), title, util.ConvertToUint(categoryID),
+		util.ConvertToUint(formatID), page, size)
+	if err != nil {
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindBooksByCategoryIDs returns a page of books per given category by one query.
+func (b *bookService) FindBooksByCategoryIDs(categoryIDs []uint, page int, size int) (*[]model.Book, error) {
+	rep := b.container.GetRepository()
+	book := model.Book{}
+	result, err := book.FindByCategoryIDs(rep, categoryIDs, page, size)
+	if err != nil {
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindBooksByFormatIDs returns a page of books per given format by one query.
+func (b *bookService) FindBooksByFormatIDs(formatIDs []uint, page int, size int) (*[]model.Book, error) {
+	rep := b.container.GetRepository()
+	book := model.Book{}
+	result, err := book.FindByFormatIDs(rep, formatIDs, page, size)
+	if err != nil {
+		b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
 	}
 	return result, nil
 }
 
 // CreateBook register the given book data.
-func (b *bookService) CreateBook(dto *dto.BookDto) (*model.Book, map[string]string) {
-	if e := dto.Validate(); e != nil {
-		return nil, e
+func (b *bookService) CreateBook(dto *dto.BookDto) (*model.Book, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
 	}
 
 	rep := b.container.GetRepository()
@@ -95,9 +214,9 @@ func (b *bookService) CreateBook(dto *dto.BookDto) (*model.Book, map[string]stri
 		result, err = txCreateBook(txRep, dto)
 		return err
 	}); trErr != nil {
-		b.container.GetLogger().GetZapLogger().Errorf(trErr.Error())
-		return nil, map[string]string{"error": "Failed to the registration"}
+		return nil, b.transactionError(trErr, "", "Failed to the registration")
 	}
+	b.container.GetBroker().Publish(broker.EntityBook, broker.ActionCreated, result)
 	return result, nil
 }
 
@@ -106,27 +225,57 @@ func txCreateBook(txRep repository.Repository, dto *dto.BookDto) (*model.Book, e
 	var err error
 	book := dto.Create()
 
-	category := model.Category{}
-	if book.Category, err = category.FindByID(txRep, dto.CategoryID).Take(); err != nil {
+	if err = txFindReferences(txRep, book, dto); err != nil {
 		return nil, err
 	}
 
-	format := model.Format{}
-	if book.Format, err = format.FindByID(txRep, dto.FormatID).Take(); err != nil {
+	if result, err = book.Create(txRep); err != nil {
 		return nil, err
 	}
 
-	if result, err = book.Create(txRep); err != nil {
+	deleted := model.DeletedBook{}
+	if err = deleted.DeleteByID(txRep, result.ID); err != nil {
+		return nil, err
+	}
+
+	if err = txPublishEvent(txRep, webhook.BookCreated, result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// txFindReferences sets the category and the format of the book, and returns referenceError if they don't exist.
+func txFindReferences(txRep repository.Repository, book *model.Book, dto *dto.BookDto) error {
+	category := model.Category{}
+	categoryExists, err := category.Exist(txRep, dto.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	format := model.Format{}
+	formatExists, err := format.Exist(txRep, dto.FormatID)
+	if err != nil {
+		return err
+	}
+
+	if !categoryExists || !formatExists {
+		return &referenceError{messages: dto.ReferenceErrors(categoryExists, formatExists)}
+	}
+
+	if book.Category, err = category.FindByID(txRep, dto.CategoryID).Take(); err != nil {
+		return err
+	}
+	if book.Format, err = format.FindByID(txRep, dto.FormatID).Take(); err != nil {
+		return err
+	}
+	return nil
+}
+
 // UpdateBook updates the given book data.
-func (b *bookService) UpdateBook(dto *dto.BookDto, id string) (*model.Book, map[string]string) {
-	if e := dto.Validate(); e != nil {
-		return nil, e
+func (b *bookService) UpdateBook(dto *dto.BookDto, id string) (*model.Book, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
 	}
 
 	rep := b.container.GetRepository()
@@ -137,9 +286,9 @@ func (b *bookService) UpdateBook(dto *dto.BookDto, id string) (*model.Book, map[
 		result, err = txUpdateBook(txRep, dto, id)
 		return err
 	}); trErr != nil {
-		b.container.GetLogger().GetZapLogger().Errorf(trErr.Error())
-		return nil, map[string]string{"error": "Failed to the update"}
+		return nil, b.transactionError(trErr, id, "Failed to the update")
 	}
+	b.container.GetBroker().Publish(broker.EntityBook, broker.ActionUpdated, result)
 	return result, nil
 }
 
@@ -149,7 +298,7 @@ func txUpdateBook(txRep repository.Repository, dto *dto.BookDto, id string) (*mo
 
 	b := model.Book{}
 	if book, err = b.FindByID(txRep, util.ConvertToUint(id)).Take(); err != nil {
-		return nil, err
+		return nil, errBookNotFound
 	}
 
 	book.Title = dto.Title
@@ -157,17 +306,15 @@ func txUpdateBook(txRep repository.Repository, dto *dto.BookDto, id string) (*mo
 	book.CategoryID = dto.CategoryID
 	book.FormatID = dto.FormatID
 
-	category := model.Category{}
-	if book.Category, err = category.FindByID(txRep, dto.CategoryID).Take(); err != nil {
+	if err = txFindReferences(txRep, book, dto); err != nil {
 		return nil, err
 	}
 
-	format := model.Format{}
-	if book.Format, err = format.FindByID(txRep, dto.FormatID).Take(); err != nil {
+	if result, err = book.Update(txRep); err != nil {
 		return nil, err
 	}
 
-	if result, err = book.Update(txRep); err != nil {
+	if err = txPublishEvent(txRep, webhook.BookUpdated, result); err != nil {
 		return nil, err
 	}
 
@@ -175,7 +322,7 @@ func txUpdateBook(txRep repository.Repository, dto *dto.BookDto, id string) (*mo
 }
 
 // DeleteBook deletes the given book data.
-func (b *bookService) DeleteBook(id string) (*model.Book, map[string]string) {
+func (b *bookService) DeleteBook(id string) (*model.Book, error) {
 	rep := b.container.GetRepository()
 	var result *model.Book
 	var err error
@@ -184,24 +331,51 @@ func (b *bookService) DeleteBook(id string) (*model.Book, map[string]string) {
 		result, err = txDeleteBook(txRep, id)
 		return err
 	}); trErr != nil {
-		b.container.GetLogger().GetZapLogger().Errorf(trErr.Error())
-		return nil, map[string]string{"error": "Failed to the delete"}
+		return nil, b.transactionError(trErr, id, "Failed to the delete")
 	}
+	b.container.GetBroker().Publish(broker.EntityBook, broker.ActionDeleted, result)
 	return result, nil
 }
 
+// transactionError converts the error of the transaction to the error of services. The errors caused by
+// the request are returned as they are, and the others are logged as the failures of the database.
+func (b *bookService) transactionError(err error, id string, failure string) error {
+	var refErr *referenceError
+	if errors.As(err, &refErr) {
+		return NewInvalidReferenceError("The category or the format doesn't exist", refErr.messages)
+	}
+	if errors.Is(err, errBookNotFound) {
+		return bookNotFound(id)
+	}
+	b.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	return NewInternalError(failure, err)
+}
+
+func bookNotFound(id string) error {
+	return NewNotFoundError(fmt.Sprintf("Book %s does not exist", id))
+}
+
 func txDeleteBook(txRep repository.Repository, id string) (*model.Book, error) {
 	var book, result *model.Book
 	var err error
 
 	b := model.Book{}
 	if book, err = b.FindByID(txRep, util.ConvertToUint(id)).Take(); err != nil {
-		return nil, err
+		return nil, errBookNotFound
 	}
 
 	if result, err = book.Delete(txRep); err != nil {
 		return nil, err
 	}
 
+	deleted := model.NewDeletedBook(book.ID, book.CategoryID)
+	if _, err = deleted.Create(txRep); err != nil {
+		return nil, err
+	}
+
+	if err = txPublishEvent(txRep, webhook.BookDeleted, result); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
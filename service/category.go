@@ -1,13 +1,16 @@
 package service
 
 import (
+	"fmt"
 	"github.com/lyh-demo/go-webapp-demo/container"
 	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/util"
 )
 
 // CategoryService is a service for managing master data such as format and category.
 type CategoryService interface {
 	FindAllCategories() *[]model.Category
+	FindByID(id string) (*model.Category, error)
 }
 
 type categoryService struct {
@@ -30,3 +33,18 @@ func (m *categoryService) FindAllCategories() *[]model.Category {
 	}
 	return result
 }
+
+// FindByID returns one record matched category's id.
+func (m *categoryService) FindByID(id string) (*model.Category, error) {
+	if !util.IsNumeric(id) {
+		return nil, NewNotFoundError(fmt.Sprintf("Category %s does not exist", id))
+	}
+
+	rep := m.container.GetRepository()
+	category := model.Category{}
+	result, err := category.FindByID(rep, util.ConvertToUint(id)).Take()
+	if err != nil {
+		return nil, NewNotFoundError(fmt.Sprintf("Category %s does not exist", id))
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrorKind represents the kind of the errors of services, which tells the clients how to handle them.
+type ErrorKind string
+
+const (
+	// KindValidation means the request has invalid fields.
+	KindValidation ErrorKind = "validation"
+	// KindInvalidReference means the request refers to the entities which don't exist.
+	KindInvalidReference ErrorKind = "invalid-reference"
+	// KindNotFound means the requested entity doesn't exist.
+	KindNotFound ErrorKind = "not-found"
+	// KindConflict means the request conflicts with the current state.
+	KindConflict ErrorKind = "conflict"
+	// KindUnauthorized means the client isn't authenticated.
+	KindUnauthorized ErrorKind = "unauthorized"
+	// KindForbidden means the account doesn't have the authority for the request.
+	KindForbidden ErrorKind = "forbidden"
+	// KindTooManyRequests means the client has to wait before sending the request again.
+	KindTooManyRequests ErrorKind = "too-many-requests"
+	// KindInternal means the failure which isn't caused by the request, such as a database error.
+	KindInternal ErrorKind = "internal"
+)
+
+// Error is the error of services. The detail and the messages of fields are shown to the clients,
+// but the cause is only logged. The retry after tells the clients how long to wait before retrying.
+type Error struct {
+	Kind       ErrorKind
+	Detail     string
+	Fields     map[string]string
+	RetryAfter time.Duration
+	cause      error
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Detail + ": " + e.cause.Error()
+	}
+	return e.Detail
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// NewValidationError returns the error of the request which has invalid fields.
+func NewValidationError(detail string, fields map[string]string) *Error {
+	return &Error{Kind: KindValidation, Detail: detail, Fields: fields}
+}
+
+// NewInvalidReferenceError returns the error of the request which refers to the entities which don't exist.
+func NewInvalidReferenceError(detail string, fields map[string]string) *Error {
+	return &Error{Kind: KindInvalidReference, Detail: detail, Fields: fields}
+}
+
+// NewNotFoundError returns the error of the entity which doesn't exist.
+func NewNotFoundError(detail string) *Error {
+	return &Error{Kind: KindNotFound, Detail: detail}
+}
+
+// NewConflictError returns the error of the request which conflicts with the current state.
+func NewConflictError(detail string) *Error {
+	return &Error{Kind: KindConflict, Detail: detail}
+}
+
+// NewUnauthorizedError returns the error of the client which isn't authenticated.
+func NewUnauthorizedError(detail string) *Error {
+	return &Error{Kind: KindUnauthorized, Detail: detail}
+}
+
+// NewForbiddenError returns the error of the account which doesn't have the authority.
+func NewForbiddenError(detail string) *Error {
+	return &Error{Kind: KindForbidden, Detail: detail}
+}
+
+// NewTooManyRequestsError returns the error of the client which has to wait for given duration.
+func NewTooManyRequestsError(detail string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindTooManyRequests, Detail: detail, RetryAfter: retryAfter}
+}
+
+// NewInternalError returns the error which isn't caused by the request.
+func NewInternalError(detail string, cause error) *Error {
+	return &Error{Kind: KindInternal, Detail: detail, cause: cause}
+}
+
+// AsError returns the error of services, and the other errors are regarded as the internal errors.
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return NewInternalError("Internal Server Error", err)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/broker"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const eventDefaultHeartbeat = 15
+
+// EventService is a service for subscribing the live events of data changes.
+type EventService interface {
+	Subscribe(types string, lastEventID string) (*broker.Subscription, error)
+	Unsubscribe(subscription *broker.Subscription)
+	Heartbeat() time.Duration
+}
+
+type eventService struct {
+	container container.Container
+}
+
+// NewEventService is constructor.
+func NewEventService(container container.Container) EventService {
+	return &eventService{container: container}
+}
+
+// Subscribe registers a subscriber of the comma separated entity types, and all types are subscribed if it is empty.
+// The events after lastEventID are replayed for resuming if it is given.
+func (e *eventService) Subscribe(types string, lastEventID string) (*broker.Subscription, error) {
+	var entities []string
+	for _, entity := range strings.Split(types, ",") {
+		if entity = strings.TrimSpace(entity); entity == "" {
+			continue
+		}
+		if !broker.IsEntity(entity) {
+			return nil, NewValidationError("The request has invalid fields",
+				map[string]string{"types": "Unknown entity type: " + entity})
+		}
+		entities = append(entities, entity)
+	}
+
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			return nil, NewValidationError("The request has invalid fields",
+				map[string]string{"lastEventId": "The last event ID must be a number"})
+		}
+	}
+
+	return e.container.GetBroker().Subscribe(entities, lastID), nil
+}
+
+// Unsubscribe removes the subscriber.
+func (e *eventService) Unsubscribe(subscription *broker.Subscription) {
+	e.container.GetBroker().Unsubscribe(subscription)
+}
+
+// Heartbeat returns the interval of heartbeats which keep the connections alive.
+func (e *eventService) Heartbeat() time.Duration {
+	heartbeat := e.container.GetConfig().Event.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = eventDefaultHeartbeat
+	}
+	return time.Duration(heartbeat) * time.Second
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/feed"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"net/url"
+	"time"
+)
+
+const (
+	feedDefaultSize        = 20
+	feedDefaultSpaBookPath = "/?book=%d"
+	feedHTMLType           = "text/html"
+)
+
+// FeedService is a service for composing the syndication feeds of newly added books.
+type FeedService interface {
+	CreateNewArrivalsAtom(baseURL string, categoryID string, formatID string) (*feed.Feed, error)
+	CreateNewArrivalsRSS(baseURL string, categoryID string, formatID string) (*feed.RSS, error)
+}
+
+type feedService struct {
+	container       container.Container
+	categoryService CategoryService
+	formatService   FormatService
+}
+
+// newArrivals represents the books and the description of the new arrivals feed.
+type newArrivals struct {
+	title  string
+	params map[string]string
+	books  *[]model.Book
+}
+
+// NewFeedService is constructor.
+func NewFeedService(container container.Container) FeedService {
+	return &feedService{
+		container:       container,
+		categoryService: NewCategoryService(container),
+		formatService:   NewFormatService(container),
+	}
+}
+
+// CreateNewArrivalsAtom returns the Atom feed of the most recently added books.
+func (f *feedService) CreateNewArrivalsAtom(baseURL string, categoryID string, formatID string) (*feed.Feed, error) {
+	arrivals, err := f.findNewArrivals(categoryID, formatID)
+	if err != nil {
+		return nil, err
+	}
+
+	selfURL := feedURL(baseURL+config.FeedsNewAtom, arrivals.params)
+	atom := feed.NewFeed(selfURL, arrivals.title, time.Now())
+	atom.Author = &feed.Person{Name: opdsAuthor, URI: baseURL}
+	atom.AddLink(feed.RelSelf, selfURL, feed.AtomType)
+	atom.AddLink(feed.RelAlternate, baseURL+"/", feedHTMLType)
+	if len(*arrivals.books) > 0 {
+		atom.Updated = feed.FormatTime((*arrivals.books)[0].CreatedAt)
+	}
+
+	for _, book := range *arrivals.books {
+		atom.AddEntry(feed.Entry{
+			ID:         fmt.Sprintf("%s%s/%d", baseURL, config.APIBooks, book.ID),
+			Title:      book.Title,
+			Updated:    feed.FormatTime(book.UpdatedAt),
+			Published:  feed.FormatTime(book.CreatedAt),
+			Identifier: "urn:isbn:" + book.Isbn,
+			Categories: []feed.Category{{Term: book.Category.Name, Label: book.Category.Name}},
+			Summary:    &feed.Text{Type: "text", Body: f.describe(&book)},
+			Links: []feed.Link{
+				{Rel: feed.RelAlternate, Href: bookPageURL(f.container, baseURL, book.ID), Type: feedHTMLType},
+				{Rel: feed.RelAlternate, Href: fmt.Sprintf("%s%s/%d", baseURL, config.APIBooks, book.ID), Type: opdsJSONType},
+			},
+		})
+	}
+	return atom, nil
+}
+
+// CreateNewArrivalsRSS returns the RSS feed of the most recently added books.
+func (f *feedService) CreateNewArrivalsRSS(baseURL string, categoryID string, formatID string) (*feed.RSS, error) {
+	arrivals, err := f.findNewArrivals(categoryID, formatID)
+	if err != nil {
+		return nil, err
+	}
+
+	selfURL := feedURL(baseURL+config.FeedsNewRSS, arrivals.params)
+	rss := feed.NewRSS(arrivals.title, baseURL+"/", "Books recently added to the catalog.", selfURL, time.Now())
+	for _, book := range *arrivals.books {
+		rss.AddItem(feed.Item{
+			Title:       book.Title,
+			Link:        bookPageURL(f.container, baseURL, book.ID),
+			Description: f.describe(&book),
+			Category:    book.Category.Name,
+			GUID:        feed.GUID{IsPermaLink: true, Value: fmt.Sprintf("%s%s/%d", baseURL, config.APIBooks, book.ID)},
+			PubDate:     feed.FormatRSSTime(book.CreatedAt),
+		})
+	}
+	return rss, nil
+}
+
+func (f *feedService) findNewArrivals(categoryID string, formatID string) (*newArrivals, error) {
+	arrivals := &newArrivals{title: "New arrivals", params: map[string]string{}}
+	var category, format uint
+
+	if categoryID != "" {
+		c, err := f.categoryService.FindByID(categoryID)
+		if err != nil {
+			return nil, err
+		}
+		category = c.ID
+		arrivals.title += " in " + c.Name
+		arrivals.params["category"] = categoryID
+	}
+	if formatID != "" {
+		fm, err := f.formatService.FindByID(formatID)
+		if err != nil {
+			return nil, err
+		}
+		format = fm.ID
+		arrivals.title += " (" + fm.Name + ")"
+		arrivals.params["format"] = formatID
+	}
+
+	size := f.container.GetConfig().Feed.Size
+	if size <= 0 {
+		size = feedDefaultSize
+	}
+
+	rep := f.container.GetRepository()
+	book := model.Book{}
+	books, err := book.FindNewArrivals(rep, category, format, size)
+	if err != nil {
+		f.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	arrivals.books = books
+	return arrivals, nil
+}
+
+func (f *feedService) describe(book *model.Book) string {
+	return fmt.Sprintf("%s, %s, ISBN %s", book.Category.Name, book.Format.Name, book.Isbn)
+}
+
+// bookPageURL returns the URL of the page which shows the given book in the single page application.
+func bookPageURL(container container.Container, baseURL string, id uint) string {
+	path := container.GetConfig().Feed.SpaBookPath
+	if path == "" {
+		path = feedDefaultSpaBookPath
+	}
+	return baseURL + fmt.Sprintf(path, id)
+}
+
+func feedURL(base string, params map[string]string) string {
+	builder := util.NewRequestBuilder().URL(base)
+	for key, value := range params {
+		builder.RequestParams(key, url.QueryEscape(value))
+	}
+	return builder.Build().GetRequestURL()
+}
@@ -1,13 +1,16 @@
 package service
 
 import (
+	"fmt"
 	"github.com/lyh-demo/go-webapp-demo/container"
 	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/util"
 )
 
 // FormatService is a service for managing master data such as format and category.
 type FormatService interface {
 	FindAllFormats() *[]model.Format
+	FindByID(id string) (*model.Format, error)
 }
 
 type formatService struct {
@@ -30,3 +33,18 @@ func (m *formatService) FindAllFormats() *[]model.Format {
 	}
 	return result
 }
+
+// FindByID returns one record matched format's id.
+func (m *formatService) FindByID(id string) (*model.Format, error) {
+	if !util.IsNumeric(id) {
+		return nil, NewNotFoundError(fmt.Sprintf("Format %s does not exist", id))
+	}
+
+	rep := m.container.GetRepository()
+	format := model.Format{}
+	result, err := format.FindByID(rep, util.ConvertToUint(id)).Take()
+	if err != nil {
+		return nil, NewNotFoundError(fmt.Sprintf("Format %s does not exist", id))
+	}
+	return result, nil
+}
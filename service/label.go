@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/label"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"strings"
+)
+
+// labelMaxBooks is the maximum number of labels printed at once, which is 10 pages of PDF.
+const labelMaxBooks = label.PerSheet * 10
+
+// LabelService is a service for printing the labels of books with the barcode of ISBN and the QR code of the book page.
+type LabelService interface {
+	CreateLabel(baseURL string, id string, format string, symbology string) ([]byte, string, error)
+	CreateSheet(baseURL string, ids string, format string, symbology string, skip string) ([]byte, string, error)
+}
+
+type labelService struct {
+	container   container.Container
+	bookService BookService
+}
+
+// NewLabelService is constructor.
+func NewLabelService(container container.Container) LabelService {
+	return &labelService{container: container, bookService: NewBookService(container)}
+}
+
+// CreateLabel returns the label of the given book as SVG or PNG, and the media type of it.
+func (l *labelService) CreateLabel(baseURL string, id string, format string, symbology string) ([]byte, string, error) {
+	lbl, err := l.newLabel(baseURL, id, symbology)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case "", label.SVG:
+		return lbl.RenderSVG(), label.SVGType, nil
+	case label.PNG:
+		png, err := lbl.RenderPNG()
+		if err != nil {
+			l.container.GetLogger().GetZapLogger().Errorf(err.Error())
+			return nil, "", NewInternalError("Failed to render the label", err)
+		}
+		return png, label.PNGType, nil
+	default:
+		return nil, "", labelFieldError("format", "Unsupported format: "+format)
+	}
+}
+
+// CreateSheet returns the labels of the given comma separated books laid out on A4 sheets as SVG or PDF,
+// and the media type of it. The skip is the number of the used positions on the first sheet.
+func (l *labelService) CreateSheet(baseURL string, ids string, format string, symbology string,
+	skip string) ([]byte, string, error) {
+	if skip == "" {
+		skip = "0"
+	}
+	if !util.IsNumeric(skip) || util.ConvertToInt(skip) >= label.PerSheet {
+		return nil, "", labelFieldError("skip", fmt.Sprintf("The skip must be a number less than %d", label.PerSheet))
+	}
+
+	var labels []*label.Label
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		lbl, err := l.newLabel(baseURL, id, symbology)
+		if err != nil {
+			return nil, "", err
+		}
+		labels = append(labels, lbl)
+	}
+	if len(labels) == 0 {
+		return nil, "", labelFieldError("ids", "The IDs of books are required")
+	}
+	if len(labels) > labelMaxBooks {
+		return nil, "", labelFieldError("ids", fmt.Sprintf("Up to %d labels can be printed at once", labelMaxBooks))
+	}
+
+	var result []byte
+	var contentType string
+	var err error
+	switch format {
+	case "", label.PDF:
+		result, err = label.RenderSheetPDF(labels, util.ConvertToInt(skip))
+		contentType = label.PDFType
+	case label.SVG:
+		if util.ConvertToInt(skip)+len(labels) > label.PerSheet {
+			return nil, "", labelFieldError("ids", fmt.Sprintf("An SVG sheet has up to %d labels", label.PerSheet))
+		}
+		result, err = label.RenderSheetSVG(labels, util.ConvertToInt(skip))
+		contentType = label.SVGType
+	default:
+		return nil, "", labelFieldError("format", "Unsupported format: "+format)
+	}
+	if err != nil {
+		l.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, "", NewInternalError("Failed to render the labels", err)
+	}
+	return result, contentType, nil
+}
+
+func (l *labelService) newLabel(baseURL string, id string, symbology string) (*label.Label, error) {
+	book, err := l.bookService.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	lbl, err := label.NewLabel(book.Title, book.Isbn, bookPageURL(l.container, baseURL, book.ID), symbology)
+	if err != nil {
+		return nil, labelFieldError("symbology", err.Error())
+	}
+	return lbl, nil
+}
+
+func labelFieldError(field string, message string) error {
+	return NewValidationError("The request has invalid fields", map[string]string{field: message})
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"time"
+)
+
+const (
+	defaultMaxFailures   = 5
+	defaultMaxIPFailures = 20
+	defaultLockoutWindow = 15
+	defaultLockDuration  = 15
+	defaultDelayBase     = 1
+	defaultDelayMax      = 30
+)
+
+// LockoutService is a service for the protection of the login against the brute-force attacks.
+// The failed logins are counted by the username and by the remote IP address. Each failure of the username
+// makes the next login wait longer, and the username or the remote IP address is locked out for a while
+// after too many failures.
+type LockoutService interface {
+	Check(username string, remoteIP string) error
+	RecordFailure(username string, remoteIP string)
+	RecordSuccess(username string)
+	FindLockouts() ([]model.LoginAttempt, error)
+	Unlock(dto *dto.UnlockDto) error
+}
+
+type lockoutService struct {
+	container container.Container
+}
+
+// NewLockoutService is constructor.
+func NewLockoutService(container container.Container) LockoutService {
+	return &lockoutService{container: container}
+}
+
+// Check returns the too many requests error if the username or the remote IP address is locked out,
+// or if the username has to wait after the last failure. The login is allowed if the attempts can't be read.
+func (l *lockoutService) Check(username string, remoteIP string) error {
+	if !l.container.GetConfig().Lockout.Enabled {
+		return nil
+	}
+	logger := l.container.GetLogger().GetZapLogger()
+	store := l.container.GetLockoutStore()
+	now := time.Now()
+
+	if remoteIP != "" {
+		ip, err := store.Find(model.LoginAttemptRemoteIP, remoteIP)
+		if err != nil {
+			logger.Errorf(err.Error())
+		} else if ip.IsLocked(now) {
+			logger.Warnf("the login of %s from %s is rejected, the remote IP is locked out until %s",
+				username, remoteIP, ip.LockedUntil.Format(time.RFC3339))
+			return tooManyFailures(ip.LockedUntil.Sub(now))
+		}
+	}
+
+	user, err := store.Find(model.LoginAttemptUsername, username)
+	if err != nil {
+		logger.Errorf(err.Error())
+		return nil
+	}
+	if user.IsLocked(now) {
+		logger.Warnf("the login of %s from %s is rejected, the username is locked out until %s",
+			username, remoteIP, user.LockedUntil.Format(time.RFC3339))
+		return tooManyFailures(user.LockedUntil.Sub(now))
+	}
+	if l.isCounting(user, now) {
+		if wait := user.LastFailedAt.Add(l.delay(user.Failures)).Sub(now); wait > 0 {
+			return NewTooManyRequestsError("The login has failed recently. Please wait before trying again", wait)
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts the failed login of the username from the remote IP address,
+// and locks them out if the failures reach the limits.
+func (l *lockoutService) RecordFailure(username string, remoteIP string) {
+	conf := l.container.GetConfig().Lockout
+	if !conf.Enabled {
+		return
+	}
+	l.fail(model.LoginAttemptUsername, username, positive(conf.MaxFailures, defaultMaxFailures), username, remoteIP)
+	if remoteIP != "" {
+		l.fail(model.LoginAttemptRemoteIP, remoteIP, positive(conf.MaxIPFailures, defaultMaxIPFailures), username,
+			remoteIP)
+	}
+}
+
+// RecordSuccess forgets the failed logins of the username. The failures of the remote IP address are kept,
+// because an attacker could reset them by logging in to the own account.
+func (l *lockoutService) RecordSuccess(username string) {
+	if !l.container.GetConfig().Lockout.Enabled {
+		return
+	}
+	if err := l.container.GetLockoutStore().Delete(model.LoginAttemptUsername, username); err != nil {
+		l.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	}
+}
+
+// FindLockouts returns the usernames and the remote IP addresses which are locked out now.
+func (l *lockoutService) FindLockouts() ([]model.LoginAttempt, error) {
+	attempts, err := l.container.GetLockoutStore().FindLocked(time.Now())
+	if err != nil {
+		l.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return attempts, nil
+}
+
+// Unlock forgets the failed logins of the username and the remote IP address of given dto.
+func (l *lockoutService) Unlock(dto *dto.UnlockDto) error {
+	if fields := dto.Validate(); fields != nil {
+		return NewValidationError("The request has invalid fields", fields)
+	}
+	logger := l.container.GetLogger().GetZapLogger()
+	store := l.container.GetLockoutStore()
+	if dto.UserName != "" {
+		if err := store.Delete(model.LoginAttemptUsername, dto.UserName); err != nil {
+			logger.Errorf(err.Error())
+			return NewInternalError("Failed to delete", err)
+		}
+		logger.Infof("the lockout of the username %s is released", dto.UserName)
+	}
+	if dto.RemoteIP != "" {
+		if err := store.Delete(model.LoginAttemptRemoteIP, dto.RemoteIP); err != nil {
+			logger.Errorf(err.Error())
+			return NewInternalError("Failed to delete", err)
+		}
+		logger.Infof("the lockout of the remote IP %s is released", dto.RemoteIP)
+	}
+	return nil
+}
+
+// fail counts up the failures of the attempts. The failures are counted from zero again if the last failure
+// is older than the window or the lockout has expired.
+func (l *lockoutService) fail(kind string, value string, limit int, username string, remoteIP string) {
+	conf := l.container.GetConfig().Lockout
+	lockDuration := time.Duration(positive(conf.LockDuration, defaultLockDuration)) * time.Minute
+	ttl := time.Duration(positive(conf.Window, defaultLockoutWindow)) * time.Minute
+	if lockDuration > ttl {
+		ttl = lockDuration
+	}
+
+	now := time.Now()
+	locked := false
+	attempt, err := l.container.GetLockoutStore().Update(kind, value, ttl, func(attempt *model.LoginAttempt) {
+		if !l.isCounting(attempt, now) {
+			attempt.Failures = 0
+			attempt.LockedUntil = nil
+		}
+		attempt.Failures++
+		attempt.LastFailedAt = now
+		if attempt.Failures >= limit && !attempt.IsLocked(now) {
+			until := now.Add(lockDuration)
+			attempt.LockedUntil = &until
+			locked = true
+		}
+	})
+	if err != nil {
+		l.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return
+	}
+	if locked {
+		l.container.GetLogger().GetZapLogger().Warnf("the %s %s is locked out until %s after %d failed logins, "+
+			"the last one of %s from %s", kind, value, attempt.LockedUntil.Format(time.RFC3339), attempt.Failures,
+			username, remoteIP)
+	}
+}
+
+// isCounting returns true if the failures of the attempts are still counted at given time.
+func (l *lockoutService) isCounting(attempt *model.LoginAttempt, now time.Time) bool {
+	if attempt.Failures == 0 || (attempt.LockedUntil != nil && !attempt.IsLocked(now)) {
+		return false
+	}
+	window := time.Duration(positive(l.container.GetConfig().Lockout.Window, defaultLockoutWindow)) * time.Minute
+	return attempt.LastFailedAt.Add(window).After(now)
+}
+
+// delay returns the duration to wait after given number of failures, which doubles by each failure.
+func (l *lockoutService) delay(failures int) time.Duration {
+	conf := l.container.GetConfig().Lockout
+	base := time.Duration(positive(conf.DelayBase, defaultDelayBase)) * time.Second
+	limit := time.Duration(positive(conf.DelayMax, defaultDelayMax)) * time.Second
+	delay := base
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		return limit
+	}
+	return delay
+}
+
+func tooManyFailures(retryAfter time.Duration) error {
+	return NewTooManyRequestsError("Too many failed logins. Please try again later", retryAfter)
+}
+
+// positive returns the value if it is positive, and the default value otherwise.
+func positive(value int, defaultValue int) int {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareForLockoutTest() (container.Container, service.LockoutService) {
+	container := test.PrepareForServiceTest()
+	container.GetConfig().Lockout.Enabled = true
+	container.GetConfig().Lockout.MaxFailures = 3
+	container.GetConfig().Lockout.MaxIPFailures = 5
+	return container, service.NewLockoutService(container)
+}
+
+// elapse moves the last failure and the lockout of the attempts to the past by given duration.
+func elapse(container container.Container, kind string, value string, d time.Duration) {
+	_, _ = container.GetLockoutStore().Update(kind, value, time.Hour, func(attempt *model.LoginAttempt) {
+		attempt.LastFailedAt = attempt.LastFailedAt.Add(-d)
+		if attempt.LockedUntil != nil {
+			until := attempt.LockedUntil.Add(-d)
+			attempt.LockedUntil = &until
+		}
+	})
+}
+
+func assertTooManyRequests(t *testing.T, err error) {
+	if assert.Error(t, err) {
+		assert.Equal(t, service.KindTooManyRequests, service.AsError(err).Kind)
+		assert.Greater(t, service.AsError(err).RetryAfter, time.Duration(0))
+	}
+}
+
+func TestLockout_DelayAfterFailure(t *testing.T) {
+	container, lockout := prepareForLockoutTest()
+
+	lockout.RecordFailure("test", "192.0.2.1")
+	assertTooManyRequests(t, lockout.Check("test", "192.0.2.1"))
+	assert.NoError(t, lockout.Check("test2", "192.0.2.1"))
+
+	elapse(container, model.LoginAttemptUsername, "test", time.Second)
+	assert.NoError(t, lockout.Check("test", "192.0.2.1"))
+
+	// the delay doubles by each failure.
+	lockout.RecordFailure("test", "192.0.2.1")
+	elapse(container, model.LoginAttemptUsername, "test", time.Second)
+	assertTooManyRequests(t, lockout.Check("test", "192.0.2.1"))
+	elapse(container, model.LoginAttemptUsername, "test", time.Second)
+	assert.NoError(t, lockout.Check("test", "192.0.2.1"))
+}
+
+func TestLockout_LockUsername(t *testing.T) {
+	container, lockout := prepareForLockoutTest()
+
+	for i := 0; i < 3; i++ {
+		lockout.RecordFailure("test", "192.0.2.1")
+	}
+	elapse(container, model.LoginAttemptUsername, "test", time.Minute)
+	assertTooManyRequests(t, lockout.Check("test", "192.0.2.2"))
+
+	lockouts, err := lockout.FindLockouts()
+	assert.NoError(t, err)
+	if assert.Len(t, lockouts, 1) {
+		assert.Equal(t, "test", lockouts[0].Value)
+	}
+
+	// the failures are counted from zero again after the lockout has expired.
+	elapse(container, model.LoginAttemptUsername, "test", 15*time.Minute)
+	assert.NoError(t, lockout.Check("test", "192.0.2.2"))
+	lockout.RecordFailure("test", "192.0.2.2")
+	attempt, _ := container.GetLockoutStore().Find(model.LoginAttemptUsername, "test")
+	assert.Equal(t, 1, attempt.Failures)
+	assert.Nil(t, attempt.LockedUntil)
+}
+
+func TestLockout_LockRemoteIP(t *testing.T) {
+	_, lockout := prepareForLockoutTest()
+
+	for _, username := range []string{"a", "b", "c", "d", "e"} {
+		lockout.RecordFailure(username, "192.0.2.1")
+	}
+
+	assertTooManyRequests(t, lockout.Check("test", "192.0.2.1"))
+	assert.NoError(t, lockout.Check("test", "192.0.2.2"))
+}
+
+func TestLockout_RecordSuccess(t *testing.T) {
+	container, lockout := prepareForLockoutTest()
+
+	lockout.RecordFailure("test", "192.0.2.1")
+	lockout.RecordSuccess("test")
+
+	assert.NoError(t, lockout.Check("test", "192.0.2.1"))
+	// the failures of the remote IP address are kept.
+	attempt, _ := container.GetLockoutStore().Find(model.LoginAttemptRemoteIP, "192.0.2.1")
+	assert.Equal(t, 1, attempt.Failures)
+}
+
+func TestLockout_Unlock(t *testing.T) {
+	_, lockout := prepareForLockoutTest()
+	for i := 0; i < 5; i++ {
+		lockout.RecordFailure("test", "192.0.2.1")
+	}
+
+	unlock := dto.NewUnlockDto(nil)
+	unlock.UserName = "test"
+	unlock.RemoteIP = "192.0.2.1"
+	assert.NoError(t, lockout.Unlock(unlock))
+
+	assert.NoError(t, lockout.Check("test", "192.0.2.1"))
+	lockouts, _ := lockout.FindLockouts()
+	assert.Empty(t, lockouts)
+}
+
+func TestLockout_Disabled(t *testing.T) {
+	container, lockout := prepareForLockoutTest()
+	container.GetConfig().Lockout.Enabled = false
+
+	for i := 0; i < 5; i++ {
+		lockout.RecordFailure("test", "192.0.2.1")
+	}
+
+	assert.NoError(t, lockout.Check("test", "192.0.2.1"))
+}
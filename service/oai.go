@@ -0,0 +1,472 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/oai"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	oaiDefaultName       = "go-webapp-demo"
+	oaiDefaultIdentifier = "localhost"
+	oaiDefaultPageSize   = 100
+	oaiSetPrefix         = "category:"
+	oaiDeletedRecord     = "persistent"
+)
+
+// oaiArguments represents the arguments allowed for each verb.
+var oaiArguments = map[string][]string{
+	oai.Identify:            {},
+	oai.ListMetadataFormats: {"identifier"},
+	oai.ListSets:            {"resumptionToken"},
+	oai.ListIdentifiers:     {"metadataPrefix", "from", "until", "set", "resumptionToken"},
+	oai.ListRecords:         {"metadataPrefix", "from", "until", "set", "resumptionToken"},
+	oai.GetRecord:           {"identifier", "metadataPrefix"},
+}
+
+// OaiService is a service for providing the metadata of books over OAI-PMH.
+type OaiService interface {
+	HandleRequest(baseURL string, args url.Values) (*oai.Response, error)
+}
+
+type oaiService struct {
+	container container.Container
+}
+
+// NewOaiService is constructor.
+func NewOaiService(container container.Container) OaiService {
+	return &oaiService{container: container}
+}
+
+// oaiItem represents a book or a deleted book which is exposed as a record.
+type oaiItem struct {
+	id         uint
+	categoryID uint
+	datestamp  time.Time
+	book       *model.Book
+}
+
+// HandleRequest processes an OAI-PMH request and returns the response. The errors of the request are
+// returned in the response as OAI-PMH requires, but the failure of the database is returned as the internal error,
+// so that the harvesters don't take it as the fact that no records match.
+func (o *oaiService) HandleRequest(baseURL string, args url.Values) (*oai.Response, error) {
+	res := oai.NewResponse(baseURL+config.OAI, time.Now())
+
+	verb, ok := single(args, "verb")
+	allowed, known := oaiArguments[verb]
+	if !ok || !known {
+		res.AddError(oai.BadVerb, "Illegal or missing OAI-PMH verb.")
+		return res, nil
+	}
+	if !validateArguments(res, args, allowed) {
+		return res, nil
+	}
+
+	res.Request.Verb = verb
+	res.Request.Identifier = args.Get("identifier")
+	res.Request.MetadataPrefix = args.Get("metadataPrefix")
+	res.Request.From = args.Get("from")
+	res.Request.Until = args.Get("until")
+	res.Request.Set = args.Get("set")
+	res.Request.ResumptionToken = args.Get("resumptionToken")
+
+	var err error
+	switch verb {
+	case oai.Identify:
+		o.identify(res)
+	case oai.ListMetadataFormats:
+		o.listMetadataFormats(res, args)
+	case oai.ListSets:
+		err = o.listSets(res, args)
+	case oai.ListIdentifiers:
+		err = o.listRecords(res, args, baseURL, false)
+	case oai.ListRecords:
+		err = o.listRecords(res, args, baseURL, true)
+	case oai.GetRecord:
+		o.getRecord(res, args, baseURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (o *oaiService) identify(res *oai.Response) {
+	conf := o.container.GetConfig().Oai
+	rep := o.container.GetRepository()
+
+	earliest := time.Now()
+	book := model.Book{}
+	if t, err := book.FindEarliest(rep).Take(); err == nil && t.Before(earliest) {
+		earliest = t
+	}
+	deleted := model.DeletedBook{}
+	if t, err := deleted.FindEarliest(rep).Take(); err == nil && t.Before(earliest) {
+		earliest = t
+	}
+
+	name := conf.RepositoryName
+	if name == "" {
+		name = oaiDefaultName
+	}
+	res.Identify = &oai.IdentifyResponse{
+		RepositoryName:    name,
+		BaseURL:           res.Request.BaseURL,
+		ProtocolVersion:   "2.0",
+		AdminEmails:       conf.AdminEmail,
+		EarliestDatestamp: oai.FormatDatestamp(earliest),
+		DeletedRecord:     oaiDeletedRecord,
+		Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+	}
+}
+
+func (o *oaiService) listMetadataFormats(res *oai.Response, args url.Values) {
+	if identifier := args.Get("identifier"); identifier != "" {
+		if _, ok := o.findItem(res, identifier); !ok {
+			return
+		}
+	}
+	res.ListMetadataFormats = &oai.ListMetadataFormatsResponse{
+		MetadataFormats: []oai.MetadataFormat{{
+			MetadataPrefix:    oai.DCPrefix,
+			Schema:            oai.DCSchema,
+			MetadataNamespace: oai.DCNamespace,
+		}},
+	}
+}
+
+func (o *oaiService) listSets(res *oai.Response, args url.Values) error {
+	if args.Get("resumptionToken") != "" {
+		res.AddError(oai.BadResumptionToken, "The list of sets is always complete.")
+		return nil
+	}
+
+	rep := o.container.GetRepository()
+	category := model.Category{}
+	categories, err := category.FindAll(rep)
+	if err != nil {
+		o.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return NewInternalError("Failed to fetch data", err)
+	}
+	if len(*categories) == 0 {
+		res.AddError(oai.NoSetHierarchy, "This repository has no sets.")
+		return nil
+	}
+
+	res.ListSets = &oai.ListSetsResponse{}
+	for _, c := range *categories {
+		res.ListSets.Sets = append(res.ListSets.Sets, oai.Set{SetSpec: setSpec(c.ID), SetName: c.Name})
+	}
+	return nil
+}
+
+func (o *oaiService) getRecord(res *oai.Response, args url.Values, baseURL string) {
+	if args.Get("identifier") == "" || args.Get("metadataPrefix") == "" {
+		res.AddError(oai.BadArgument, "The identifier and the metadataPrefix arguments are required.")
+		return
+	}
+	if args.Get("metadataPrefix") != oai.DCPrefix {
+		res.AddError(oai.CannotDisseminateFormat, "The metadata format is not supported by this repository.")
+		return
+	}
+
+	item, ok := o.findItem(res, args.Get("identifier"))
+	if !ok {
+		return
+	}
+	res.GetRecord = &oai.GetRecordResponse{Record: o.createRecord(item, baseURL, true)}
+}
+
+func (o *oaiService) listRecords(res *oai.Response, args url.Values, baseURL string, withMetadata bool) error {
+	token, ok := o.parseListArguments(res, args)
+	if !ok {
+		return nil
+	}
+
+	cond, ok := o.createCondition(res, token)
+	if !ok {
+		return nil
+	}
+
+	items, total, err := o.findItems(cond)
+	if err != nil {
+		o.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return NewInternalError("Failed to fetch data", err)
+	}
+	if len(items) == 0 {
+		res.AddError(oai.NoRecordsMatch, "No records match the request.")
+		return nil
+	}
+
+	var resumption *oai.ResumptionToken
+	pageSize := o.pageSize()
+	if len(items) > pageSize {
+		items = items[:pageSize]
+		last := items[len(items)-1]
+		next := *token
+		next.AfterTime = last.datestamp.UnixNano()
+		next.AfterID = last.id
+		next.Cursor = token.Cursor + pageSize
+		resumption = &oai.ResumptionToken{CompleteListSize: total, Cursor: token.Cursor, Token: next.Encode()}
+	} else if args.Get("resumptionToken") != "" {
+		resumption = &oai.ResumptionToken{CompleteListSize: total, Cursor: token.Cursor}
+	}
+
+	if withMetadata {
+		res.ListRecords = &oai.ListRecordsResponse{ResumptionToken: resumption}
+		for i := range items {
+			res.ListRecords.Records = append(res.ListRecords.Records, o.createRecord(&items[i], baseURL, true))
+		}
+		return nil
+	}
+	res.ListIdentifiers = &oai.ListIdentifiersResponse{ResumptionToken: resumption}
+	for i := range items {
+		res.ListIdentifiers.Headers = append(res.ListIdentifiers.Headers, o.createRecord(&items[i], baseURL, false).Header)
+	}
+	return nil
+}
+
+// parseListArguments returns the token which represents the state of the list request.
+func (o *oaiService) parseListArguments(res *oai.Response, args url.Values) (*oai.Token, bool) {
+	if value := args.Get("resumptionToken"); value != "" {
+		if len(args) > 2 {
+			res.AddError(oai.BadArgument, "The resumptionToken argument is an exclusive argument.")
+			return nil, false
+		}
+		token, err := oai.DecodeToken(value)
+		if err != nil || token.MetadataPrefix != oai.DCPrefix {
+			res.AddError(oai.BadResumptionToken, "The resumptionToken is invalid.")
+			return nil, false
+		}
+		return token, true
+	}
+
+	prefix := args.Get("metadataPrefix")
+	if prefix == "" {
+		res.AddError(oai.BadArgument, "The metadataPrefix argument is required.")
+		return nil, false
+	}
+	if prefix != oai.DCPrefix {
+		res.AddError(oai.CannotDisseminateFormat, "The metadata format is not supported by this repository.")
+		return nil, false
+	}
+	return &oai.Token{MetadataPrefix: prefix, Set: args.Get("set"),
+		From: args.Get("from"), Until: args.Get("until")}, true
+}
+
+// createCondition converts the given token to the condition to find records.
+func (o *oaiService) createCondition(res *oai.Response, token *oai.Token) (*model.ChangeCondition, bool) {
+	cond := model.NewChangeCondition()
+
+	from, fromLayout, ok := parseDatestamp(token.From)
+	if !ok {
+		res.AddError(oai.BadArgument, "The from argument has an illegal syntax.")
+		return nil, false
+	}
+	until, untilLayout, ok := parseDatestamp(token.Until)
+	if !ok {
+		res.AddError(oai.BadArgument, "The until argument has an illegal syntax.")
+		return nil, false
+	}
+	if from != nil && until != nil {
+		if fromLayout != untilLayout {
+			res.AddError(oai.BadArgument, "The from and until arguments have different granularities.")
+			return nil, false
+		}
+		if from.After(*until) {
+			res.AddError(oai.BadArgument, "The from argument must be less than or equal to the until argument.")
+			return nil, false
+		}
+	}
+	cond.From = from
+	if until != nil {
+		// until is inclusive at the given granularity, so the condition holds the beginning of the next unit.
+		next := until.Add(time.Second)
+		if untilLayout == oai.DayGranularity {
+			next = until.AddDate(0, 0, 1)
+		}
+		cond.Until = &next
+	}
+
+	if token.Set != "" {
+		id := strings.TrimPrefix(token.Set, oaiSetPrefix)
+		if !strings.HasPrefix(token.Set, oaiSetPrefix) || !util.IsNumeric(id) || util.ConvertToUint(id) == 0 {
+			res.AddError(oai.NoRecordsMatch, "The set doesn't exist in this repository.")
+			return nil, false
+		}
+		cond.CategoryID = util.ConvertToUint(id)
+	}
+
+	if token.AfterTime != 0 {
+		after := time.Unix(0, token.AfterTime).UTC()
+		cond.AfterTime = &after
+		cond.AfterID = token.AfterID
+	}
+	cond.Limit = o.pageSize() + 1
+	return cond, true
+}
+
+// findItems returns books and deleted books in order of their datestamps and the size of the complete list.
+func (o *oaiService) findItems(cond *model.ChangeCondition) ([]oaiItem, int64, error) {
+	rep := o.container.GetRepository()
+
+	book := model.Book{}
+	books, err := book.FindChanged(rep, cond)
+	if err != nil {
+		return nil, 0, err
+	}
+	deleted := model.DeletedBook{}
+	deletedBooks, err := deleted.FindChanged(rep, cond)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []oaiItem
+	for i := range *books {
+		b := &(*books)[i]
+		items = append(items, oaiItem{id: b.ID, categoryID: b.CategoryID, datestamp: b.UpdatedAt, book: b})
+	}
+	for _, d := range *deletedBooks {
+		items = append(items, oaiItem{id: d.ID, categoryID: d.CategoryID, datestamp: d.DeletedAt})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].datestamp.Equal(items[j].datestamp) {
+			return items[i].id < items[j].id
+		}
+		return items[i].datestamp.Before(items[j].datestamp)
+	})
+
+	bookCount, err := book.CountChanged(rep, cond)
+	if err != nil {
+		return nil, 0, err
+	}
+	deletedCount, err := deleted.CountChanged(rep, cond)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, bookCount + deletedCount, nil
+}
+
+// findItem returns the book or the deleted book matched given OAI identifier.
+func (o *oaiService) findItem(res *oai.Response, identifier string) (*oaiItem, bool) {
+	prefix := o.identifierPrefix()
+	id := strings.TrimPrefix(identifier, prefix)
+	if !strings.HasPrefix(identifier, prefix) || !util.IsNumeric(id) {
+		res.AddError(oai.IDDoesNotExist, "The identifier is unknown or illegal in this repository.")
+		return nil, false
+	}
+
+	rep := o.container.GetRepository()
+	book := model.Book{}
+	if b, err := book.FindByID(rep, util.ConvertToUint(id)).Take(); err == nil {
+		return &oaiItem{id: b.ID, categoryID: b.CategoryID, datestamp: b.UpdatedAt, book: b}, true
+	}
+	deleted := model.DeletedBook{}
+	if d, err := deleted.FindByID(rep, util.ConvertToUint(id)).Take(); err == nil {
+		return &oaiItem{id: d.ID, categoryID: d.CategoryID, datestamp: d.DeletedAt}, true
+	}
+	res.AddError(oai.IDDoesNotExist, "The identifier is unknown or illegal in this repository.")
+	return nil, false
+}
+
+func (o *oaiService) createRecord(item *oaiItem, baseURL string, withMetadata bool) oai.Record {
+	record := oai.Record{Header: oai.Header{
+		Identifier: o.identifierPrefix() + strconv.Itoa(int(item.id)),
+		Datestamp:  oai.FormatDatestamp(item.datestamp),
+		SetSpecs:   []string{setSpec(item.categoryID)},
+	}}
+	if item.book == nil {
+		record.Header.Status = oai.StatusDeleted
+		return record
+	}
+	if !withMetadata {
+		return record
+	}
+
+	dc := oai.NewDublinCore()
+	dc.Titles = []string{item.book.Title}
+	dc.Types = []string{"Text"}
+	dc.Identifiers = []string{"urn:isbn:" + item.book.Isbn, fmt.Sprintf("%s%s/%d", baseURL, config.APIBooks, item.id)}
+	if item.book.Category != nil {
+		dc.Subjects = []string{item.book.Category.Name}
+	}
+	if item.book.Format != nil {
+		dc.Formats = []string{item.book.Format.Name}
+	}
+	record.Metadata = &oai.Metadata{DC: dc}
+	return record
+}
+
+func (o *oaiService) identifierPrefix() string {
+	identifier := o.container.GetConfig().Oai.RepositoryIdentifier
+	if identifier == "" {
+		identifier = oaiDefaultIdentifier
+	}
+	return "oai:" + identifier + ":book/"
+}
+
+func (o *oaiService) pageSize() int {
+	if size := o.container.GetConfig().Oai.PageSize; size > 0 {
+		return size
+	}
+	return oaiDefaultPageSize
+}
+
+// validateArguments checks that the request has only the allowed arguments and none of them are repeated.
+func validateArguments(res *oai.Response, args url.Values, allowed []string) bool {
+	for key, values := range args {
+		if key == "verb" {
+			continue
+		}
+		if len(values) > 1 {
+			res.AddError(oai.BadArgument, fmt.Sprintf("The %s argument is repeated.", key))
+			return false
+		}
+		if !contains(allowed, key) {
+			res.AddError(oai.BadArgument, fmt.Sprintf("The %s argument is illegal for this verb.", key))
+			return false
+		}
+	}
+	return true
+}
+
+func parseDatestamp(value string) (*time.Time, string, bool) {
+	if value == "" {
+		return nil, "", true
+	}
+	for _, layout := range []string{oai.DayGranularity, oai.SecondGranularity} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, layout, true
+		}
+	}
+	return nil, "", false
+}
+
+func setSpec(categoryID uint) string {
+	return oaiSetPrefix + strconv.Itoa(int(categoryID))
+}
+
+func single(args url.Values, key string) (string, bool) {
+	values, ok := args[key]
+	if !ok || len(values) != 1 {
+		return "", false
+	}
+	return values[0], true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/lyh-demo/go-webapp-demo/oai"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOaiListRecords_NoRecordsMatch(t *testing.T) {
+	container := test.PrepareForServiceTest()
+
+	res, err := service.NewOaiService(container).HandleRequest(baseURL,
+		url.Values{"verb": {oai.ListRecords}, "metadataPrefix": {oai.DCPrefix}})
+
+	assert.NoError(t, err)
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, oai.NoRecordsMatch, res.Errors[0].Code)
+	}
+}
+
+func TestOaiListRecords_DatabaseError(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	container.GetRepository().Exec("drop table book")
+
+	for _, verb := range []string{oai.ListRecords, oai.ListIdentifiers} {
+		res, err := service.NewOaiService(container).HandleRequest(baseURL,
+			url.Values{"verb": {verb}, "metadataPrefix": {oai.DCPrefix}})
+
+		assert.Nil(t, res, verb)
+		assert.Equal(t, service.KindInternal, service.AsError(err).Kind, verb)
+	}
+}
+
+func TestOaiListSets_DatabaseError(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	container.GetRepository().Exec("drop table category_master")
+
+	res, err := service.NewOaiService(container).HandleRequest(baseURL, url.Values{"verb": {oai.ListSets}})
+
+	assert.Nil(t, res)
+	assert.Equal(t, service.KindInternal, service.AsError(err).Kind)
+}
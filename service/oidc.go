@@ -0,0 +1,271 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/oidc"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"strings"
+)
+
+// maxNameSuffix is the max number appended to the name of the provisioned account to make it unique.
+const maxNameSuffix = 100
+
+// OIDCService is a service for the login through an external OpenID Connect provider.
+type OIDCService interface {
+	BeginLogin(linkAccount *model.Account) (*oidc.AuthRequest, error)
+	CompleteLogin(dto *dto.OIDCCallbackDto, request *oidc.AuthRequest) (*model.Account, error)
+}
+
+type oidcService struct {
+	container container.Container
+	provider  oidc.Provider
+}
+
+// NewOIDCService is constructor.
+func NewOIDCService(container container.Container) OIDCService {
+	return &oidcService{container: container, provider: oidc.NewProvider(container.GetConfig())}
+}
+
+// BeginLogin returns a new authorization request of the authorization code flow with PKCE.
+// The request must be kept until the callback to validate it. If the link account is given,
+// the identity of the callback is linked to it instead of provisioning a new account.
+func (o *oidcService) BeginLogin(linkAccount *model.Account) (*oidc.AuthRequest, error) {
+	request, err := o.provider.NewAuthRequest()
+	if err != nil {
+		o.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to connect to the identity provider", err)
+	}
+	if linkAccount != nil {
+		request.LinkAccountID = linkAccount.ID
+	}
+	return request, nil
+}
+
+// CompleteLogin validates the callback of the given authorization request, and exchanges the code for
+// the ID token. The account of the identity is provisioned just in time unless the request links the identity
+// to the logged-in account. The email never links the identity to an existing account, because the emails of
+// the accounts aren't verified. The authorities of the provisioned accounts are mapped from the groups
+// of the ID token if the token has the groups claim, but the ones of the linked accounts are left as they are.
+func (o *oidcService) CompleteLogin(dto *dto.OIDCCallbackDto, request *oidc.AuthRequest) (*model.Account, error) {
+	if dto.Error != "" {
+		return nil, NewUnauthorizedError(fmt.Sprintf("The identity provider denied the login, %s", dto.Error))
+	}
+	if request == nil || dto.State == "" || dto.Code == "" ||
+		subtle.ConstantTimeCompare([]byte(dto.State), []byte(request.State)) != 1 {
+		return nil, NewUnauthorizedError("The state of the login is invalid or has expired")
+	}
+
+	claims, err := o.provider.Exchange(dto.Code, request)
+	if errors.Is(err, oidc.ErrInvalidIDToken) {
+		return nil, NewUnauthorizedError("The ID token is invalid")
+	}
+	if err != nil {
+		o.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to connect to the identity provider", err)
+	}
+
+	rep := o.container.GetRepository()
+	var account *model.Account
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		account, err = o.txProvision(txRep, claims, request.LinkAccountID)
+		return err
+	}); trErr != nil {
+		return nil, o.transactionError(trErr, "Failed to the login")
+	}
+
+	ac := model.Account{}
+	result, err := ac.FindByID(rep, account.ID).Take()
+	if err != nil {
+		return nil, accountNotFound(fmt.Sprint(account.ID))
+	}
+	return result, nil
+}
+
+// txProvision returns the account linked to the identity of the claims. If the identity isn't linked yet,
+// it is linked to the account of the link account ID, or a new account is created for it.
+func (o *oidcService) txProvision(txRep repository.Repository, claims *oidc.Claims,
+	linkAccountID uint) (*model.Account, error) {
+	ac := model.Account{}
+	ai := model.AccountIdentity{}
+	var account *model.Account
+	var err error
+
+	identity, idErr := ai.FindByIssuerAndSubject(txRep, claims.Issuer, claims.Subject).Take()
+	switch {
+	case idErr == nil && linkAccountID != 0 && identity.AccountID != linkAccountID:
+		return nil, NewConflictError("The identity is linked to another account")
+	case idErr == nil:
+		if account, err = ac.FindByID(txRep, identity.AccountID).Take(); err != nil {
+			return nil, accountNotFound(fmt.Sprint(identity.AccountID))
+		}
+	case linkAccountID != 0:
+		if account, err = ac.FindByID(txRep, linkAccountID).Take(); err != nil {
+			return nil, accountNotFound(fmt.Sprint(linkAccountID))
+		}
+		identity = model.NewAccountIdentity(account.ID, claims.Issuer, claims.Subject, false)
+		if _, err = identity.Create(txRep); err != nil {
+			return nil, err
+		}
+	default:
+		if account, err = o.txCreateAccount(txRep, claims); err != nil {
+			return nil, err
+		}
+		identity = model.NewAccountIdentity(account.ID, claims.Issuer, claims.Subject, true)
+		if _, err = identity.Create(txRep); err != nil {
+			return nil, err
+		}
+		if err = o.txMapAuthorities(txRep, account, claims.Groups); err != nil {
+			return nil, err
+		}
+		return account, nil
+	}
+
+	if account.Disabled {
+		return nil, NewUnauthorizedError("The account is disabled")
+	}
+	if identity.Provisioned && claims.Groups != nil {
+		if err = o.txMapAuthorities(txRep, account, claims.Groups); err != nil {
+			return nil, err
+		}
+	}
+	return account, nil
+}
+
+// txCreateAccount creates the account of the identity. The account has a random password, so it can login
+// only through the identity provider until the password is reset.
+func (o *oidcService) txCreateAccount(txRep repository.Repository, claims *oidc.Claims) (*model.Account, error) {
+	name, err := txUniqueName(txRep, claims)
+	if err != nil {
+		return nil, err
+	}
+	password, err := newRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	authorities, err := o.txFindAuthorities(txRep, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	account := model.NewAccountWithPlainPassword(name, password, authorities[0].ID)
+	if claims.Email != "" && claims.EmailVerified {
+		exists, err := account.ExistEmail(txRep, claims.Email, 0)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			account.Email = claims.Email
+		}
+	}
+	if _, err = account.Create(txRep); err != nil {
+		return nil, err
+	}
+	account.Authority = &authorities[0]
+	account.Authorities = authorities
+	return account, nil
+}
+
+// txMapAuthorities replaces the authorities of the account with the ones mapped from the groups.
+// The last enabled administrator can't lose the admin authority.
+func (o *oidcService) txMapAuthorities(txRep repository.Repository, account *model.Account, groups []string) error {
+	authorities, err := o.txFindAuthorities(txRep, groups)
+	if err != nil {
+		return err
+	}
+	wasAdmin := isEnabledAdmin(account)
+	account.AuthorityID = authorities[0].ID
+	account.Authority = &authorities[0]
+	account.Authorities = authorities
+	if wasAdmin && !isEnabledAdmin(account) {
+		if err = txCheckLastAdmin(txRep, "The last administrator can't lose the admin authority"); err != nil {
+			return err
+		}
+	}
+
+	if _, err = account.Update(txRep); err != nil {
+		return err
+	}
+	aa := model.AccountAuthority{}
+	return aa.ReplaceByAccountID(txRep, account.ID, account.AdditionalAuthorityIDs())
+}
+
+// txFindAuthorities returns the authorities mapped from the groups in order of the mappings,
+// or the default authority if no groups are mapped.
+func (o *oidcService) txFindAuthorities(txRep repository.Repository, groups []string) ([]model.Authority, error) {
+	authority := model.Authority{}
+	all, err := authority.FindAll(txRep)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]model.Authority)
+	for _, a := range *all {
+		byName[a.Name] = a
+	}
+
+	conf := o.container.GetConfig().OIDC
+	var result []model.Authority
+	seen := make(map[uint]bool)
+	for _, mapping := range conf.AuthorityMappings {
+		a, ok := byName[mapping.Authority]
+		if ok && !seen[a.ID] && contains(groups, mapping.Group) {
+			seen[a.ID] = true
+			result = append(result, a)
+		}
+	}
+	if len(result) == 0 {
+		name := conf.DefaultAuthority
+		if name == "" {
+			name = model.AuthorityUser
+		}
+		a, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("the default authority %s doesn't exist", name)
+		}
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// txUniqueName returns the name of the account from the claims, and a number is appended if it is used.
+func txUniqueName(txRep repository.Repository, claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" && claims.Email != "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if base == "" {
+		base = claims.Name
+	}
+	if base == "" {
+		base = claims.Subject
+	}
+
+	account := model.Account{}
+	name := base
+	for i := 2; i <= maxNameSuffix; i++ {
+		exists, err := account.Exist(txRep, name, 0)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return name, nil
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", NewConflictError(fmt.Sprintf("Account %s already exists", base))
+}
+
+// transactionError converts the error of the transaction to the error of services. The errors of services
+// are returned as they are, and the others are logged as the failures of the database.
+func (o *oidcService) transactionError(err error, failure string) error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	o.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	return NewInternalError(failure, err)
+}
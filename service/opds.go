@@ -0,0 +1,268 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/feed"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	opdsTitle              = "go-webapp-demo catalog"
+	opdsAuthor             = "go-webapp-demo"
+	opdsJSONType           = "application/json"
+	opdsSearchQuery        = "q"
+	defaultOpdsMaxPageSize = 100
+	defaultAcquisitionPath = "/files/books/%d"
+)
+
+// OpdsService is a service for composing the feeds of the OPDS catalog.
+// The catalog has only the e-books, which are the books published in the formats having the media type,
+// and each of them has the acquisition link to its file.
+type OpdsService interface {
+	CreateRootFeed(baseURL string) *feed.Feed
+	CreateCategoryNavigationFeed(baseURL string) (*feed.Feed, error)
+	CreateFormatNavigationFeed(baseURL string) (*feed.Feed, error)
+	CreateAllBooksFeed(baseURL string, page string, size string) (*feed.Feed, error)
+	CreateCategoryBooksFeed(baseURL string, id string, page string, size string) (*feed.Feed, error)
+	CreateFormatBooksFeed(baseURL string, id string, page string, size string) (*feed.Feed, error)
+	CreateSearchFeed(baseURL string, query string, page string, size string) (*feed.Feed, error)
+	CreateOpenSearchDescription(baseURL string) *feed.OpenSearchDescription
+}
+
+type opdsService struct {
+	container       container.Container
+	bookService     BookService
+	categoryService CategoryService
+	formatService   FormatService
+}
+
+// NewOpdsService is constructor.
+func NewOpdsService(container container.Container) OpdsService {
+	return &opdsService{
+		container:       container,
+		bookService:     NewBookService(container),
+		categoryService: NewCategoryService(container),
+		formatService:   NewFormatService(container),
+	}
+}
+
+// CreateRootFeed returns the navigation feed of the catalog root.
+func (o *opdsService) CreateRootFeed(baseURL string) *feed.Feed {
+	f := o.newFeed(baseURL, baseURL+config.OPDS, opdsTitle, feed.NavigationType)
+	f.AddEntry(navigationEntry(baseURL, config.OPDSBooks, "All books",
+		"Browse all books of the catalog.", feed.AcquisitionType))
+	f.AddEntry(navigationEntry(baseURL, config.OPDSCategories, "By category",
+		"Browse books by category.", feed.NavigationType))
+	f.AddEntry(navigationEntry(baseURL, config.OPDSFormats, "By format",
+		"Browse books by format.", feed.NavigationType))
+	return f
+}
+
+// CreateCategoryNavigationFeed returns the navigation feed which has an entry per category.
+func (o *opdsService) CreateCategoryNavigationFeed(baseURL string) (*feed.Feed, error) {
+	categories := o.categoryService.FindAllCategories()
+	if categories == nil {
+		return nil, NewInternalError("Failed to fetch data", nil)
+	}
+
+	f := o.newFeed(baseURL, baseURL+config.OPDSCategories, "By category", feed.NavigationType)
+	f.AddLink(feed.RelUp, baseURL+config.OPDS, feed.NavigationType)
+	for _, category := range *categories {
+		path := config.OPDSCategories + "/" + strconv.Itoa(int(category.ID))
+		f.AddEntry(navigationEntry(baseURL, path, category.Name,
+			fmt.Sprintf("Books in the category \"%s\".", category.Name), feed.AcquisitionType))
+	}
+	return f, nil
+}
+
+// CreateFormatNavigationFeed returns the navigation feed which has an entry per format.
+func (o *opdsService) CreateFormatNavigationFeed(baseURL string) (*feed.Feed, error) {
+	formats := o.formatService.FindAllFormats()
+	if formats == nil {
+		return nil, NewInternalError("Failed to fetch data", nil)
+	}
+
+	f := o.newFeed(baseURL, baseURL+config.OPDSFormats, "By format", feed.NavigationType)
+	f.AddLink(feed.RelUp, baseURL+config.OPDS, feed.NavigationType)
+	for _, format := range *formats {
+		if !format.IsEBook() {
+			continue
+		}
+		path := config.OPDSFormats + "/" + strconv.Itoa(int(format.ID))
+		f.AddEntry(navigationEntry(baseURL, path, format.Name,
+			fmt.Sprintf("Books in the format \"%s\".", format.Name), feed.AcquisitionType))
+	}
+	return f, nil
+}
+
+// CreateAllBooksFeed returns the acquisition feed of all e-books.
+func (o *opdsService) CreateAllBooksFeed(baseURL string, page string, size string) (*feed.Feed, error) {
+	p, s := o.normalizePaging(page, size)
+	result, err := o.bookService.FindEBooks("", "", "", strconv.Itoa(p), strconv.Itoa(s))
+	if err != nil {
+		return nil, err
+	}
+	return o.newAcquisitionFeed(baseURL, config.OPDSBooks, "All books", nil, p, s, result), nil
+}
+
+// CreateCategoryBooksFeed returns the acquisition feed of e-books belonging to given category.
+func (o *opdsService) CreateCategoryBooksFeed(baseURL string, id string, page string, size string) (*feed.Feed, error) {
+	category, err := o.categoryService.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	p, s := o.normalizePaging(page, size)
+	result, err := o.bookService.FindEBooks("", id, "", strconv.Itoa(p), strconv.Itoa(s))
+	if err != nil {
+		return nil, err
+	}
+
+	f := o.newAcquisitionFeed(baseURL, config.OPDSCategories+"/"+id, category.Name, nil, p, s, result)
+	f.AddLink(feed.RelUp, baseURL+config.OPDSCategories, feed.NavigationType)
+	return f, nil
+}
+
+// CreateFormatBooksFeed returns the acquisition feed of books published in given e-book format.
+// The formats other than e-books aren't found in the catalog.
+func (o *opdsService) CreateFormatBooksFeed(baseURL string, id string, page string, size string) (*feed.Feed, error) {
+	format, err := o.formatService.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !format.IsEBook() {
+		return nil, NewNotFoundError(fmt.Sprintf("Format %s isn't an e-book format", id))
+	}
+
+	p, s := o.normalizePaging(page, size)
+	result, err := o.bookService.FindEBooks("", "", id, strconv.Itoa(p), strconv.Itoa(s))
+	if err != nil {
+		return nil, err
+	}
+
+	f := o.newAcquisitionFeed(baseURL, config.OPDSFormats+"/"+id, format.Name, nil, p, s, result)
+	f.AddLink(feed.RelUp, baseURL+config.OPDSFormats, feed.NavigationType)
+	return f, nil
+}
+
+// CreateSearchFeed returns the acquisition feed of e-books partially matched given title.
+func (o *opdsService) CreateSearchFeed(baseURL string, query string, page string, size string) (*feed.Feed, error) {
+	p, s := o.normalizePaging(page, size)
+	result, err := o.bookService.FindEBooks(query, "", "", strconv.Itoa(p), strconv.Itoa(s))
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{opdsSearchQuery: query}
+	return o.newAcquisitionFeed(baseURL, config.OPDSSearch,
+		fmt.Sprintf("Search results for \"%s\"", query), params, p, s, result), nil
+}
+
+// CreateOpenSearchDescription returns the OpenSearch description wired to the title search.
+func (o *opdsService) CreateOpenSearchDescription(baseURL string) *feed.OpenSearchDescription {
+	template := baseURL + config.OPDSSearch + "?" + opdsSearchQuery + "=" + feed.SearchTermsParam
+	return feed.NewOpenSearchDescription(opdsAuthor, "Search books by title.",
+		feed.OpenSearchURL{Type: feed.AcquisitionType, Template: template})
+}
+
+func (o *opdsService) newFeed(baseURL string, selfURL string, title string, feedType string) *feed.Feed {
+	f := feed.NewFeed(selfURL, title, time.Now())
+	f.Author = &feed.Person{Name: opdsAuthor, URI: baseURL}
+	f.AddLink(feed.RelSelf, selfURL, feedType)
+	f.AddLink(feed.RelStart, baseURL+config.OPDS, feed.NavigationType)
+	f.AddLink(feed.RelSearch, baseURL+config.OPDSOpenSearch, feed.OpenSearchType)
+	return f
+}
+
+func (o *opdsService) newAcquisitionFeed(baseURL string, path string, title string,
+	params map[string]string, page int, size int, result *model.Page) *feed.Feed {
+	f := o.newFeed(baseURL, pageURL(baseURL+path, params, page, size), title, feed.AcquisitionType)
+	f.ItemsPerPage = size
+	f.StartIndex = page*size + 1
+
+	count := 0
+	if result.Content != nil {
+		count = len(*result.Content)
+		for i := range *result.Content {
+			f.AddEntry(o.bookEntry(baseURL, &(*result.Content)[i]))
+		}
+	}
+
+	if page > 0 {
+		f.AddLink(feed.RelFirst, pageURL(baseURL+path, params, 0, size), feed.AcquisitionType)
+		f.AddLink(feed.RelPrevious, pageURL(baseURL+path, params, page-1, size), feed.AcquisitionType)
+	}
+	if count == size {
+		f.AddLink(feed.RelNext, pageURL(baseURL+path, params, page+1, size), feed.AcquisitionType)
+	}
+	return f
+}
+
+func navigationEntry(baseURL string, path string, title string, content string, linkType string) feed.Entry {
+	return feed.Entry{
+		ID:      baseURL + path,
+		Title:   title,
+		Updated: feed.FormatTime(time.Now()),
+		Content: &feed.Text{Type: "text", Body: content},
+		Links:   []feed.Link{{Rel: feed.RelSubsection, Href: baseURL + path, Type: linkType}},
+	}
+}
+
+// bookEntry returns the entry of the e-book, which has the acquisition link to its file of the media type.
+func (o *opdsService) bookEntry(baseURL string, book *model.Book) feed.Entry {
+	entry := feed.Entry{
+		ID:         fmt.Sprintf("%s%s/%d", baseURL, config.APIBooks, book.ID),
+		Title:      book.Title,
+		Updated:    feed.FormatTime(book.UpdatedAt),
+		Published:  feed.FormatTime(book.CreatedAt),
+		Identifier: "urn:isbn:" + book.Isbn,
+		Links: []feed.Link{{Rel: feed.RelAlternate, Type: opdsJSONType,
+			Href: fmt.Sprintf("%s%s/%d", baseURL, config.APIBooks, book.ID)}},
+	}
+	if book.Category != nil {
+		entry.Categories = append(entry.Categories, feed.Category{Term: book.Category.Name, Label: book.Category.Name})
+	}
+	if book.Format != nil {
+		entry.Summary = &feed.Text{Type: "text", Body: book.Format.Name}
+		if book.Format.IsEBook() {
+			path := o.container.GetConfig().Opds.AcquisitionPath
+			if path == "" {
+				path = defaultAcquisitionPath
+			}
+			entry.Links = append(entry.Links, feed.Link{Rel: feed.RelAcquisition, Type: book.Format.MediaType,
+				Href: baseURL + fmt.Sprintf(path, book.ID)})
+		}
+	}
+	return entry
+}
+
+func pageURL(base string, params map[string]string, page int, size int) string {
+	builder := util.NewRequestBuilder().URL(base)
+	for key, value := range params {
+		builder.RequestParams(key, url.QueryEscape(value))
+	}
+	builder.RequestParams("page", strconv.Itoa(page))
+	builder.RequestParams("size", strconv.Itoa(size))
+	return builder.Build().GetRequestURL()
+}
+
+// normalizePaging returns the page and the size, which is limited to the max page size of the configuration.
+func (o *opdsService) normalizePaging(page string, size string) (int, int) {
+	p, s := 0, config.OPDSPageSize
+	if util.IsNumeric(page) && util.ConvertToInt(page) > 0 {
+		p = util.ConvertToInt(page)
+	}
+	if util.IsNumeric(size) && util.ConvertToInt(size) > 0 {
+		s = util.ConvertToInt(size)
+	}
+	if limit := positive(o.container.GetConfig().Opds.MaxPageSize, defaultOpdsMaxPageSize); s > limit {
+		s = limit
+	}
+	return p, s
+}
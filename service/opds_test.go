@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/feed"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	baseURL     = "http://localhost:8080"
+	paperFormat = 1
+	eBookFormat = 2
+)
+
+// prepareForOpdsTest creates a paper book and an e-book, and returns the e-book.
+func prepareForOpdsTest() (container.Container, service.OpdsService, *model.Book) {
+	container := test.PrepareForServiceTest()
+	rep := container.GetRepository()
+	_, _ = model.NewBook("Paper Book", "9780000000001", 1, paperFormat).Create(rep)
+	eBook, _ := model.NewBook("Electronic Book", "9780000000002", 1, eBookFormat).Create(rep)
+	return container, service.NewOpdsService(container), eBook
+}
+
+func findLink(links []feed.Link, rel string) *feed.Link {
+	for i := range links {
+		if links[i].Rel == rel {
+			return &links[i]
+		}
+	}
+	return nil
+}
+
+func TestOpds_AcquisitionLink(t *testing.T) {
+	_, opds, eBook := prepareForOpdsTest()
+
+	f, err := opds.CreateAllBooksFeed(baseURL, "0", "10")
+
+	assert.NoError(t, err)
+	if assert.Len(t, f.Entries, 1) {
+		assert.Equal(t, "Electronic Book", f.Entries[0].Title)
+		link := findLink(f.Entries[0].Links, feed.RelAcquisition)
+		if assert.NotNil(t, link) {
+			assert.Equal(t, model.MediaTypeEPUB, link.Type)
+			assert.Equal(t, fmt.Sprintf("%s/files/books/%d", baseURL, eBook.ID), link.Href)
+		}
+	}
+}
+
+func TestOpds_OnlyEBooks(t *testing.T) {
+	_, opds, _ := prepareForOpdsTest()
+
+	category, err := opds.CreateCategoryBooksFeed(baseURL, "1", "0", "10")
+	assert.NoError(t, err)
+	assert.Len(t, category.Entries, 1)
+
+	search, err := opds.CreateSearchFeed(baseURL, "Book", "0", "10")
+	assert.NoError(t, err)
+	assert.Len(t, search.Entries, 1)
+
+	formats, err := opds.CreateFormatNavigationFeed(baseURL)
+	assert.NoError(t, err)
+	if assert.Len(t, formats.Entries, 1) {
+		assert.Equal(t, "e-Book", formats.Entries[0].Title)
+	}
+
+	_, err = opds.CreateFormatBooksFeed(baseURL, fmt.Sprint(paperFormat), "0", "10")
+	assert.Equal(t, service.KindNotFound, service.AsError(err).Kind)
+}
+
+func TestOpds_AcquisitionPath(t *testing.T) {
+	container, opds, eBook := prepareForOpdsTest()
+	container.GetConfig().Opds.AcquisitionPath = "/downloads/%d.epub"
+
+	f, _ := opds.CreateFormatBooksFeed(baseURL, fmt.Sprint(eBookFormat), "0", "10")
+
+	if assert.Len(t, f.Entries, 1) {
+		link := findLink(f.Entries[0].Links, feed.RelAcquisition)
+		assert.Equal(t, fmt.Sprintf("%s/downloads/%d.epub", baseURL, eBook.ID), link.Href)
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/notifier"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+const (
+	defaultResetTokenExpiry   = 30
+	defaultResetURL           = "/?reset_token=%s"
+	defaultMaxResetRequests   = 3
+	defaultMaxResetIPRequests = 20
+	defaultResetWindow        = 60
+	randomTokenBytes          = 32
+)
+
+// PasswordService is a service for changing and resetting the passwords of accounts.
+type PasswordService interface {
+	ChangePassword(account *model.Account, dto *dto.PasswordDto) (*model.Account, error)
+	RequestReset(dto *dto.PasswordResetRequestDto, remoteIP string) error
+	ResetPassword(dto *dto.PasswordResetDto) (*model.Account, error)
+}
+
+type passwordService struct {
+	container container.Container
+}
+
+// NewPasswordService is constructor.
+func NewPasswordService(container container.Container) PasswordService {
+	return &passwordService{container: container}
+}
+
+// ChangePassword changes the password of the given account after checking the current password.
+// The sessions of the account created before the change are no longer valid.
+func (p *passwordService) ChangePassword(account *model.Account, dto *dto.PasswordDto) (*model.Account, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	rep := p.container.GetRepository()
+	a := model.Account{}
+	current, err := a.FindByID(rep, account.ID).Take()
+	if err != nil {
+		return nil, NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(current.Password), []byte(dto.CurrentPassword)); err != nil {
+		return nil, NewValidationError("The current password is incorrect", dto.CurrentPasswordErrors())
+	}
+
+	result, err := p.updatePassword(rep, current, dto.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RequestReset creates a reset token of the account matched given username or email, and delivers it by the notifier.
+// It succeeds even if the account doesn't exist, so that the clients can't find out the registered accounts.
+// The requests are throttled by the username or the email and by the remote IP address whether the account exists
+// or not, and the token is delivered in the background, so that the response takes as long for any account.
+func (p *passwordService) RequestReset(dto *dto.PasswordResetRequestDto, remoteIP string) error {
+	if fields := dto.Validate(); fields != nil {
+		return NewValidationError("The request has invalid fields", fields)
+	}
+
+	key := dto.UserName
+	if key == "" {
+		key = dto.Email
+	}
+	if err := p.throttle(key, remoteIP); err != nil {
+		return err
+	}
+
+	token, err := newRandomToken()
+	if err != nil {
+		p.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return NewInternalError("Failed to create the token", err)
+	}
+	account := p.findResetAccount(p.container.GetRepository(), dto)
+	if account == nil || account.Disabled {
+		p.container.GetLogger().GetZapLogger().Debugf("the password reset is requested for the unknown account")
+		return nil
+	}
+	go p.deliverReset(account, token)
+	return nil
+}
+
+// throttle counts the reset requests of the username or the email and of the remote IP address in the lockout store,
+// and returns the too many requests error if either exceeds the limit in the window. The requests are allowed
+// if they can't be counted.
+func (p *passwordService) throttle(key string, remoteIP string) error {
+	conf := p.container.GetConfig().PasswordReset
+	window := time.Duration(positive(conf.Window, defaultResetWindow)) * time.Minute
+	if p.countRequest(model.LoginAttemptReset, key, window) > positive(conf.MaxRequests, defaultMaxResetRequests) {
+		p.container.GetLogger().GetZapLogger().Warnf("the password reset of %s from %s is rejected, "+
+			"too many requests for the account", key, remoteIP)
+		return tooManyResetRequests(window)
+	}
+	if remoteIP != "" && p.countRequest(model.LoginAttemptResetIP, remoteIP, window) >
+		positive(conf.MaxIPRequests, defaultMaxResetIPRequests) {
+		p.container.GetLogger().GetZapLogger().Warnf("the password reset of %s from %s is rejected, "+
+			"too many requests from the remote IP", key, remoteIP)
+		return tooManyResetRequests(window)
+	}
+	return nil
+}
+
+// countRequest counts up the reset requests of the attempts, and returns the number of the requests in the window.
+// The requests are counted from zero again if the last request is older than the window.
+func (p *passwordService) countRequest(kind string, value string, window time.Duration) int {
+	now := time.Now()
+	attempt, err := p.container.GetLockoutStore().Update(kind, value, window, func(attempt *model.LoginAttempt) {
+		if attempt.LastFailedAt.Add(window).Before(now) {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailedAt = now
+	})
+	if err != nil {
+		p.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return 0
+	}
+	return attempt.Failures
+}
+
+// deliverReset persists the hash of the reset token and sends the token to the email of the account.
+// The token isn't created for the account without the email, because it can't be delivered to the owner.
+func (p *passwordService) deliverReset(account *model.Account, token string) {
+	logger := p.container.GetLogger()
+	if account.Email == "" {
+		logger.GetZapLogger().Warnf("the password reset of %s isn't sent, the account doesn't have the email",
+			account.Name)
+		return
+	}
+
+	rep := p.container.GetRepository()
+	expiry := p.container.GetConfig().PasswordReset.TokenExpiry
+	if expiry <= 0 {
+		expiry = defaultResetTokenExpiry
+	}
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		t := model.PasswordResetToken{}
+		if err := t.DeleteByAccountID(txRep, account.ID); err != nil {
+			return err
+		}
+		expiresAt := time.Now().Add(time.Duration(expiry) * time.Minute)
+		_, err := model.NewPasswordResetToken(account.ID, hashToken(token), expiresAt).Create(txRep)
+		return err
+	}); trErr != nil {
+		logger.GetZapLogger().Errorf(trErr.Error())
+		return
+	}
+
+	url := p.container.GetConfig().PasswordReset.ResetURL
+	if url == "" {
+		url = defaultResetURL
+	}
+	messages := p.container.GetMessages(account.Locale)
+	message := &notifier.Message{
+		To:      account.Email,
+		Subject: messages["NotificationPasswordResetSubject"],
+		Body:    fmt.Sprintf(messages["NotificationPasswordResetBody"], expiry, account.Name, fmt.Sprintf(url, token)),
+	}
+	if err := p.container.GetNotifier().Notify(message); err != nil {
+		logger.GetZapLogger().Errorf(err.Error())
+	}
+}
+
+// findResetAccount returns the account matched the username, or the email if the username isn't given.
+func (p *passwordService) findResetAccount(rep repository.Repository, dto *dto.PasswordResetRequestDto) *model.Account {
+	a := model.Account{}
+	if dto.UserName != "" {
+		if account, _ := a.FindByName(rep, dto.UserName); account.ID != 0 {
+			return account
+		}
+		return nil
+	}
+	if account, err := a.FindByEmail(rep, dto.Email).Take(); err == nil {
+		return account
+	}
+	return nil
+}
+
+// ResetPassword sets the new password of the account by using the reset token. The token can be used only once,
+// and all the sessions of the account are no longer valid.
+func (p *passwordService) ResetPassword(dto *dto.PasswordResetDto) (*model.Account, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	rep := p.container.GetRepository()
+	var result *model.Account
+	var err error
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = p.txResetPassword(txRep, dto)
+		return err
+	}); trErr != nil {
+		if svcErr := AsError(trErr); svcErr.Kind != KindInternal {
+			return nil, svcErr
+		}
+		p.container.GetLogger().GetZapLogger().Errorf(trErr.Error())
+		return nil, NewInternalError("Failed to the update", trErr)
+	}
+	return result, nil
+}
+
+func (p *passwordService) txResetPassword(txRep repository.Repository, dto *dto.PasswordResetDto) (*model.Account, error) {
+	now := time.Now()
+	t := model.PasswordResetToken{}
+	token, err := t.FindByTokenHash(txRep, hashToken(dto.Token), now).Take()
+	if err != nil {
+		return nil, NewValidationError("The token is invalid or has expired", dto.TokenErrors())
+	}
+	used, err := token.Use(txRep, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, NewValidationError("The token is invalid or has expired", dto.TokenErrors())
+	}
+
+	a := model.Account{}
+	account, err := a.FindByID(txRep, token.AccountID).Take()
+	if err != nil || account.Disabled {
+		return nil, NewValidationError("The token is invalid or has expired", dto.TokenErrors())
+	}
+	return p.updatePassword(txRep, account, dto.NewPassword)
+}
+
+// updatePassword hashes the new password by using bcrypt and persists it with the new session version.
+// The sessions of the account are revoked, because they were started by the old password.
+func (p *passwordService) updatePassword(rep repository.Repository, account *model.Account,
+	password string) (*model.Account, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), config.PasswordHashCost)
+	if err != nil {
+		return nil, err
+	}
+	account.Password = string(hashed)
+	result, err := account.UpdatePassword(rep)
+	if err != nil {
+		p.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the update", err)
+	}
+	as := model.AccountSession{}
+	if _, err = as.DeleteByAccountID(rep, account.ID); err != nil {
+		p.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the update", err)
+	}
+	return result, nil
+}
+
+func tooManyResetRequests(retryAfter time.Duration) error {
+	return NewTooManyRequestsError("Too many password reset requests. Please try again later", retryAfter)
+}
+
+// newRandomToken returns a random token encoded in URL-safe base64.
+func newRandomToken() (string, error) {
+	b := make([]byte, randomTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash of the token which is persisted instead of the token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareForPasswordTest() (container.Container, service.PasswordService) {
+	container := test.PrepareForServiceTest()
+	container.GetConfig().PasswordReset.MaxRequests = 2
+	container.GetConfig().PasswordReset.MaxIPRequests = 3
+	return container, service.NewPasswordService(container)
+}
+
+func resetRequestDto(container container.Container, username string) *dto.PasswordResetRequestDto {
+	requestDto := dto.NewPasswordResetRequestDto(container.GetMessages(""))
+	requestDto.UserName = username
+	return requestDto
+}
+
+func countResetTokens(container container.Container) int64 {
+	var count int64
+	container.GetRepository().Model(&model.PasswordResetToken{}).Count(&count)
+	return count
+}
+
+func TestRequestReset_Success(t *testing.T) {
+	container, password := prepareForPasswordTest()
+	container.GetRepository().Exec("update account_master set email = ? where name = ?", "test@example.com", "test")
+
+	err := password.RequestReset(resetRequestDto(container, "test"), "192.0.2.1")
+
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return countResetTokens(container) == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestRequestReset_WithoutEmail(t *testing.T) {
+	container, password := prepareForPasswordTest()
+
+	err := password.RequestReset(resetRequestDto(container, "test"), "192.0.2.1")
+
+	assert.NoError(t, err)
+	assert.Never(t, func() bool { return countResetTokens(container) > 0 }, 200*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestRequestReset_ThrottleAccount(t *testing.T) {
+	for _, username := range []string{"test", "unknown"} {
+		container, password := prepareForPasswordTest()
+
+		assert.NoError(t, password.RequestReset(resetRequestDto(container, username), "192.0.2.1"), username)
+		assert.NoError(t, password.RequestReset(resetRequestDto(container, username), "192.0.2.2"), username)
+		assertTooManyRequests(t, password.RequestReset(resetRequestDto(container, username), "192.0.2.3"))
+		assert.NoError(t, password.RequestReset(resetRequestDto(container, "test2"), "192.0.2.3"), username)
+	}
+}
+
+func TestRequestReset_ThrottleRemoteIP(t *testing.T) {
+	container, password := prepareForPasswordTest()
+
+	for _, username := range []string{"test", "test2", "unknown"} {
+		assert.NoError(t, password.RequestReset(resetRequestDto(container, username), "192.0.2.1"), username)
+	}
+	assertTooManyRequests(t, password.RequestReset(resetRequestDto(container, "other"), "192.0.2.1"))
+	assert.NoError(t, password.RequestReset(resetRequestDto(container, "other"), "192.0.2.2"))
+}
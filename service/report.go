@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"strconv"
+)
+
+// Sections of the summary report in CSV.
+const (
+	reportSectionTotal    = "total"
+	reportSectionCategory = "category"
+	reportSectionFormat   = "format"
+	reportSectionMonth    = "month"
+)
+
+// ReportService is a service for reporting the statistics of the catalog.
+type ReportService interface {
+	CreateSummary() (*model.Summary, error)
+	CreateSummaryCSV() ([]byte, error)
+}
+
+type reportService struct {
+	container container.Container
+}
+
+// NewReportService is constructor.
+func NewReportService(container container.Container) ReportService {
+	return &reportService{container: container}
+}
+
+// CreateSummary returns the number of books grouped by category, format and the month of registration.
+func (r *reportService) CreateSummary() (*model.Summary, error) {
+	rep := r.container.GetRepository()
+	result, err := model.NewSummary().Aggregate(rep, r.container.GetConfig().Database.Dialect)
+	if err != nil {
+		r.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to aggregate data", err)
+	}
+	return result, nil
+}
+
+// CreateSummaryCSV returns the summary as CSV, which has a row per group with the section of the group.
+func (r *reportService) CreateSummaryCSV() ([]byte, error) {
+	summary, err := r.CreateSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{
+		{"section", "id", "name", "count"},
+		{reportSectionTotal, "", "", strconv.FormatInt(summary.TotalBooks, 10)},
+	}
+	for _, c := range summary.ByCategory {
+		records = append(records, []string{reportSectionCategory,
+			strconv.FormatUint(uint64(c.ID), 10), c.Name, strconv.FormatInt(c.Count, 10)})
+	}
+	for _, f := range summary.ByFormat {
+		records = append(records, []string{reportSectionFormat,
+			strconv.FormatUint(uint64(f.ID), 10), f.Name, strconv.FormatInt(f.Count, 10)})
+	}
+	for _, m := range summary.ByMonth {
+		records = append(records, []string{reportSectionMonth, "", m.Month, strconv.FormatInt(m.Count, 10)})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		r.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to write CSV", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"time"
+)
+
+const (
+	sessionTouchInterval = time.Minute
+	sessionIdleExpiry    = 30 * 24 * time.Hour
+)
+
+// SessionService is a service for the login sessions tracked per account. Each login starts a session
+// identified by a random token kept in the session store, and a request is rejected if its session has been
+// revoked, so the revocation takes effect immediately whichever session store is used.
+type SessionService interface {
+	Start(account *model.Account, remoteIP string, userAgent string) (string, error)
+	Validate(account *model.Account, token string, remoteIP string) error
+	End(token string)
+	FindSessions(account *model.Account, token string) ([]model.AccountSession, error)
+	Revoke(account *model.Account, id string, token string) (bool, error)
+	RevokeOthers(account *model.Account, token string) error
+	FindAccountSessions(id string) ([]model.AccountSession, error)
+	ForceLogout(id string) error
+}
+
+type sessionService struct {
+	container container.Container
+}
+
+// NewSessionService is constructor.
+func NewSessionService(container container.Container) SessionService {
+	return &sessionService{container: container}
+}
+
+// Start starts a new session of the account, and returns the token which identifies it.
+// The sessions which have been inactive for a long time are discarded at the same time.
+func (s *sessionService) Start(account *model.Account, remoteIP string, userAgent string) (string, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return "", NewInternalError("Failed to start the session", err)
+	}
+	now := time.Now()
+	rep := s.container.GetRepository()
+	as := model.AccountSession{}
+	if err = as.DeleteInactiveBefore(rep, now.Add(-sessionIdleExpiry)); err != nil {
+		s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	}
+	if _, err = model.NewAccountSession(account.ID, hashToken(token), remoteIP, userAgent, now).Create(rep); err != nil {
+		s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return "", NewInternalError("Failed to start the session", err)
+	}
+	return token, nil
+}
+
+// Validate returns the unauthorized error if the session of the token doesn't belong to the account,
+// or it has been revoked or inactive for a long time. The last activity is recorded at most once a minute.
+func (s *sessionService) Validate(account *model.Account, token string, remoteIP string) error {
+	if token == "" {
+		return sessionRevoked()
+	}
+	now := time.Now()
+	rep := s.container.GetRepository()
+	as := model.AccountSession{}
+	session, err := as.FindByTokenHash(rep, hashToken(token)).Take()
+	if err != nil || session.AccountID != account.ID || session.LastActiveAt.Add(sessionIdleExpiry).Before(now) {
+		return sessionRevoked()
+	}
+	if now.Sub(session.LastActiveAt) >= sessionTouchInterval || session.RemoteIP != remoteIP {
+		if err = session.Touch(rep, now, remoteIP); err != nil {
+			s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		}
+	}
+	return nil
+}
+
+// End ends the session of the token on the logout.
+func (s *sessionService) End(token string) {
+	if token == "" {
+		return
+	}
+	as := model.AccountSession{}
+	if err := as.DeleteByTokenHash(s.container.GetRepository(), hashToken(token)); err != nil {
+		s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	}
+}
+
+// FindSessions returns the sessions of the account, and marks the session of the token as the current one.
+func (s *sessionService) FindSessions(account *model.Account, token string) ([]model.AccountSession, error) {
+	sessions, err := s.findByAccountID(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	tokenHash := hashToken(token)
+	for i := range sessions {
+		sessions[i].Current = token != "" && sessions[i].TokenHash == tokenHash
+	}
+	return sessions, nil
+}
+
+// Revoke revokes the session of given ID which belongs to the account.
+// It returns true if the revoked session is the session of the token.
+func (s *sessionService) Revoke(account *model.Account, id string, token string) (bool, error) {
+	rep := s.container.GetRepository()
+	as := model.AccountSession{}
+	current := false
+	if token != "" {
+		if session, err := as.FindByTokenHash(rep, hashToken(token)).Take(); err == nil {
+			current = session.ID == util.ConvertToUint(id)
+		}
+	}
+	deleted, err := as.DeleteByID(rep, account.ID, util.ConvertToUint(id))
+	if err != nil {
+		s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return false, NewInternalError("Failed to delete", err)
+	}
+	if !deleted {
+		return false, NewNotFoundError(fmt.Sprintf("Session %s does not exist", id))
+	}
+	s.container.GetLogger().GetZapLogger().Infof("the session %s of %s is revoked", id, account.Name)
+	return current, nil
+}
+
+// RevokeOthers revokes the sessions of the account except the session of the token.
+func (s *sessionService) RevokeOthers(account *model.Account, token string) error {
+	rep := s.container.GetRepository()
+	as := model.AccountSession{}
+	session, err := as.FindByTokenHash(rep, hashToken(token)).Take()
+	if token == "" || err != nil || session.AccountID != account.ID {
+		return sessionRevoked()
+	}
+	count, err := as.DeleteOthers(rep, account.ID, session.ID)
+	if err != nil {
+		s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return NewInternalError("Failed to delete", err)
+	}
+	s.container.GetLogger().GetZapLogger().Infof("%d other sessions of %s are revoked", count, account.Name)
+	return nil
+}
+
+// FindAccountSessions returns the sessions of the account of given ID.
+func (s *sessionService) FindAccountSessions(id string) ([]model.AccountSession, error) {
+	account := model.Account{}
+	if _, err := account.FindByID(s.container.GetRepository(), util.ConvertToUint(id)).Take(); err != nil {
+		return nil, accountNotFound(id)
+	}
+	return s.findByAccountID(util.ConvertToUint(id))
+}
+
+// ForceLogout revokes all sessions and refresh tokens of the account of given ID. The session version
+// is incremented as well, so the access tokens already issued are no longer valid.
+func (s *sessionService) ForceLogout(id string) error {
+	var count int64
+	rep := s.container.GetRepository()
+	trErr := rep.Transaction(func(txRep repository.Repository) error {
+		ac := model.Account{}
+		account, err := ac.FindByID(txRep, util.ConvertToUint(id)).Take()
+		if err != nil {
+			return accountNotFound(id)
+		}
+		if _, err = account.UpdateSessionVersion(txRep); err != nil {
+			return err
+		}
+		as := model.AccountSession{}
+		if count, err = as.DeleteByAccountID(txRep, util.ConvertToUint(id)); err != nil {
+			return err
+		}
+		rt := model.RefreshToken{}
+		return rt.RevokeByAccountID(txRep, util.ConvertToUint(id), time.Now())
+	})
+	if trErr != nil {
+		return s.transactionError(trErr, "Failed to delete")
+	}
+	s.container.GetLogger().GetZapLogger().Infof("the account %s is logged out from %d sessions", id, count)
+	return nil
+}
+
+func (s *sessionService) findByAccountID(accountID uint) ([]model.AccountSession, error) {
+	as := model.AccountSession{}
+	sessions, err := as.FindByAccountID(s.container.GetRepository(), accountID)
+	if err != nil {
+		s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return *sessions, nil
+}
+
+func (s *sessionService) transactionError(err error, failure string) error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	s.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	return NewInternalError(failure, err)
+}
+
+func sessionRevoked() error {
+	return NewUnauthorizedError("The session has been revoked or has expired")
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/token"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRefreshTokenExpiry = 10080
+	tokenTypeBearer           = "Bearer"
+)
+
+// TokenService is a service for the access tokens and the refresh tokens of the bearer authentication.
+type TokenService interface {
+	IssueToken(dto *dto.LoginDto, remoteIP string) (*model.TokenResponse, error)
+	RefreshToken(dto *dto.RefreshTokenDto) (*model.TokenResponse, error)
+	RevokeToken(dto *dto.RefreshTokenDto) error
+	Authenticate(accessToken string) (*model.Account, error)
+	FindKeys() *token.JSONWebKeySet
+}
+
+type tokenService struct {
+	container container.Container
+	accounts  AccountService
+	twoFactor TwoFactorService
+}
+
+// NewTokenService is constructor.
+func NewTokenService(container container.Container) TokenService {
+	return &tokenService{container: container, accounts: NewAccountService(container),
+		twoFactor: NewTwoFactorService(container)}
+}
+
+// IssueToken authenticates by the username and password, and issues a new access token and refresh token.
+// The code of the two-factor authentication is also required if the account has enabled it.
+func (t *tokenService) IssueToken(dto *dto.LoginDto, remoteIP string) (*model.TokenResponse, error) {
+	account, err := t.accounts.AuthenticateByUsernameAndPassword(dto.UserName, dto.Password, remoteIP)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.twoFactor.Authenticate(account, dto.Code, remoteIP); err != nil {
+		return nil, err
+	}
+	return t.issueToken(account, time.Now())
+}
+
+// RefreshToken issues a new access token and refresh token by using the refresh token, which is revoked then.
+// If a revoked token is used again, it may have been stolen, so all refresh tokens of the account are revoked.
+func (t *tokenService) RefreshToken(dto *dto.RefreshTokenDto) (*model.TokenResponse, error) {
+	if dto.RefreshToken == "" {
+		return nil, invalidRefreshToken()
+	}
+
+	now := time.Now()
+	rep := t.container.GetRepository()
+	rt := model.RefreshToken{}
+	current, err := rt.FindByTokenHash(rep, hashToken(dto.RefreshToken)).Take()
+	if err != nil {
+		return nil, invalidRefreshToken()
+	}
+	if current.RevokedAt != nil {
+		t.container.GetLogger().GetZapLogger().Warnf("the revoked refresh token of account %d is used",
+			current.AccountID)
+		if err = rt.RevokeByAccountID(rep, current.AccountID, now); err != nil {
+			t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+			return nil, NewInternalError("Failed to revoke the token", err)
+		}
+		return nil, invalidRefreshToken()
+	}
+	if !current.IsActive(now) {
+		return nil, invalidRefreshToken()
+	}
+
+	revoked, err := current.Revoke(rep, now)
+	if err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to revoke the token", err)
+	}
+	if !revoked {
+		return nil, invalidRefreshToken()
+	}
+
+	a := model.Account{}
+	account, err := a.FindByID(rep, current.AccountID).Take()
+	if err != nil || account.Disabled || account.SessionVersion != current.SessionVersion {
+		return nil, invalidRefreshToken()
+	}
+	return t.issueToken(account, now)
+}
+
+// RevokeToken revokes the refresh token. It succeeds even if the token is unknown or has already been revoked.
+func (t *tokenService) RevokeToken(dto *dto.RefreshTokenDto) error {
+	if dto.RefreshToken == "" {
+		return nil
+	}
+
+	rep := t.container.GetRepository()
+	rt := model.RefreshToken{}
+	current, err := rt.FindByTokenHash(rep, hashToken(dto.RefreshToken)).Take()
+	if err != nil {
+		return nil
+	}
+	if _, err = current.Revoke(rep, time.Now()); err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return NewInternalError("Failed to revoke the token", err)
+	}
+	return nil
+}
+
+// Authenticate returns the account of the access token. The account is loaded by the subject of the token,
+// and the token is no longer valid if the account has been deleted or disabled, or its session version has
+// changed by the password change or the forced logout. The permissions are resolved from the current authorities
+// as well as the session, so the changes of the account take effect immediately.
+func (t *tokenService) Authenticate(accessToken string) (*model.Account, error) {
+	claims, err := t.container.GetKeySet().Verify(accessToken)
+	if err != nil {
+		return nil, invalidAccessToken()
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, invalidAccessToken()
+	}
+
+	a := model.Account{}
+	account, err := a.FindByID(t.container.GetRepository(), uint(id)).Take()
+	if err != nil || account.Disabled || account.SessionVersion != claims.SessionVersion {
+		return nil, invalidAccessToken()
+	}
+	if account.Permissions, err = t.accounts.FindPermissions(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// FindKeys returns the public keys to verify the access tokens.
+func (t *tokenService) FindKeys() *token.JSONWebKeySet {
+	return t.container.GetKeySet().JWKS()
+}
+
+// issueToken signs a new access token of the account and persists the hash of a new refresh token.
+func (t *tokenService) issueToken(account *model.Account, now time.Time) (*model.TokenResponse, error) {
+	permissions, err := t.accounts.FindPermissions(account)
+	if err != nil {
+		return nil, err
+	}
+	claims := &token.Claims{Name: account.Name, Permissions: permissions, SessionVersion: account.SessionVersion}
+	claims.Subject = strconv.FormatUint(uint64(account.ID), 10)
+	for _, authority := range account.Authorities {
+		claims.Authorities = append(claims.Authorities, authority.Name)
+	}
+
+	accessToken, expiresAt, refreshToken, err := t.signToken(claims, now)
+	if err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to issue the token", err)
+	}
+	expiry := t.container.GetConfig().JWT.RefreshTokenExpiry
+	if expiry <= 0 {
+		expiry = defaultRefreshTokenExpiry
+	}
+	rt := model.NewRefreshToken(account, hashToken(refreshToken), now.Add(time.Duration(expiry)*time.Minute))
+	if _, err = rt.Create(t.container.GetRepository()); err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to issue the token", err)
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(expiresAt.Sub(now).Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// signToken returns the signed access token of the claims, the time when it expires and a new refresh token.
+func (t *tokenService) signToken(claims *token.Claims, now time.Time) (string, time.Time, string, error) {
+	var err error
+	if claims.Id, err = newRandomToken(); err != nil {
+		return "", time.Time{}, "", err
+	}
+	accessToken, expiresAt, err := t.container.GetKeySet().Sign(claims, now)
+	if err != nil {
+		return "", time.Time{}, "", err
+	}
+	refreshToken, err := newRandomToken()
+	if err != nil {
+		return "", time.Time{}, "", err
+	}
+	return accessToken, expiresAt, refreshToken, nil
+}
+
+func invalidAccessToken() error {
+	return NewUnauthorizedError("The access token is invalid or has expired")
+}
+
+func invalidRefreshToken() error {
+	return NewUnauthorizedError("The refresh token is invalid or has expired")
+}
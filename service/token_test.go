@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate_Success(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	tokens := service.NewTokenService(container)
+
+	response, err := tokens.IssueToken(&dto.LoginDto{UserName: "test", Password: "test"}, "127.0.0.1")
+	assert.NoError(t, err)
+	account, err := tokens.Authenticate(response.AccessToken)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test", account.Name)
+	assert.Equal(t, model.AllPermissionNames(), account.Permissions)
+}
+
+func TestAuthenticate_InvalidAccount(t *testing.T) {
+	cases := map[string]func(container container.Container){
+		"disabled": func(container container.Container) {
+			container.GetRepository().Exec("update account_master set disabled = ? where name = ?", true, "test")
+		},
+		"deleted": func(container container.Container) {
+			container.GetRepository().Exec("delete from account_master where name = ?", "test")
+		},
+		"logged out": func(container container.Container) {
+			assert.NoError(t, service.NewSessionService(container).ForceLogout("1"))
+		},
+		"password changed": func(container container.Container) {
+			a := model.Account{}
+			account, _ := a.FindByID(container.GetRepository(), 1).Take()
+			_, err := account.UpdatePassword(container.GetRepository())
+			assert.NoError(t, err)
+		},
+	}
+	for name, change := range cases {
+		container := test.PrepareForServiceTest()
+		tokens := service.NewTokenService(container)
+
+		response, _ := tokens.IssueToken(&dto.LoginDto{UserName: "test", Password: "test"}, "127.0.0.1")
+		change(container)
+		account, err := tokens.Authenticate(response.AccessToken)
+
+		assert.Nil(t, account, name)
+		assert.Equal(t, service.KindUnauthorized, service.AsError(err).Kind, name)
+	}
+}
+
+func TestAuthenticate_CurrentPermissions(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	tokens := service.NewTokenService(container)
+
+	response, _ := tokens.IssueToken(&dto.LoginDto{UserName: "test", Password: "test"}, "127.0.0.1")
+	container.GetRepository().Exec("update account_master set authority_id = ? where name = ?", 2, "test")
+	account, err := tokens.Authenticate(response.AccessToken)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, account.Permissions, model.PermissionAccountManage)
+	assert.Contains(t, account.Permissions, model.PermissionBookRead)
+}
+
+func TestAuthenticate_InvalidToken(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	tokens := service.NewTokenService(container)
+
+	account, err := tokens.Authenticate("invalid token")
+
+	assert.Nil(t, account)
+	assert.Equal(t, service.KindUnauthorized, service.AsError(err).Kind)
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/totp"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTwoFactorIssuer  = "go-webapp-demo"
+	defaultTwoFactorSkew    = 1
+	defaultRecoveryCodes    = 10
+	defaultPendingExpiry    = 5
+	maxTwoFactorAttempts    = 5
+	recoveryCodeBytes       = 6
+	recoveryCodeGroupLength = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorService is a service for the two-factor authentication by the TOTP codes and the recovery codes.
+type TwoFactorService interface {
+	FindStatus(account *model.Account) (*model.TwoFactorStatus, error)
+	IsRequired(account *model.Account) bool
+	BeginLogin(account *model.Account) (*model.PendingLogin, error)
+	FindPendingAccount(pending *model.PendingLogin) (*model.Account, error)
+	CountFailure(pending *model.PendingLogin, err error) bool
+	CompleteLogin(pending *model.PendingLogin, dto *dto.TwoFactorCodeDto, remoteIP string) (*model.Account, error)
+	Authenticate(account *model.Account, code string, remoteIP string) error
+	Setup(account *model.Account) (*model.TwoFactorEnrollment, error)
+	Confirm(account *model.Account, dto *dto.TwoFactorCodeDto) (*model.RecoveryCodes, error)
+	RegenerateRecoveryCodes(account *model.Account, dto *dto.TwoFactorCodeDto) (*model.RecoveryCodes, error)
+	Disable(account *model.Account, dto *dto.TwoFactorDisableDto) error
+}
+
+type twoFactorService struct {
+	container container.Container
+	lockout   LockoutService
+}
+
+// NewTwoFactorService is constructor.
+func NewTwoFactorService(container container.Container) TwoFactorService {
+	return &twoFactorService{container: container, lockout: NewLockoutService(container)}
+}
+
+// FindStatus returns whether the two-factor authentication of the account is enabled or required,
+// and the number of the recovery codes which haven't been used yet.
+func (t *twoFactorService) FindStatus(account *model.Account) (*model.TwoFactorStatus, error) {
+	rep := t.container.GetRepository()
+	enabled, err := t.isEnabled(rep, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	rc := model.RecoveryCode{}
+	remaining, err := rc.CountUnused(rep, account.ID)
+	if err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return &model.TwoFactorStatus{Enabled: enabled, Required: t.IsRequired(account),
+		RecoveryCodesRemaining: int(remaining)}, nil
+}
+
+// IsRequired returns true if the account must enable the two-factor authentication to login,
+// which is the case of the administrators if it is configured.
+func (t *twoFactorService) IsRequired(account *model.Account) bool {
+	return t.container.GetConfig().TwoFactor.RequiredForAdmin && account.HasAuthority(model.AuthorityAdmin)
+}
+
+// BeginLogin returns the pending login of the account which has passed the password, if the account has to
+// enter the code or to enable the two-factor authentication. It returns nil if the account can login now,
+// and the failed logins of the account are forgotten then.
+func (t *twoFactorService) BeginLogin(account *model.Account) (*model.PendingLogin, error) {
+	enabled, err := t.isEnabled(t.container.GetRepository(), account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !t.IsRequired(account) {
+		t.lockout.RecordSuccess(account.Name)
+		return nil, nil
+	}
+
+	expiry := t.container.GetConfig().TwoFactor.PendingExpiry
+	if expiry <= 0 {
+		expiry = defaultPendingExpiry
+	}
+	return &model.PendingLogin{
+		AccountID:          account.ID,
+		SessionVersion:     account.SessionVersion,
+		EnrollmentRequired: !enabled,
+		ExpiresAt:          time.Now().Add(time.Duration(expiry) * time.Minute),
+	}, nil
+}
+
+// FindPendingAccount returns the account of the pending login if it hasn't expired,
+// and the account hasn't been disabled or changed the password since then.
+func (t *twoFactorService) FindPendingAccount(pending *model.PendingLogin) (*model.Account, error) {
+	if pending == nil || !pending.ExpiresAt.After(time.Now()) || pending.Attempts >= maxTwoFactorAttempts {
+		return nil, NewUnauthorizedError("The login has expired. Please login again")
+	}
+	ac := model.Account{}
+	account, err := ac.FindByID(t.container.GetRepository(), pending.AccountID).Take()
+	if err != nil || account.Disabled || account.SessionVersion != pending.SessionVersion {
+		return nil, NewUnauthorizedError("The login has expired. Please login again")
+	}
+	return account, nil
+}
+
+// CountFailure counts up the attempts of the pending login if the error is caused by the incorrect code.
+// It returns false if the pending login can't be attempted anymore.
+func (t *twoFactorService) CountFailure(pending *model.PendingLogin, err error) bool {
+	if pending == nil {
+		return false
+	}
+	if AsError(err).Kind == KindValidation {
+		pending.Attempts++
+	}
+	return pending.Attempts < maxTwoFactorAttempts && pending.ExpiresAt.After(time.Now())
+}
+
+// CompleteLogin returns the account of the pending login if the code is correct. The incorrect codes are counted
+// as the failed logins of the account, so the account is locked out even if the pending login is replayed.
+func (t *twoFactorService) CompleteLogin(pending *model.PendingLogin, dto *dto.TwoFactorCodeDto,
+	remoteIP string) (*model.Account, error) {
+	account, err := t.FindPendingAccount(pending)
+	if err != nil {
+		return nil, err
+	}
+	if pending.EnrollmentRequired {
+		return nil, NewUnauthorizedError("The two-factor authentication must be enabled to login")
+	}
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+	if err = t.lockout.Check(account.Name, remoteIP); err != nil {
+		return nil, err
+	}
+
+	ok, err := t.verify(account.ID, dto.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		t.lockout.RecordFailure(account.Name, remoteIP)
+		return nil, NewValidationError("The code is incorrect", dto.CodeErrors())
+	}
+	t.lockout.RecordSuccess(account.Name)
+	return account, nil
+}
+
+// Authenticate checks the code of the account which logins without the second step, such as by the token
+// endpoint. It returns the unauthorized error if the code is incorrect, or the account has to enable
+// the two-factor authentication. The incorrect codes are counted as the failed logins of the account,
+// and the failures are forgotten if the account passes.
+func (t *twoFactorService) Authenticate(account *model.Account, code string, remoteIP string) error {
+	enabled, err := t.isEnabled(t.container.GetRepository(), account.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if t.IsRequired(account) {
+			return NewUnauthorizedError("The two-factor authentication must be enabled to login")
+		}
+		t.lockout.RecordSuccess(account.Name)
+		return nil
+	}
+	if code == "" {
+		return NewUnauthorizedError("The code of the two-factor authentication is required")
+	}
+	ok, err := t.verify(account.ID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		t.lockout.RecordFailure(account.Name, remoteIP)
+		return NewUnauthorizedError("The code of the two-factor authentication is incorrect")
+	}
+	t.lockout.RecordSuccess(account.Name)
+	return nil
+}
+
+// Setup creates a new secret of the account, which is enabled after a code of it is confirmed.
+// The secret which hasn't been confirmed yet is replaced.
+func (t *twoFactorService) Setup(account *model.Account) (*model.TwoFactorEnrollment, error) {
+	rep := t.container.GetRepository()
+	enabled, err := t.isEnabled(rep, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, NewConflictError("The two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the registration", err)
+	}
+	tf := &model.TwoFactor{AccountID: account.ID, Secret: secret}
+	if _, err = tf.Save(rep); err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the registration", err)
+	}
+
+	issuer := t.container.GetConfig().TwoFactor.Issuer
+	if issuer == "" {
+		issuer = defaultTwoFactorIssuer
+	}
+	uri := totp.ProvisioningURI(issuer, account.Name, secret)
+	qrCode, err := totp.QRCode(uri)
+	if err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the registration", err)
+	}
+	return &model.TwoFactorEnrollment{Secret: secret, ProvisioningURI: uri, QRCode: qrCode}, nil
+}
+
+// Confirm enables the secret of the account if the code of it is correct, and returns new recovery codes.
+// The failed logins of the account are forgotten then, because it completes the login which requires the enrollment.
+func (t *twoFactorService) Confirm(account *model.Account, dto *dto.TwoFactorCodeDto) (*model.RecoveryCodes, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+	rep := t.container.GetRepository()
+	tf := model.TwoFactor{}
+	current, err := tf.FindByAccountID(rep, account.ID).Take()
+	if err != nil {
+		return nil, NewConflictError("The two-factor authentication hasn't been set up yet")
+	}
+	if current.Enabled {
+		return nil, NewConflictError("The two-factor authentication is already enabled")
+	}
+	counter, ok := totp.Validate(current.Secret, dto.Code, time.Now(), t.skew())
+	if !ok {
+		return nil, NewValidationError("The code is incorrect", dto.CodeErrors())
+	}
+
+	var result *model.RecoveryCodes
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		if _, err = current.Enable(txRep, counter); err != nil {
+			return err
+		}
+		result, err = t.txReplaceRecoveryCodes(txRep, account.ID)
+		return err
+	}); trErr != nil {
+		return nil, t.transactionError(trErr, "Failed to the registration")
+	}
+	t.lockout.RecordSuccess(account.Name)
+	return result, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the account if the code is correct.
+func (t *twoFactorService) RegenerateRecoveryCodes(account *model.Account,
+	dto *dto.TwoFactorCodeDto) (*model.RecoveryCodes, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+	rep := t.container.GetRepository()
+	enabled, err := t.isEnabled(rep, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, NewConflictError("The two-factor authentication isn't enabled")
+	}
+	ok, err := t.verify(account.ID, dto.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewValidationError("The code is incorrect", dto.CodeErrors())
+	}
+
+	var result *model.RecoveryCodes
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		result, err = t.txReplaceRecoveryCodes(txRep, account.ID)
+		return err
+	}); trErr != nil {
+		return nil, t.transactionError(trErr, "Failed to the update")
+	}
+	return result, nil
+}
+
+// Disable deletes the secret and the recovery codes of the account after checking the password and the code.
+// The account which is required to use the two-factor authentication can't disable it.
+func (t *twoFactorService) Disable(account *model.Account, dto *dto.TwoFactorDisableDto) error {
+	if fields := dto.Validate(); fields != nil {
+		return NewValidationError("The request has invalid fields", fields)
+	}
+	if t.IsRequired(account) {
+		return NewConflictError("The two-factor authentication is required for the administrators")
+	}
+
+	rep := t.container.GetRepository()
+	ac := model.Account{}
+	current, err := ac.FindByID(rep, account.ID).Take()
+	if err != nil {
+		return NewUnauthorizedError("The current user haven't logged-in yet")
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(current.Password), []byte(dto.Password)); err != nil {
+		return NewValidationError("The password is incorrect", dto.PasswordErrors())
+	}
+	ok, err := t.verify(account.ID, dto.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NewValidationError("The code is incorrect", dto.CodeErrors())
+	}
+
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		tf := model.TwoFactor{}
+		if err := tf.DeleteByAccountID(txRep, account.ID); err != nil {
+			return err
+		}
+		rc := model.RecoveryCode{}
+		return rc.DeleteByAccountID(txRep, account.ID)
+	}); trErr != nil {
+		return t.transactionError(trErr, "Failed to delete")
+	}
+	return nil
+}
+
+// verify returns true if the code is the TOTP code which hasn't been used, or an unused recovery code
+// of the enabled two-factor authentication of the account. The recovery code is used up then.
+func (t *twoFactorService) verify(accountID uint, code string) (bool, error) {
+	rep := t.container.GetRepository()
+	tf := model.TwoFactor{}
+	current, err := tf.FindByAccountID(rep, accountID).Take()
+	if err != nil || !current.Enabled {
+		return false, nil
+	}
+
+	now := time.Now()
+	if totp.IsCode(code) {
+		counter, ok := totp.Validate(current.Secret, code, now, t.skew())
+		if !ok {
+			return false, nil
+		}
+		used, err := current.UseCounter(rep, counter)
+		if err != nil {
+			t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+			return false, NewInternalError("Failed to verify the code", err)
+		}
+		return used, nil
+	}
+
+	rc := model.RecoveryCode{}
+	used, err := rc.Use(rep, accountID, hashToken(normalizeRecoveryCode(code)), now)
+	if err != nil {
+		t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return false, NewInternalError("Failed to verify the code", err)
+	}
+	return used, nil
+}
+
+func (t *twoFactorService) isEnabled(rep repository.Repository, accountID uint) (bool, error) {
+	tf := model.TwoFactor{}
+	current, err := tf.FindByAccountID(rep, accountID).Take()
+	if err != nil {
+		return false, nil
+	}
+	return current.Enabled, nil
+}
+
+// txReplaceRecoveryCodes generates new recovery codes of the account, and persists only their hashes.
+func (t *twoFactorService) txReplaceRecoveryCodes(txRep repository.Repository,
+	accountID uint) (*model.RecoveryCodes, error) {
+	count := t.container.GetConfig().TwoFactor.RecoveryCodes
+	if count <= 0 {
+		count = defaultRecoveryCodes
+	}
+	codes := make([]string, count)
+	hashes := make([]string, count)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = code[:recoveryCodeGroupLength] + "-" + code[recoveryCodeGroupLength:]
+		hashes[i] = hashToken(code)
+	}
+	rc := model.RecoveryCode{}
+	if err := rc.ReplaceByAccountID(txRep, accountID, hashes); err != nil {
+		return nil, err
+	}
+	return &model.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (t *twoFactorService) skew() int {
+	if skew := t.container.GetConfig().TwoFactor.Skew; skew > 0 {
+		return skew
+	}
+	return defaultTwoFactorSkew
+}
+
+// transactionError converts the error of the transaction to the error of services. The errors of services
+// are returned as they are, and the others are logged as the failures of the database.
+func (t *twoFactorService) transactionError(err error, failure string) error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	t.container.GetLogger().GetZapLogger().Errorf(err.Error())
+	return NewInternalError(failure, err)
+}
+
+// normalizeRecoveryCode removes the separators and the spaces from the recovery code entered by the user.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
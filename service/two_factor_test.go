@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/service"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/lyh-demo/go-webapp-demo/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareForTwoFactorTest enables the two-factor authentication of the account test by the code of the
+// previous time step, and returns the secret and the recovery codes.
+func prepareForTwoFactorTest(t *testing.T) (container.Container, service.TwoFactorService, *model.Account, string,
+	[]string) {
+	container := test.PrepareForServiceTest()
+	twoFactor := service.NewTwoFactorService(container)
+	a := model.Account{}
+	account, _ := a.FindByName(container.GetRepository(), "test")
+
+	enrollment, err := twoFactor.Setup(account)
+	assert.NoError(t, err)
+	codes, err := twoFactor.Confirm(account, codeDto(code(t, enrollment.Secret, -1)))
+	assert.NoError(t, err)
+	return container, twoFactor, account, enrollment.Secret, codes.RecoveryCodes
+}
+
+// code returns the code of the secret at the time step of given offset from the current one.
+func code(t *testing.T, secret string, offset int64) string {
+	c, err := totp.Code(secret, totp.Counter(time.Now())+offset)
+	assert.NoError(t, err)
+	return c
+}
+
+func codeDto(code string) *dto.TwoFactorCodeDto {
+	d := dto.NewTwoFactorCodeDto(nil)
+	d.Code = code
+	return d
+}
+
+func assertUnauthorized(t *testing.T, err error) {
+	if assert.Error(t, err) {
+		assert.Equal(t, service.KindUnauthorized, service.AsError(err).Kind)
+	}
+}
+
+func TestTwoFactorAuthenticate_Success(t *testing.T) {
+	_, twoFactor, account, secret, _ := prepareForTwoFactorTest(t)
+
+	assert.NoError(t, twoFactor.Authenticate(account, code(t, secret, 0), "192.0.2.1"))
+}
+
+func TestTwoFactorAuthenticate_Replay(t *testing.T) {
+	_, twoFactor, account, secret, _ := prepareForTwoFactorTest(t)
+
+	// the code used by the confirmation can't be used again.
+	assertUnauthorized(t, twoFactor.Authenticate(account, code(t, secret, -1), "192.0.2.1"))
+
+	current := code(t, secret, 0)
+	assert.NoError(t, twoFactor.Authenticate(account, current, "192.0.2.1"))
+	assertUnauthorized(t, twoFactor.Authenticate(account, current, "192.0.2.1"))
+
+	// the code of the earlier time step is rejected after a later one has been used.
+	assert.NoError(t, twoFactor.Authenticate(account, code(t, secret, 1), "192.0.2.1"))
+	assertUnauthorized(t, twoFactor.Authenticate(account, current, "192.0.2.1"))
+}
+
+func TestTwoFactorAuthenticate_RecoveryCode(t *testing.T) {
+	_, twoFactor, account, _, recoveryCodes := prepareForTwoFactorTest(t)
+
+	assert.NoError(t, twoFactor.Authenticate(account, recoveryCodes[0], "192.0.2.1"))
+	assertUnauthorized(t, twoFactor.Authenticate(account, recoveryCodes[0], "192.0.2.1"))
+	assert.NoError(t, twoFactor.Authenticate(account, recoveryCodes[1], "192.0.2.1"))
+}
+
+func TestTwoFactorAuthenticate_CountFailures(t *testing.T) {
+	container, twoFactor, account, secret, _ := prepareForTwoFactorTest(t)
+	container.GetConfig().Lockout.Enabled = true
+	container.GetConfig().Lockout.MaxFailures = 3
+
+	for i := 0; i < 3; i++ {
+		assertUnauthorized(t, twoFactor.Authenticate(account, "000000", "192.0.2.1"))
+	}
+
+	attempt, _ := container.GetLockoutStore().Find(model.LoginAttemptUsername, "test")
+	assert.Equal(t, 3, attempt.Failures)
+	assert.True(t, attempt.IsLocked(time.Now()))
+
+	// the failures of the account are forgotten after the correct code.
+	assert.NoError(t, twoFactor.Authenticate(account, code(t, secret, 0), "192.0.2.1"))
+	attempt, _ = container.GetLockoutStore().Find(model.LoginAttemptUsername, "test")
+	assert.Equal(t, 0, attempt.Failures)
+}
+
+func TestTwoFactorConfirm_IncorrectCode(t *testing.T) {
+	container := test.PrepareForServiceTest()
+	twoFactor := service.NewTwoFactorService(container)
+	a := model.Account{}
+	account, _ := a.FindByName(container.GetRepository(), "test")
+	_, _ = twoFactor.Setup(account)
+
+	_, err := twoFactor.Confirm(account, codeDto("000000"))
+
+	assert.Equal(t, service.KindValidation, service.AsError(err).Kind)
+	status, _ := twoFactor.FindStatus(account)
+	assert.False(t, status.Enabled)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/model/dto"
+	"github.com/lyh-demo/go-webapp-demo/repository"
+	"github.com/lyh-demo/go-webapp-demo/util"
+	"github.com/lyh-demo/go-webapp-demo/webhook"
+	"time"
+)
+
+const webhookDeliveryPageSize = 20
+
+// WebhookService is a service for managing webhook subscriptions and their deliveries.
+type WebhookService interface {
+	FindAllWebhooks() (*[]model.Webhook, error)
+	FindByID(id string) (*model.Webhook, error)
+	FindDeliveries(webhookID string, page string, size string) (*[]model.WebhookDelivery, error)
+	CreateWebhook(dto *dto.WebhookDto) (*model.Webhook, error)
+	UpdateWebhook(dto *dto.WebhookDto, id string) (*model.Webhook, error)
+	DeleteWebhook(id string) (*model.Webhook, error)
+	Redeliver(deliveryID string) (*model.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	container container.Container
+}
+
+// NewWebhookService is constructor.
+func NewWebhookService(container container.Container) WebhookService {
+	return &webhookService{container: container}
+}
+
+// FindAllWebhooks returns the list of all webhooks.
+func (w *webhookService) FindAllWebhooks() (*[]model.Webhook, error) {
+	rep := w.container.GetRepository()
+	webhook := model.Webhook{}
+	result, err := webhook.FindAll(rep)
+	if err != nil {
+		w.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// FindByID returns one record matched webhook's id.
+func (w *webhookService) FindByID(id string) (*model.Webhook, error) {
+	if !util.IsNumeric(id) {
+		return nil, NewNotFoundError(fmt.Sprintf("Webhook %s does not exist", id))
+	}
+
+	rep := w.container.GetRepository()
+	webhook := model.Webhook{}
+	result, err := webhook.FindByID(rep, util.ConvertToUint(id)).Take()
+	if err != nil {
+		return nil, NewNotFoundError(fmt.Sprintf("Webhook %s does not exist", id))
+	}
+	return result, nil
+}
+
+// FindDeliveries returns the delivery history of the given webhook in order of the newest.
+func (w *webhookService) FindDeliveries(webhookID string, page string, size string) (*[]model.WebhookDelivery, error) {
+	if _, err := w.FindByID(webhookID); err != nil {
+		return nil, err
+	}
+
+	p, s := 0, webhookDeliveryPageSize
+	if util.IsNumeric(page) && util.ConvertToInt(page) > 0 {
+		p = util.ConvertToInt(page)
+	}
+	if util.IsNumeric(size) && util.ConvertToInt(size) > 0 {
+		s = util.ConvertToInt(size)
+	}
+
+	rep := w.container.GetRepository()
+	delivery := model.WebhookDelivery{}
+	result, err := delivery.FindByWebhookID(rep, util.ConvertToUint(webhookID), p, s)
+	if err != nil {
+		w.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to fetch data", err)
+	}
+	return result, nil
+}
+
+// CreateWebhook registers the given webhook subscription.
+func (w *webhookService) CreateWebhook(dto *dto.WebhookDto) (*model.Webhook, error) {
+	if fields := dto.ValidateForCreate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	rep := w.container.GetRepository()
+	result, err := dto.Create().Create(rep)
+	if err != nil {
+		w.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the registration", err)
+	}
+	return result, nil
+}
+
+// UpdateWebhook updates the given webhook subscription. The secret is kept if it is omitted.
+func (w *webhookService) UpdateWebhook(dto *dto.WebhookDto, id string) (*model.Webhook, error) {
+	if fields := dto.Validate(); fields != nil {
+		return nil, NewValidationError("The request has invalid fields", fields)
+	}
+
+	current, err := w.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	current.URL = dto.URL
+	current.Events = dto.Events
+	if dto.Secret != "" {
+		current.Secret = dto.Secret
+	}
+	if dto.Active != nil {
+		current.Active = *dto.Active
+	}
+
+	rep := w.container.GetRepository()
+	result, err := current.Update(rep)
+	if err != nil {
+		w.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the update", err)
+	}
+	return result, nil
+}
+
+// DeleteWebhook deletes the given webhook subscription with its delivery history.
+func (w *webhookService) DeleteWebhook(id string) (*model.Webhook, error) {
+	current, err := w.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := w.container.GetRepository()
+	var result *model.Webhook
+	if trErr := rep.Transaction(func(txRep repository.Repository) error {
+		delivery := model.WebhookDelivery{}
+		if err := delivery.DeleteByWebhookID(txRep, current.ID); err != nil {
+			return err
+		}
+		result, err = current.Delete(txRep)
+		return err
+	}); trErr != nil {
+		w.container.GetLogger().GetZapLogger().Errorf(trErr.Error())
+		return nil, NewInternalError("Failed to the delete", trErr)
+	}
+	return result, nil
+}
+
+// Redeliver queues the payload of the given delivery again as a new delivery.
+// The original delivery is kept as the history.
+func (w *webhookService) Redeliver(deliveryID string) (*model.WebhookDelivery, error) {
+	if !util.IsNumeric(deliveryID) {
+		return nil, NewNotFoundError(fmt.Sprintf("Delivery %s does not exist", deliveryID))
+	}
+
+	rep := w.container.GetRepository()
+	delivery := model.WebhookDelivery{}
+	original, err := delivery.FindByID(rep, util.ConvertToUint(deliveryID)).Take()
+	if err != nil {
+		return nil, NewNotFoundError(fmt.Sprintf("Delivery %s does not exist", deliveryID))
+	}
+
+	result, err := model.NewWebhookDelivery(original.WebhookID, original.Event, original.Payload).Create(rep)
+	if err != nil {
+		w.container.GetLogger().GetZapLogger().Errorf(err.Error())
+		return nil, NewInternalError("Failed to the redelivery", err)
+	}
+	return result, nil
+}
+
+// txPublishEvent records the event to the outbox for each webhook subscribing it.
+// It must be called in the transaction of the change, so the event is recorded only if the change is committed.
+func txPublishEvent(txRep repository.Repository, event string, data interface{}) error {
+	w := model.Webhook{}
+	webhooks, err := w.FindByEvent(txRep, event)
+	if err != nil || len(*webhooks) == 0 {
+		return err
+	}
+
+	payload, err := webhook.NewPayload(event, data, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range *webhooks {
+		if _, err := model.NewWebhookDelivery((*webhooks)[i].ID, event, payload).Create(txRep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
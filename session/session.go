@@ -1,6 +1,8 @@
 package session
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/sessions"
@@ -9,17 +11,49 @@ import (
 	"github.com/lyh-demo/go-webapp-demo/logger"
 	"github.com/lyh-demo/go-webapp-demo/model"
 	"gopkg.in/boj/redistore.v1"
+	"net/http"
+	"os"
+	"strings"
 )
 
 const (
-	// sessionStr represents a string of session key.
+	// sessionStr represents the default name of the session cookie.
 	sessionStr = "SESSION"
+	// defaultKey is the key of the session used if no keys are configured, which is refused in production.
+	defaultKey = "secret"
+	// minAuthenticationKeyBytes is the minimum length of the authentication key.
+	minAuthenticationKeyBytes = 32
+	// EnvAuthenticationKeys is the environment variable of the authentication keys separated by commas,
+	// which takes precedence over the keys of the configuration.
+	EnvAuthenticationKeys = "WEB_APP_SESSION_AUTH_KEYS"
+	// EnvEncryptionKeys is the environment variable of the encryption keys separated by commas,
+	// which are paired with the authentication keys in the same order.
+	EnvEncryptionKeys = "WEB_APP_SESSION_ENCRYPTION_KEYS"
 	// Account is the key of account data in the session.
 	Account = "Account"
+	// SessionVersion is the key of the session version of the account, which isn't included in the account data.
+	SessionVersion = "SessionVersion"
+	// OIDCRequest is the key of the authorization request of the login through the OpenID Connect provider.
+	OIDCRequest = "OIDCRequest"
+	// PendingLogin is the key of the login which has passed the password and waits for the second factor.
+	PendingLogin = "PendingLogin"
+	// SessionToken is the key of the token which identifies the session tracked for the account.
+	SessionToken = "SessionToken"
+	// CSRFToken is the key of the token which protects the session against the cross-site request forgery.
+	CSRFToken = "CSRFToken"
+	// CSRFCookie is the cookie of the CSRF token, which is readable by the scripts of the application
+	// to send the token back by the header.
+	CSRFCookie = "XSRF-TOKEN"
+	// csrfTokenBytes is the length of the random bytes of the CSRF token.
+	csrfTokenBytes = 32
+	// requestAccount is the key of the account authenticated without the session, such as by the access token.
+	requestAccount = "RequestAccount"
 )
 
 type session struct {
-	store sessions.Store
+	store   sessions.Store
+	name    string
+	options sessions.Options
 }
 
 // Session represents an interface for accessing the session on the application.
@@ -33,24 +67,129 @@ type Session interface {
 	GetValue(c echo.Context, key string) string
 	SetAccount(c echo.Context, account *model.Account) error
 	GetAccount(c echo.Context) *model.Account
+	SetRequestAccount(c echo.Context, account *model.Account)
+	IsSessionAuthenticated(c echo.Context) bool
+	SetSessionToken(c echo.Context, token string) error
+	GetSessionToken(c echo.Context) string
+	GetCSRFToken(c echo.Context) (string, error)
+	ResetCSRFToken(c echo.Context) (string, error)
 }
 
-// NewSession is constructor.
-func NewSession(logger logger.Logger, conf *config.Config) Session {
+// NewSession is constructor. The session is signed and encrypted by the first pair of the configured keys,
+// and the other pairs are accepted to read the session during the rotation of the keys.
+// The default key is used if no keys are configured, but it is refused in production.
+func NewSession(logger logger.Logger, conf *config.Config, env string) Session {
+	keyPairs, err := loadKeyPairs(conf.Session)
+	if err != nil {
+		logger.GetZapLogger().Panicf("failed to load the keys of the session, %s", err.Error())
+	}
+	if len(keyPairs) == 0 {
+		if env == config.PRD {
+			logger.GetZapLogger().Panicf("no keys of the session are configured, the default key can't be used in %s", env)
+		}
+		logger.GetZapLogger().Warnf("no keys of the session are configured, use the default key")
+		keyPairs = [][]byte{[]byte(defaultKey), nil}
+	}
+	options, err := newOptions(conf.Session)
+	if err != nil {
+		logger.GetZapLogger().Panicf("failed to load the options of the session, %s", err.Error())
+	}
+	if options.SameSite == http.SameSiteNoneMode && !options.Secure {
+		logger.GetZapLogger().Warnf("the session cookie of SameSite=None is rejected by the browsers unless it is secure")
+	}
+	name := conf.Session.Name
+	if name == "" {
+		name = sessionStr
+	}
+
 	if !conf.Redis.Enabled {
 		logger.GetZapLogger().Infof("use CookieStore for session")
-		return &session{sessions.NewCookieStore([]byte("secret"))}
+		store := sessions.NewCookieStore(keyPairs...)
+		if options.MaxAge > 0 {
+			store.MaxAge(options.MaxAge)
+		}
+		store.Options = &options
+		return &session{store: store, name: name, options: options}
 	}
 
 	logger.GetZapLogger().Infof("use redis for session")
 	logger.GetZapLogger().Infof("Try redis connection")
 	address := fmt.Sprintf("%s:%s", conf.Redis.Host, conf.Redis.Port)
-	store, err := redistore.NewRediStore(conf.Redis.ConnectionPoolSize, "tcp", address, "", []byte("secret"))
+	store, err := redistore.NewRediStore(conf.Redis.ConnectionPoolSize, "tcp", address, "", keyPairs...)
 	if err != nil {
 		logger.GetZapLogger().Panicf("Failure redis connection, %s", err.Error())
 	}
+	if options.MaxAge > 0 {
+		store.SetMaxAge(options.MaxAge)
+	}
+	store.Options = &options
 	logger.GetZapLogger().Infof(fmt.Sprintf("Success redis connection, %s", address))
-	return &session{store: store}
+	return &session{store: store, name: name, options: options}
+}
+
+// loadKeyPairs returns the pairs of the authentication key and the encryption key, which are encoded in base64.
+// The keys of the environment variables take precedence over the keys of the configuration.
+// The encryption key may be empty, and then the session is signed but isn't encrypted.
+func loadKeyPairs(conf config.SessionConfig) ([][]byte, error) {
+	keys := conf.Keys
+	if value := os.Getenv(EnvAuthenticationKeys); value != "" {
+		encryptionKeys := strings.Split(os.Getenv(EnvEncryptionKeys), ",")
+		keys = nil
+		for i, authenticationKey := range strings.Split(value, ",") {
+			key := config.SessionKeyConfig{AuthenticationKey: strings.TrimSpace(authenticationKey)}
+			if i < len(encryptionKeys) {
+				key.EncryptionKey = strings.TrimSpace(encryptionKeys[i])
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	var keyPairs [][]byte
+	for i, key := range keys {
+		authenticationKey, err := base64.StdEncoding.DecodeString(key.AuthenticationKey)
+		if err != nil {
+			return nil, fmt.Errorf("the authentication key %d isn't encoded in base64", i)
+		}
+		if len(authenticationKey) < minAuthenticationKeyBytes {
+			return nil, fmt.Errorf("the authentication key %d has to be %d bytes or more", i, minAuthenticationKeyBytes)
+		}
+		var encryptionKey []byte
+		if key.EncryptionKey != "" {
+			if encryptionKey, err = base64.StdEncoding.DecodeString(key.EncryptionKey); err != nil {
+				return nil, fmt.Errorf("the encryption key %d isn't encoded in base64", i)
+			}
+			if n := len(encryptionKey); n != 16 && n != 24 && n != 32 {
+				return nil, fmt.Errorf("the encryption key %d has to be 16, 24 or 32 bytes", i)
+			}
+		}
+		keyPairs = append(keyPairs, authenticationKey, encryptionKey)
+	}
+	return keyPairs, nil
+}
+
+// newOptions returns the options of the session cookie. The max age is configured in minutes,
+// and the cookie is kept until the browser is closed if it is zero.
+func newOptions(conf config.SessionConfig) (sessions.Options, error) {
+	options := sessions.Options{
+		Path:     "/",
+		Domain:   conf.Domain,
+		MaxAge:   conf.MaxAge * 60,
+		Secure:   conf.Secure,
+		HttpOnly: true,
+	}
+	switch strings.ToLower(conf.SameSite) {
+	case "", "lax":
+		options.SameSite = http.SameSiteLaxMode
+	case "strict":
+		options.SameSite = http.SameSiteStrictMode
+	case "none":
+		options.SameSite = http.SameSiteNoneMode
+	case "default":
+		options.SameSite = http.SameSiteDefaultMode
+	default:
+		return options, fmt.Errorf("unsupported SameSite of the cookie, %s", conf.SameSite)
+	}
+	return options, nil
 }
 
 func (s *session) GetStore() sessions.Store {
@@ -59,27 +198,26 @@ func (s *session) GetStore() sessions.Store {
 
 // Get returns a session for the current request.
 func (s *session) Get(c echo.Context) *sessions.Session {
-	sess, _ := s.store.Get(c.Request(), sessionStr)
+	sess, _ := s.store.Get(c.Request(), s.name)
 	return sess
 }
 
-// Save saves the current session.
+// Save saves the current session with the configured options of the cookie.
 func (s *session) Save(c echo.Context) error {
 	sess := s.Get(c)
-	sess.Options = &sessions.Options{
-		Path:     "/",
-		HttpOnly: true,
-	}
+	options := s.options
+	sess.Options = &options
 	return s.saveSession(c, sess)
 }
 
-// Delete the current session.
+// Delete the current session, and the cookie of the CSRF token if it exists.
 func (s *session) Delete(c echo.Context) error {
 	sess := s.Get(c)
-	sess.Options = &sessions.Options{
-		Path:     "/",
-		HttpOnly: true,
-		MaxAge:   -1,
+	options := s.options
+	options.MaxAge = -1
+	sess.Options = &options
+	if _, err := c.Cookie(CSRFCookie); err == nil {
+		s.setCSRFCookie(c, "", -1)
 	}
 	return s.saveSession(c, sess)
 }
@@ -116,15 +254,107 @@ func (s *session) GetValue(c echo.Context, key string) string {
 	return ""
 }
 
+// SetAccount sets the account data and its session version.
 func (s *session) SetAccount(c echo.Context, account *model.Account) error {
+	if account != nil {
+		if err := s.SetValue(c, SessionVersion, account.SessionVersion); err != nil {
+			return err
+		}
+	}
 	return s.SetValue(c, Account, account)
 }
 
+// SetRequestAccount sets the account authenticated without the session, such as by the access token.
+// The account is kept only in the current request, and it takes precedence over the account of the session.
+func (s *session) SetRequestAccount(c echo.Context, account *model.Account) {
+	c.Set(requestAccount, account)
+}
+
+// IsSessionAuthenticated returns true if the account of the current request is the account of the session,
+// rather than the account authenticated by the access token or the API key.
+func (s *session) IsSessionAuthenticated(c echo.Context) bool {
+	if a, ok := c.Get(requestAccount).(*model.Account); ok && a != nil {
+		return false
+	}
+	return s.GetValue(c, Account) != ""
+}
+
+// GetAccount returns the account of the current request if it is set,
+// or the account data with the session version when it was set.
 func (s *session) GetAccount(c echo.Context) *model.Account {
+	if a, ok := c.Get(requestAccount).(*model.Account); ok && a != nil {
+		return a
+	}
 	if v := s.GetValue(c, Account); v != "" {
 		a := &model.Account{}
 		_ = json.Unmarshal([]byte(v), a)
+		if version := s.GetValue(c, SessionVersion); version != "" {
+			_ = json.Unmarshal([]byte(version), &a.SessionVersion)
+		}
 		return a
 	}
 	return nil
 }
+
+// SetSessionToken sets the token which identifies the session tracked for the account.
+func (s *session) SetSessionToken(c echo.Context, token string) error {
+	return s.SetValue(c, SessionToken, token)
+}
+
+// GetSessionToken returns the token which identifies the session tracked for the account,
+// or an empty string if the session isn't tracked.
+func (s *session) GetSessionToken(c echo.Context) string {
+	var token string
+	if v := s.GetValue(c, SessionToken); v != "" {
+		_ = json.Unmarshal([]byte(v), &token)
+	}
+	return token
+}
+
+// GetCSRFToken returns the CSRF token of the session. A new token is generated and the session is saved
+// if the session doesn't have it, and the cookie of the token is set if the request doesn't have the current one.
+func (s *session) GetCSRFToken(c echo.Context) (string, error) {
+	var token string
+	if v := s.GetValue(c, CSRFToken); v != "" {
+		_ = json.Unmarshal([]byte(v), &token)
+	}
+	if token == "" {
+		var err error
+		if token, err = s.ResetCSRFToken(c); err != nil {
+			return "", err
+		}
+		return token, s.Save(c)
+	}
+	if cookie, err := c.Cookie(CSRFCookie); err != nil || cookie.Value != token {
+		s.setCSRFCookie(c, token, s.options.MaxAge)
+	}
+	return token, nil
+}
+
+// ResetCSRFToken sets a new CSRF token to the session and its cookie, and returns it.
+// It is called when the account logs in so that the token before the login can't be used.
+func (s *session) ResetCSRFToken(c echo.Context) (string, error) {
+	b := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate the CSRF token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	if err := s.SetValue(c, CSRFToken, token); err != nil {
+		return "", err
+	}
+	s.setCSRFCookie(c, token, s.options.MaxAge)
+	return token, nil
+}
+
+// setCSRFCookie sets the cookie of the CSRF token by the options of the session cookie,
+// except that the cookie is readable by the scripts.
+func (s *session) setCSRFCookie(c echo.Context, token string, maxAge int) {
+	c.SetCookie(sessions.NewCookie(CSRFCookie, token, &sessions.Options{
+		Path:     s.options.Path,
+		Domain:   s.options.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.options.Secure,
+		HttpOnly: false,
+		SameSite: s.options.SameSite,
+	}))
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const oidcKeyID = "test-key"
+
+// OIDCProvider is a mock OpenID Connect provider built on httptest. It has the discovery, the JWKS and
+// the token endpoint, and Authorize plays the authorization endpoint after the user has logged in.
+type OIDCProvider struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+	key          ed25519.PrivateKey
+	mu           sync.Mutex
+	codes        map[string]*oidcGrant
+}
+
+// oidcGrant represents the authorization code issued by the mock provider.
+type oidcGrant struct {
+	challenge   string
+	redirectURI string
+	claims      jwt.MapClaims
+}
+
+// NewOIDCProvider starts the mock provider of given client. Close it after the test.
+func NewOIDCProvider(clientID string, clientSecret string) *OIDCProvider {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	p := &OIDCProvider{ClientID: clientID, ClientSecret: clientSecret, key: private, codes: map[string]*oidcGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                p.URL(),
+			"authorization_endpoint":                p.URL() + "/authorize",
+			"token_endpoint":                        p.URL() + "/token",
+			"jwks_uri":                              p.URL() + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"EdDSA"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{"kty": "OKP",
+			"crv": "Ed25519", "kid": oidcKeyID, "use": "sig", "x": base64.RawURLEncoding.EncodeToString(public)}}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+// URL returns the issuer of the mock provider.
+func (p *OIDCProvider) URL() string {
+	return p.Server.URL
+}
+
+// Close stops the mock provider.
+func (p *OIDCProvider) Close() {
+	p.Server.Close()
+}
+
+// Authorize accepts the authorization request of given URL as the user of given subject, and returns
+// the authorization code and the state which the provider redirects to the callback with.
+// The claims are added to the ID token, and they can override the standard claims such as iss, aud and nonce.
+func (p *OIDCProvider) Authorize(authURL string, subject string, claims map[string]interface{}) (string, string,
+	error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := u.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != p.ClientID ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", errors.New("the authorization request is invalid")
+	}
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{"iss": p.URL(), "aud": p.ClientID, "sub": subject, "nonce": query.Get("nonce"),
+		"iat": now.Unix(), "exp": now.Add(time.Minute).Unix()}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+	code := base64.RawURLEncoding.EncodeToString([]byte(subject + now.String()))
+	p.mu.Lock()
+	p.codes[code] = &oidcGrant{challenge: query.Get("code_challenge"), redirectURI: query.Get("redirect_uri"),
+		claims: idClaims}
+	p.mu.Unlock()
+	return code, query.Get("state"), nil
+}
+
+// token exchanges the authorization code for the ID token after checking the client and the code verifier.
+// The code can be used only once.
+func (p *OIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	p.mu.Lock()
+	grant, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	id, secret, _ := r.BasicAuth()
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || id != p.ClientID || secret != p.ClientSecret || r.Form.Get("redirect_uri") != grant.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodEdDSA, grant.claims)
+	t.Header["kid"] = oidcKeyID
+	idToken, _ := t.SignedString(p.key)
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
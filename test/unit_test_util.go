@@ -4,13 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/labstack/echo/v4"
+	"github.com/lyh-demo/go-webapp-demo/broker"
 	"github.com/lyh-demo/go-webapp-demo/config"
 	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/i18n"
+	"github.com/lyh-demo/go-webapp-demo/lockout"
 	"github.com/lyh-demo/go-webapp-demo/logger"
 	"github.com/lyh-demo/go-webapp-demo/middleware"
 	"github.com/lyh-demo/go-webapp-demo/migration"
+	"github.com/lyh-demo/go-webapp-demo/notifier"
 	"github.com/lyh-demo/go-webapp-demo/repository"
 	"github.com/lyh-demo/go-webapp-demo/session"
+	"github.com/lyh-demo/go-webapp-demo/token"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -71,17 +76,53 @@ func createConfig(isSecurity bool) *config.Config {
 	conf.Database.Migration = true
 	conf.Extension.MasterGenerator = true
 	conf.Extension.SecurityEnabled = isSecurity
+	conf.JWT.Enabled = true
+	conf.JWT.Algorithm = "EdDSA"
 	conf.Log.RequestLogFormat = "${remote_ip} ${account_name} ${uri} ${method} ${status}"
 	return conf
 }
 
 func initContainer(conf *config.Config, logger logger.Logger) container.Container {
 	rep := repository.NewBookRepository(logger, conf)
-	sess := session.NewSession(logger, conf)
+	sess := session.NewSession(logger, conf, "test")
 	messages := map[string]string{
-		"ValidationErrMessageBookTitle": "Please enter the title with 3 to 50 characters.",
-		"ValidationErrMessageBookISBN":  "Please enter the ISBN with 10 to 20 characters."}
-	c := container.NewContainer(rep, sess, conf, messages, logger, "test")
+		"ValidationErrMessageBookTitle":                "Please enter the title with 3 to 50 characters.",
+		"ValidationErrMessageBookISBN":                 "Please enter the ISBN with 10 to 20 characters.",
+		"ValidationErrMessageBookCategoryNotFound":     "Category %d does not exist.",
+		"ValidationErrMessageBookFormatNotFound":       "Format %d does not exist.",
+		"ValidationErrMessageWebhookURL":               "Please enter the URL starting with http:// or https:// within 255 characters.",
+		"ValidationErrMessageWebhookEvents":            "Please select the events from book.created, book.updated and book.deleted.",
+		"ValidationErrMessageWebhookSecret":            "Please enter the secret with 16 to 255 characters.",
+		"ValidationErrMessageAccountLocale":            "Please select the locale from the supported locales.",
+		"ValidationErrMessageAccountName":              "Please enter the name with 3 to 32 characters.",
+		"ValidationErrMessageAccountPassword":          "Please enter the password with 8 to 72 characters.",
+		"ValidationErrMessageAccountAuthority":         "Please select the authority.",
+		"ValidationErrMessageAccountAuthorityNotFound": "Authority %d does not exist.",
+		"ValidationErrMessageAccountEmail":             "Please enter the email address within 255 characters.",
+		"ValidationErrMessageAccountCurrentPassword":   "The current password is incorrect.",
+		"ValidationErrMessageAccountResetRequest":      "Please enter the username or the email address.",
+		"ValidationErrMessageAccountResetToken":        "The token is invalid or has expired. Please request the password reset again.",
+		"ValidationErrMessageAuthorityName":            "Please enter the name of the authority with 2 to 32 characters.",
+		"ValidationErrMessageAuthorityPermissions":     "Please select the permissions from the permissions of this application.",
+		"ValidationErrMessageAPIKeyName":               "Please enter the name of the API key with 1 to 64 characters.",
+		"ValidationErrMessageAPIKeyScopes":             "Please select the scopes from the permissions which you have.",
+		"ValidationErrMessageAPIKeyExpiresAt":          "Please enter the expiration date in the future.",
+		"ValidationErrMessageTwoFactorCode":            "The code is incorrect. Please enter the code of the authenticator app or a recovery code.",
+		"ValidationErrMessageLockoutUnlock":            "Please enter the username or the IP address.",
+		"ValidationErrMessageAuditLogTime":             "Please enter the time in RFC 3339 or the date such as 2024-01-31.",
+		"NotificationPasswordResetSubject":             "Reset your password",
+		"NotificationPasswordResetBody":                "Open the following URL within %d minutes to reset the password of %s. If you did not request it, please ignore this message. %s",
+		"ErrMessageBadRequest":                         "Bad Request",
+		"ErrMessageUnauthorized":                       "Unauthorized",
+		"ErrMessageForbidden":                          "Forbidden",
+		"ErrMessageNotFound":                           "Not Found",
+		"ErrMessageMethodNotAllowed":                   "Method Not Allowed",
+		"ErrMessageInternalServerError":                "Internal Server Error"}
+	b := broker.NewBroker(logger, conf)
+	n := notifier.NewNotifier(logger, conf)
+	k := token.NewKeySet(logger, conf)
+	l := lockout.NewStore(logger, conf, rep)
+	c := container.NewContainer(rep, sess, b, n, k, l, conf, i18n.NewBundles(messages, nil), logger, "test")
 	return c
 }
 
@@ -0,0 +1,149 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"math/big"
+	"os"
+)
+
+const rsaKeySize = 2048
+
+// JSONWebKeySet represents the set of the public keys defined by RFC 7517.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey represents a public key defined by RFC 7517.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// loadPrivateKey reads the PEM encoded private key of the given algorithm from the file.
+func loadPrivateKey(algorithm string, path string) (crypto.Signer, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.ParseRSAPrivateKeyFromPEM(pem)
+	case AlgorithmES256:
+		key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("the curve of %s isn't P-256", path)
+		}
+		return key, nil
+	case AlgorithmEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		if ed, ok := key.(ed25519.PrivateKey); ok {
+			return ed, nil
+		}
+		return nil, fmt.Errorf("the key of %s isn't Ed25519", path)
+	}
+	return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
+}
+
+// generatePrivateKey returns a new private key of the given algorithm.
+func generatePrivateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeySize)
+	case AlgorithmES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
+}
+
+// newJSONWebKey converts the public key to the JSON web key.
+func newJSONWebKey(id string, algorithm string, public crypto.PublicKey) JSONWebKey {
+	jwk := JSONWebKey{Kid: id, Use: "sig", Alg: algorithm}
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(key.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.X = encode(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encode(key)
+	}
+	return jwk
+}
+
+// PublicKey converts the JSON web key to the public key to verify the signatures.
+func (k *JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != elliptic.P256().Params().Name {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,168 @@
+package token
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/lyh-demo/go-webapp-demo/config"
+	"github.com/lyh-demo/go-webapp-demo/logger"
+	"time"
+)
+
+const (
+	// AlgorithmRS256 signs the tokens by RSASSA-PKCS1-v1_5 with SHA-256.
+	AlgorithmRS256 = "RS256"
+	// AlgorithmES256 signs the tokens by ECDSA using P-256 and SHA-256.
+	AlgorithmES256 = "ES256"
+	// AlgorithmEdDSA signs the tokens by Ed25519.
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	defaultIssuer            = "go-webapp-demo"
+	defaultAudience          = "go-webapp-demo"
+	defaultAccessTokenExpiry = 15
+)
+
+// ErrInvalidToken is returned when the token is malformed, expired or signed by an unknown key.
+var ErrInvalidToken = errors.New("the token is invalid")
+
+// Claims represents the claims of the access token. The subject is the ID of the account,
+// and the authorities and the permissions are the ones which the account had when the token was issued.
+type Claims struct {
+	jwt.StandardClaims
+	Name           string   `json:"name"`
+	Authorities    []string `json:"authorities"`
+	Permissions    []string `json:"permissions"`
+	SessionVersion uint     `json:"sv"`
+}
+
+// KeySet represents an interface for signing and verifying the access tokens by the configured keys.
+// The first key signs the tokens, and all keys verify them, so that the keys can be rotated
+// by adding a new key at the top and removing the old one after its tokens have expired.
+type KeySet interface {
+	Sign(claims *Claims, now time.Time) (string, time.Time, error)
+	Verify(token string) (*Claims, error)
+	JWKS() *JSONWebKeySet
+}
+
+type keySet struct {
+	method   jwt.SigningMethod
+	keys     []*signingKey
+	issuer   string
+	audience string
+	expiry   time.Duration
+}
+
+type signingKey struct {
+	id      string
+	private crypto.Signer
+}
+
+// NewKeySet is constructor. It loads the keys of the configured algorithm, and generates an ephemeral key
+// if no keys are configured, so the tokens can't be verified after restarting the application.
+func NewKeySet(logger logger.Logger, conf *config.Config) KeySet {
+	jwtConf := conf.JWT
+	algorithm := jwtConf.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmRS256
+	}
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil || !isSupported(algorithm) {
+		logger.GetZapLogger().Panicf("unsupported algorithm of the token, %s", algorithm)
+	}
+
+	ks := &keySet{
+		method:   method,
+		issuer:   valueOrDefault(jwtConf.Issuer, defaultIssuer),
+		audience: valueOrDefault(jwtConf.Audience, defaultAudience),
+		expiry:   time.Duration(jwtConf.AccessTokenExpiry) * time.Minute,
+	}
+	if ks.expiry <= 0 {
+		ks.expiry = defaultAccessTokenExpiry * time.Minute
+	}
+
+	for _, k := range jwtConf.Keys {
+		private, err := loadPrivateKey(algorithm, k.PrivateKeyFile)
+		if err != nil {
+			logger.GetZapLogger().Panicf("failed to load the key %s, %s", k.ID, err.Error())
+		}
+		ks.keys = append(ks.keys, &signingKey{id: k.ID, private: private})
+	}
+	if len(ks.keys) == 0 && jwtConf.Enabled {
+		private, err := generatePrivateKey(algorithm)
+		if err != nil {
+			logger.GetZapLogger().Panicf("failed to generate the key, %s", err.Error())
+		}
+		id := fmt.Sprintf("ephemeral-%d", time.Now().Unix())
+		ks.keys = append(ks.keys, &signingKey{id: id, private: private})
+		logger.GetZapLogger().Warnf("no keys of the token are configured, use the ephemeral key %s", id)
+	}
+	return ks
+}
+
+// Sign returns the signed token of the given claims, and the time when it expires.
+func (k *keySet) Sign(claims *Claims, now time.Time) (string, time.Time, error) {
+	if len(k.keys) == 0 {
+		return "", time.Time{}, errors.New("no keys to sign the token")
+	}
+	expiresAt := now.Add(k.expiry)
+	claims.Issuer = k.issuer
+	claims.Audience = k.audience
+	claims.IssuedAt = now.Unix()
+	claims.NotBefore = now.Unix()
+	claims.ExpiresAt = expiresAt.Unix()
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.keys[0].id
+	signed, err := token.SignedString(k.keys[0].private)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify returns the claims of the given token if it is signed by one of the keys and is valid now.
+func (k *keySet) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != k.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		id, _ := t.Header["kid"].(string)
+		for _, key := range k.keys {
+			if key.id == id {
+				return key.private.Public(), nil
+			}
+		}
+		return nil, ErrInvalidToken
+	})
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+	if !claims.VerifyIssuer(k.issuer, true) || !claims.VerifyAudience(k.audience, true) || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// JWKS returns the public keys to verify the tokens.
+func (k *keySet) JWKS() *JSONWebKeySet {
+	set := &JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range k.keys {
+		set.Keys = append(set.Keys, newJSONWebKey(key.id, k.method.Alg(), key.private.Public()))
+	}
+	return set
+}
+
+func isSupported(algorithm string) bool {
+	return algorithm == AlgorithmRS256 || algorithm == AlgorithmES256 || algorithm == AlgorithmEdDSA
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
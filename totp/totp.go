@@ -0,0 +1,123 @@
+package totp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"image/png"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the number of the digits of the codes.
+	Digits = 6
+	// Period is the seconds in which a code is valid.
+	Period = 30
+	// secretBytes is the size of the secret, which is the size of the output of SHA-1 recommended by RFC 4226.
+	secretBytes = 20
+	// qrSize is the width and the height of the QR code in pixels.
+	qrSize = 256
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret encoded in base32 without the padding.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Counter returns the time step of given time.
+func Counter(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Code returns the code of the secret at the time step defined by RFC 6238.
+func Code(secret string, counter int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1000000), nil
+}
+
+// Validate returns the time step matched the code within the allowed skew of the steps around given time.
+// It returns false if the code doesn't match any steps.
+func Validate(secret string, code string, t time.Time, skew int) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+	current := Counter(t)
+	for i := -skew; i <= skew; i++ {
+		expected, err := Code(secret, current+int64(i))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// IsCode returns true if given value looks like a code rather than a recovery code.
+func IsCode(value string) bool {
+	if len(value) != Digits {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ProvisioningURI returns the otpauth URI which the authenticator apps read to register the secret.
+func ProvisioningURI(issuer string, accountName string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// QRCode returns the QR code of the URI as the data URI of a PNG image.
+func QRCode(uri string) (string, error) {
+	code, err := qr.Encode(uri, qr.M, qr.Auto)
+	if err != nil {
+		return "", err
+	}
+	if code, err = barcode.Scale(code, qrSize, qrSize); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, code); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInterval    = 5
+	defaultTimeout     = 10
+	defaultMaxAttempts = 8
+	defaultBackoffBase = 30
+	defaultBackoffMax  = 3600
+	defaultBatchSize   = 50
+	userAgent          = "go-webapp-demo-webhook"
+	maxErrorLength     = 255
+)
+
+// Dispatcher delivers the pending events of the outbox to webhooks in the background.
+type Dispatcher interface {
+	Start()
+	Stop()
+	DispatchPending() int
+}
+
+type dispatcher struct {
+	container   container.Container
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	batchSize   int
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewDispatcher is constructor.
+func NewDispatcher(container container.Container) Dispatcher {
+	conf := container.GetConfig().Webhook
+	return &dispatcher{
+		container:   container,
+		client:      &http.Client{Timeout: seconds(conf.Timeout, defaultTimeout)},
+		interval:    seconds(conf.Interval, defaultInterval),
+		maxAttempts: orDefault(conf.MaxAttempts, defaultMaxAttempts),
+		backoffBase: seconds(conf.BackoffBase, defaultBackoffBase),
+		backoffMax:  seconds(conf.BackoffMax, defaultBackoffMax),
+		batchSize:   orDefault(conf.BatchSize, defaultBatchSize),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start dispatches the pending deliveries periodically until the dispatcher is stopped.
+func (d *dispatcher) Start() {
+	d.container.GetLogger().GetZapLogger().Infof(fmt.Sprintf("Started webhook dispatcher, interval %s", d.interval))
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				d.DispatchPending()
+			}
+		}
+	}()
+}
+
+// Stop stops the dispatcher and waits for the running dispatch to finish.
+func (d *dispatcher) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+// DispatchPending attempts the deliveries whose next attempt has come, and returns the number of them.
+// The failed deliveries are retried with exponential backoff, and become dead after the max attempts.
+func (d *dispatcher) DispatchPending() int {
+	rep := d.container.GetRepository()
+	logger := d.container.GetLogger().GetZapLogger()
+
+	delivery := model.WebhookDelivery{}
+	deliveries, err := delivery.FindDue(rep, time.Now().UTC(), d.batchSize)
+	if err != nil {
+		logger.Errorf(err.Error())
+		return 0
+	}
+
+	for i := range *deliveries {
+		target := &(*deliveries)[i]
+		now := time.Now().UTC()
+
+		webhook := model.Webhook{}
+		w, err := webhook.FindByID(rep, target.WebhookID).Take()
+		if err != nil || !w.Active {
+			target.Status = model.DeliveryDead
+			target.LastError = "the webhook doesn't exist or is inactive"
+		} else {
+			status, err := d.send(w, target, now)
+			target.Attempts++
+			target.LastAttemptAt = &now
+			target.ResponseStatus = status
+			target.LastError = ""
+			switch {
+			case err == nil:
+				target.Status = model.DeliverySucceeded
+			case target.Attempts >= d.maxAttempts:
+				target.Status = model.DeliveryDead
+				target.LastError = truncate(err.Error())
+			default:
+				target.NextAttemptAt = now.Add(d.backoff(target.Attempts))
+				target.LastError = truncate(err.Error())
+			}
+		}
+
+		if _, err := target.Update(rep); err != nil {
+			logger.Errorf(err.Error())
+		}
+	}
+	return len(*deliveries)
+}
+
+// send posts the payload of the delivery with the signature, and returns the status code of the response.
+func (d *dispatcher) send(w *model.Webhook, delivery *model.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("the receiver responded %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// backoff returns the delay until the next attempt, which doubles per attempt up to the max.
+func (d *dispatcher) backoff(attempts int) time.Duration {
+	delay := d.backoffBase
+	for i := 1; i < attempts && delay < d.backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > d.backoffMax {
+		return d.backoffMax
+	}
+	return delay
+}
+
+func seconds(value int, defaultValue int) time.Duration {
+	return time.Duration(orDefault(value, defaultValue)) * time.Second
+}
+
+func orDefault(value int, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func truncate(message string) string {
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+	return message
+}
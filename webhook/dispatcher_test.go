@@ -0,0 +1,157 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lyh-demo/go-webapp-demo/container"
+	"github.com/lyh-demo/go-webapp-demo/model"
+	"github.com/lyh-demo/go-webapp-demo/test"
+	"github.com/lyh-demo/go-webapp-demo/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "0123456789abcdef"
+
+// receiver records the requests of the dispatcher, and responds the statuses in order.
+type receiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func newReceiver(statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		status := http.StatusNoContent
+		if len(r.requests) < len(r.statuses) {
+			status = r.statuses[len(r.requests)]
+		}
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(status)
+	}))
+	return r
+}
+
+func prepareForDispatcherTest(url string, active bool) (container.Container, *model.WebhookDelivery) {
+	container := test.PrepareForServiceTest()
+	container.GetConfig().Webhook.MaxAttempts = 3
+	container.GetConfig().Webhook.BackoffBase = 30
+	container.GetConfig().Webhook.BackoffMax = 3600
+	rep := container.GetRepository()
+
+	w, _ := model.NewWebhook(url, []string{"book.created"}, secret, active).Create(rep)
+	d, _ := model.NewWebhookDelivery(w.ID, "book.created", `{"id":1}`).Create(rep)
+	return container, d
+}
+
+func findDelivery(container container.Container, id uint) *model.WebhookDelivery {
+	d := model.WebhookDelivery{}
+	delivery, _ := d.FindByID(container.GetRepository(), id).Take()
+	return delivery
+}
+
+// makeDue moves the next attempt of the delivery to the past, as if the backoff has elapsed.
+func makeDue(container container.Container, id uint) {
+	container.GetRepository().Exec("update webhook_delivery set next_attempt_at = ? where id = ?",
+		time.Now().UTC().Add(-time.Second), id)
+}
+
+func TestDispatchPending_Signature(t *testing.T) {
+	r := newReceiver()
+	defer r.server.Close()
+	container, delivery := prepareForDispatcherTest(r.server.URL, true)
+
+	assert.Equal(t, 1, webhook.NewDispatcher(container).DispatchPending())
+
+	assert.Len(t, r.requests, 1)
+	req := r.requests[0]
+	assert.Equal(t, `{"id":1}`, string(r.bodies[0]))
+	assert.Equal(t, "book.created", req.Header.Get(webhook.EventHeader))
+	assert.Equal(t, strconv.FormatUint(uint64(delivery.ID), 10), req.Header.Get(webhook.DeliveryHeader))
+	assert.True(t, webhook.Verify(secret, req.Header.Get(webhook.TimestampHeader), r.bodies[0],
+		req.Header.Get(webhook.SignatureHeader)))
+	assert.False(t, webhook.Verify("another secret", req.Header.Get(webhook.TimestampHeader), r.bodies[0],
+		req.Header.Get(webhook.SignatureHeader)))
+
+	result := findDelivery(container, delivery.ID)
+	assert.Equal(t, model.DeliverySucceeded, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, http.StatusNoContent, result.ResponseStatus)
+}
+
+func TestDispatchPending_RetryWithBackoff(t *testing.T) {
+	r := newReceiver(http.StatusInternalServerError, http.StatusServiceUnavailable)
+	defer r.server.Close()
+	container, delivery := prepareForDispatcherTest(r.server.URL, true)
+	dispatcher := webhook.NewDispatcher(container)
+
+	for i, backoff := range []time.Duration{30 * time.Second, 60 * time.Second} {
+		assert.Equal(t, 1, dispatcher.DispatchPending())
+		result := findDelivery(container, delivery.ID)
+		assert.Equal(t, model.DeliveryPending, result.Status)
+		assert.Equal(t, i+1, result.Attempts)
+		assert.Equal(t, backoff, result.NextAttemptAt.Sub(*result.LastAttemptAt))
+		assert.NotEmpty(t, result.LastError)
+
+		// the delivery is not attempted again until the backoff has elapsed.
+		assert.Equal(t, 0, dispatcher.DispatchPending())
+		makeDue(container, delivery.ID)
+	}
+
+	assert.Equal(t, 1, dispatcher.DispatchPending())
+	result := findDelivery(container, delivery.ID)
+	assert.Equal(t, model.DeliverySucceeded, result.Status)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Empty(t, result.LastError)
+
+	// the retries have the same delivery ID, so the receiver can ignore the duplicates.
+	assert.Len(t, r.requests, 3)
+	assert.Equal(t, r.requests[0].Header.Get(webhook.DeliveryHeader), r.requests[2].Header.Get(webhook.DeliveryHeader))
+}
+
+func TestDispatchPending_DeadAfterMaxAttempts(t *testing.T) {
+	r := newReceiver(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	defer r.server.Close()
+	container, delivery := prepareForDispatcherTest(r.server.URL, true)
+	dispatcher := webhook.NewDispatcher(container)
+
+	for i := 0; i < 3; i++ {
+		dispatcher.DispatchPending()
+		makeDue(container, delivery.ID)
+	}
+
+	result := findDelivery(container, delivery.ID)
+	assert.Equal(t, model.DeliveryDead, result.Status)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, result.ResponseStatus)
+	assert.Contains(t, result.LastError, "500")
+
+	// the dead delivery is never attempted again.
+	assert.Equal(t, 0, dispatcher.DispatchPending())
+	assert.Len(t, r.requests, 3)
+}
+
+func TestDispatchPending_InactiveWebhook(t *testing.T) {
+	r := newReceiver()
+	defer r.server.Close()
+	container, delivery := prepareForDispatcherTest(r.server.URL, false)
+
+	webhook.NewDispatcher(container).DispatchPending()
+
+	result := findDelivery(container, delivery.ID)
+	assert.Equal(t, model.DeliveryDead, result.Status)
+	assert.Equal(t, 0, result.Attempts)
+	assert.Empty(t, r.requests)
+}
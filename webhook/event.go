@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types delivered to webhooks.
+const (
+	// BookCreated represents the event which a book is registered.
+	BookCreated = "book.created"
+	// BookUpdated represents the event which a book is updated.
+	BookUpdated = "book.updated"
+	// BookDeleted represents the event which a book is deleted.
+	BookDeleted = "book.deleted"
+)
+
+// EventTypes is the list of event types which webhooks can subscribe.
+var EventTypes = []string{BookCreated, BookUpdated, BookDeleted}
+
+// Event represents the body of a request sent to webhooks.
+type Event struct {
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// IsEventType returns true if the given name is one of the event types.
+func IsEventType(name string) bool {
+	for _, t := range EventTypes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPayload returns the JSON document of the event which has the given data.
+func NewPayload(eventType string, data interface{}, occurredAt time.Time) (string, error) {
+	bytes, err := json.Marshal(&Event{Type: eventType, OccurredAt: occurredAt.UTC(), Data: data})
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
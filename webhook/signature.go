@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers of a request sent to webhooks.
+const (
+	// EventHeader is the header which has the event type.
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader is the header which has the ID of the delivery. It is the same among retries.
+	DeliveryHeader = "X-Webhook-Delivery"
+	// TimestampHeader is the header which has the unix time when the request was signed.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader is the header which has the HMAC-SHA256 signature of the request.
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// signaturePrefix is the prefix which represents the algorithm of the signature.
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of the body. The timestamp is signed together to prevent replay attacks,
+// as the hex encoded HMAC-SHA256 of "{timestamp}.{body}".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature matches the body. It is used by the receivers of webhooks.
+func Verify(secret string, timestamp string, body []byte, signature string) bool {
+	t, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, t, body)), []byte(signature))
+}